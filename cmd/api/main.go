@@ -9,12 +9,17 @@ import (
 	"syscall"
 	"time"
 
+	"gorm.io/gorm"
+
 	"pack-calculator/internal/api/handlers"
 	apihttp "pack-calculator/internal/api/http"
 	"pack-calculator/internal/api/middleware"
 	"pack-calculator/internal/config"
+	"pack-calculator/internal/domain/repository"
 	"pack-calculator/internal/domain/service"
+	"pack-calculator/internal/infrastructure/idgen"
 	"pack-calculator/internal/infrastructure/logger"
+	"pack-calculator/internal/infrastructure/persistence"
 )
 
 func main() {
@@ -33,13 +38,104 @@ func main() {
 		"port":        cfg.Server.Port,
 	})
 
+	// Initialize repositories
+	var (
+		db                    *gorm.DB
+		packRepository        repository.PackRepository
+		calculationRepository repository.CalculationRepository
+	)
+	if cfg.Database.Enabled {
+		db, err = persistence.OpenPostgres(cfg.Database)
+		if err != nil {
+			logger.Error("Failed to connect to database", map[string]interface{}{
+				"host":  cfg.Database.Host,
+				"error": err.Error(),
+			})
+			os.Exit(1)
+		}
+		if cfg.Database.AutoMigrate {
+			if err := persistence.Migrate(db); err != nil {
+				logger.Error("Failed to migrate database", map[string]interface{}{
+					"error": err.Error(),
+				})
+				os.Exit(1)
+			}
+		}
+		packRepository = persistence.NewGormPackRepository(db)
+		calculationRepository = persistence.NewGormCalculationRepository(db)
+		logger.Info("Database connected", map[string]interface{}{
+			"host": cfg.Database.Host,
+			"name": cfg.Database.Name,
+		})
+	} else {
+		packRepository = persistence.NewMemoryPackRepository()
+		calculationRepository = persistence.NewMemoryCalculationRepository(cfg.App.HistorySize)
+		logger.Info("Database disabled, using in-memory storage")
+	}
+
 	// Initialize services
-	packService := service.NewPackService()
+	ids, err := idgen.New(cfg.App.IDFormat)
+	if err != nil {
+		logger.Error("Invalid ID format", map[string]interface{}{
+			"id_format": cfg.App.IDFormat,
+			"error":     err.Error(),
+		})
+		os.Exit(1)
+	}
+
+	packServiceOptions := []service.PackServiceOption{
+		service.WithIDGenerator(ids),
+		service.WithMaxSolveTime(cfg.App.MaxSolveTime),
+		service.WithBatchWorkers(cfg.App.BatchWorkers),
+		service.WithCalculationRepository(calculationRepository),
+		service.WithLimits(service.Limits{
+			MaxOrderQuantity:     cfg.Limits.MaxOrderQuantity,
+			MaxPackSizes:         cfg.Limits.MaxPackSizes,
+			MaxPackSize:          cfg.Limits.MaxPackSize,
+			MaxBatchSize:         cfg.Limits.MaxBatchSize,
+			MaxAlternatives:      cfg.Limits.MaxAlternatives,
+			MaxCandidatePackSets: cfg.Limits.MaxCandidatePackSets,
+		}),
+	}
+	packConfigOptions := []service.PackConfigServiceOption{
+		service.WithPackIDGenerator(ids),
+	}
+	if cfg.SolutionTables.Enabled {
+		tables := service.NewSolutionTables(
+			service.WithSolutionTableQuantity(cfg.SolutionTables.MaxQuantity),
+			service.WithMaxSolutionTables(cfg.SolutionTables.MaxTables),
+			service.WithSolutionTableHotAfter(cfg.SolutionTables.HotAfter),
+			service.WithMaxSolutionTableBuilds(cfg.SolutionTables.MaxBuilds),
+			service.WithSolutionTableBuildTimeout(cfg.SolutionTables.BuildTimeout),
+		)
+		// Warm up in the background, then reload the catalogs of the SKUs
+		// whose packs change
+		go tables.Watch(context.Background(), packRepository)
+		tables.Refresh()
+		packServiceOptions = append(packServiceOptions, service.WithSolutionTables(tables))
+		packConfigOptions = append(packConfigOptions, service.WithPackChangeHook(tables.Refresh))
+	}
+
+	packService := service.NewPackService(packServiceOptions...)
+	packConfigService := service.NewPackConfigService(packRepository, packConfigOptions...)
+	orderService := service.NewOrderService(packRepository, packService)
+	historyService := service.NewCalculationHistoryService(calculationRepository)
+	shipmentPlanner := service.NewShipmentPlanner(
+		service.WithMaxShipments(cfg.Limits.MaxShipments),
+	)
 	logger.Info("Services initialized")
 
 	// Initialize handlers
 	calculationHandler := handlers.NewCalculationHandler(packService)
+	packHandler := handlers.NewPackHandler(packConfigService)
+	orderHandler := handlers.NewOrderHandler(orderService)
+	historyHandler := handlers.NewHistoryHandler(historyService)
+	shipmentHandler := handlers.NewShipmentHandler(packService, historyService, shipmentPlanner)
+	packSetHandler := handlers.NewPackSetHandler(packService, historyService)
 	healthHandler := handlers.NewHealthHandler()
+	if db != nil {
+		healthHandler.AddReadinessCheck("database", persistence.Ping(db))
+	}
 	staticHandler := handlers.NewStaticHandler()
 	logger.Info("Handlers initialized")
 
@@ -47,7 +143,26 @@ func main() {
 	router := apihttp.NewRouter()
 
 	// Register routes with handler functions
-	router.RegisterCalculationRoutes(calculationHandler.Calculate)
+	router.RegisterCalculationRoutes(
+		calculationHandler.Calculate,
+		calculationHandler.CalculateBatch,
+		calculationHandler.Explain,
+	)
+	router.RegisterPackRoutes(
+		packHandler.List,
+		packHandler.Get,
+		packHandler.Create,
+		packHandler.Update,
+		packHandler.Delete,
+	)
+	router.RegisterOrderRoutes(orderHandler.Calculate, orderHandler.Plan)
+	router.RegisterShipmentRoutes(shipmentHandler.Plan)
+	router.RegisterPackSetRoutes(
+		packSetHandler.Recommend,
+		packSetHandler.Table,
+		packSetHandler.Analyze,
+	)
+	router.RegisterHistoryRoutes(historyHandler.List, historyHandler.Get)
 	router.RegisterHealthRoutes(healthHandler.Health, healthHandler.Ready)
 	router.RegisterStaticRoutes(staticHandler.ServeUI, staticHandler.ServeStatic)
 	// Wrap with logging middleware
@@ -96,4 +211,12 @@ func main() {
 	} else {
 		logger.Info("Server shutdown completed")
 	}
+
+	if db != nil {
+		if err := persistence.Close(db); err != nil {
+			logger.Error("Failed to close database", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
 }
@@ -0,0 +1,156 @@
+// Command recommend reports which pack sizes to stock for a demand
+// histogram of order quantities read from a CSV file.
+//
+//	recommend -demand orders.csv -k 3 -candidates 250,500,1000,2000,5000
+//
+// Each CSV line is "quantity,orders", or just "quantity" for one order.
+// Limits and workers come from the same PC_ environment as the API.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"pack-calculator/internal/api/dto"
+	"pack-calculator/internal/config"
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/domain/service"
+	"pack-calculator/internal/infrastructure/logger"
+)
+
+func main() {
+	demandPath := flag.String("demand", "", "CSV file of order quantities, or - for stdin")
+	maxPackSizes := flag.Int("k", 3, "most pack sizes a set may have")
+	candidates := flag.String("candidates", "",
+		"comma-separated candidate pack sizes (default: the most ordered quantities)")
+	top := flag.Int("top", service.DefaultRecommendations, "number of pack sets to report")
+	timeout := flag.Duration("timeout", 0, "search time limit (default: PC_APP_MAX_SOLVE_TIME)")
+	asJSON := flag.Bool("json", false, "print the API response JSON instead of a table")
+	flag.Parse()
+
+	if err := run(*demandPath, *maxPackSizes, *candidates, *top, *timeout, *asJSON); err != nil {
+		fmt.Fprintf(os.Stderr, "recommend: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(
+	demandPath string,
+	maxPackSizes int,
+	candidateList string,
+	top int,
+	timeout time.Duration,
+	asJSON bool,
+) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	logger.Initialize(cfg.Logging.Level, cfg.Logging.Format)
+
+	demand, err := readDemand(demandPath)
+	if err != nil {
+		return err
+	}
+	candidates, err := parseSizes(candidateList)
+	if err != nil {
+		return err
+	}
+
+	if timeout == 0 {
+		timeout = cfg.App.MaxSolveTime
+	}
+	packService := service.NewPackService(
+		service.WithMaxSolveTime(timeout),
+		service.WithBatchWorkers(cfg.App.BatchWorkers),
+		service.WithLimits(service.Limits{
+			MaxOrderQuantity:     cfg.Limits.MaxOrderQuantity,
+			MaxPackSizes:         cfg.Limits.MaxPackSizes,
+			MaxPackSize:          cfg.Limits.MaxPackSize,
+			MaxCandidatePackSets: cfg.Limits.MaxCandidatePackSets,
+		}),
+	)
+
+	scores, err := packService.RecommendPackSizes(context.Background(), demand,
+		service.RecommendOptions{
+			MaxPackSizes: maxPackSizes,
+			Candidates:   candidates,
+			Top:          top,
+		})
+	if err != nil {
+		return err
+	}
+
+	response := dto.ToPackSizeRecommendationResponse(demand, scores)
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(response)
+	}
+	return printTable(os.Stdout, response)
+}
+
+// readDemand parses the demand CSV at path, or stdin for "-"
+func readDemand(path string) (model.DemandHistogram, error) {
+	if path == "" {
+		return nil, fmt.Errorf("-demand is required")
+	}
+	if path == "-" {
+		return model.ParseDemandCSV(os.Stdin)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return model.ParseDemandCSV(file)
+}
+
+// parseSizes parses a comma-separated list of pack sizes
+func parseSizes(list string) ([]int, error) {
+	if list == "" {
+		return nil, nil
+	}
+	fields := strings.Split(list, ",")
+	sizes := make([]int, len(fields))
+	for i, field := range fields {
+		size, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, fmt.Errorf("invalid candidate size %q", field)
+		}
+		sizes[i] = size
+	}
+	return sizes, nil
+}
+
+// printTable writes the recommendation as an aligned table
+func printTable(w io.Writer, response *dto.PackSizeRecommendationResponse) error {
+	fmt.Fprintf(w, "%d orders, %d distinct quantities\n\n",
+		response.OrderCount, response.DistinctQuantities)
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(table, "RANK\tPACK SIZES\tOVERAGE/ORDER\tPACKS/ORDER\tOVERAGE RATE\t")
+	for _, score := range response.Recommendations {
+		sizes := make([]string, len(score.PackSizes))
+		for i, size := range score.PackSizes {
+			sizes[i] = strconv.Itoa(size)
+		}
+		fmt.Fprintf(table, "%d\t%s\t%.2f\t%.2f\t%.2f%%\t\n",
+			score.Rank,
+			strings.Join(sizes, ","),
+			score.ExpectedOverage,
+			score.ExpectedPacks,
+			score.OverageRate*100,
+		)
+	}
+	return table.Flush()
+}
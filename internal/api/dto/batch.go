@@ -0,0 +1,36 @@
+package dto
+
+// BatchCalculationRequest represents API request for calculating many orders
+type BatchCalculationRequest struct {
+	Items []BatchItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+// BatchItemRequest represents a single order line in a batch
+type BatchItemRequest struct {
+	Reference     string `json:"reference,omitempty"`
+	PackSizes     []int  `json:"pack_sizes"`
+	OrderQuantity int    `json:"order_quantity"`
+}
+
+// BatchItemError describes why a single batch item failed
+type BatchItemError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// BatchItemResponse represents the outcome of one batch item
+type BatchItemResponse struct {
+	Index     int                  `json:"index"`
+	Reference string               `json:"reference,omitempty"`
+	Success   bool                 `json:"success"`
+	Result    *CalculationResponse `json:"result,omitempty"`
+	Error     *BatchItemError      `json:"error,omitempty"`
+}
+
+// BatchCalculationResponse represents API response for a batch calculation
+type BatchCalculationResponse struct {
+	Items     []BatchItemResponse `json:"items"`
+	Total     int                 `json:"total"`
+	Succeeded int                 `json:"succeeded"`
+	Failed    int                 `json:"failed"`
+}
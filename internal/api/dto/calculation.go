@@ -4,37 +4,269 @@ import (
 	"pack-calculator/internal/domain/model"
 )
 
-// CalculationRequest represents API request for pack calculation
+// CalculationRequest represents API request for pack calculation.
+// Stock optionally limits how many packs of a size are available.
+// Objective selects the optimisation; min_cost prices overage items at
+// ItemCost and each pack at its PackCosts entry. Alternatives asks for
+// that many ranked distributions. Measurements gives the weight and
+// dimensions per pack size, which Caps are checked against. Packaging
+// nests the packs of a size into cartons, pallets or other outer units.
+// Tolerance lets the order ship short and caps the overage. Rules require,
+// forbid and bound the packs of a size.
 type CalculationRequest struct {
-	PackSizes     []int `json:"pack_sizes"     validate:"required,min=1,dive,gt=0"`
-	OrderQuantity int   `json:"order_quantity" validate:"required,gt=0"`
+	PackSizes     []int         `json:"pack_sizes"             validate:"required,min=1,dive,gt=0"`
+	OrderQuantity int           `json:"order_quantity"         validate:"required,gt=0"`
+	Stock         map[int]int   `json:"stock,omitempty"        validate:"omitempty,dive,gte=0"`
+	Objective     string        `json:"objective,omitempty"`
+	ItemCost      int64         `json:"item_cost,omitempty"    validate:"gte=0"`
+	PackCosts     map[int]int64 `json:"pack_costs,omitempty"   validate:"omitempty,dive,gte=0"`
+	Alternatives  int           `json:"alternatives,omitempty" validate:"gte=0"`
+
+	Measurements MeasurementsRequest  `json:"measurements,omitempty" validate:"omitempty,dive"`
+	Caps         *ShipmentCapsRequest `json:"caps,omitempty"`
+	Packaging    PackagingByPackSize  `json:"packaging,omitempty"    validate:"omitempty,dive,dive"`
+	Tolerance    *ToleranceRequest    `json:"tolerance,omitempty"`
+	Rules        PackRulesRequest     `json:"rules,omitempty"        validate:"omitempty,dive"`
+}
+
+// PackRulesRequest maps a pack size to the rule for its packs
+type PackRulesRequest map[int]PackRuleRequest
+
+// ToModel converts the rules to the domain; nil has none
+func (r PackRulesRequest) ToModel() model.PackRules {
+	if r == nil {
+		return nil
+	}
+	rules := make(model.PackRules, len(r))
+	for size, rule := range r {
+		rules[size] = rule.ToModel()
+	}
+	return rules
+}
+
+// ToleranceRequest holds the optional shortfall and overage caps; zero
+// disables a cap. The shortfall is given in items or as a percentage of
+// the order, not both.
+type ToleranceRequest struct {
+	MaxShortfall        int     `json:"max_shortfall"         validate:"gte=0"`
+	MaxShortfallPercent float64 `json:"max_shortfall_percent" validate:"gte=0,lt=100"`
+	MaxOverage          int     `json:"max_overage"           validate:"gte=0"`
+}
+
+// ToModel converts the request to a domain tolerance; nil has none
+func (t *ToleranceRequest) ToModel() model.Tolerance {
+	if t == nil {
+		return model.Tolerance{}
+	}
+	return model.Tolerance{
+		MaxShortfall:        t.MaxShortfall,
+		MaxShortfallPercent: t.MaxShortfallPercent,
+		MaxOverage:          t.MaxOverage,
+	}
+}
+
+// PackagingByPackSize maps a pack size to the outer units its packs nest
+// into
+type PackagingByPackSize map[int]PackagingRequest
+
+// ToModel converts the packaging to the domain; nil has none
+func (p PackagingByPackSize) ToModel() map[int]model.Packaging {
+	if p == nil {
+		return nil
+	}
+	result := make(map[int]model.Packaging, len(p))
+	for size, packaging := range p {
+		result[size] = packaging.ToModel()
+	}
+	return result
+}
+
+// ShipmentCapsRequest holds optional courier limits; zero disables a cap.
+// MaxPackWeightGrams caps every parcel, the others a single shipment.
+type ShipmentCapsRequest struct {
+	MaxPackWeightGrams int64 `json:"max_pack_weight_grams" validate:"gte=0"`
+	MaxWeightGrams     int64 `json:"max_weight_grams"      validate:"gte=0"`
+	MaxVolumeMm3       int64 `json:"max_volume_mm3"        validate:"gte=0"`
+	MaxPacks           int   `json:"max_packs"             validate:"gte=0"`
+}
+
+// ToModel converts the request to domain caps; nil has no caps
+func (c *ShipmentCapsRequest) ToModel() model.ShipmentCaps {
+	if c == nil {
+		return model.ShipmentCaps{}
+	}
+	return model.ShipmentCaps{
+		MaxPackWeightGrams: c.MaxPackWeightGrams,
+		MaxWeightGrams:     c.MaxWeightGrams,
+		MaxVolumeMm3:       c.MaxVolumeMm3,
+		MaxPacks:           c.MaxPacks,
+	}
+}
+
+// MeasurementsRequest maps a pack size to its weight and dimensions
+type MeasurementsRequest map[int]PackMeasurementsRequest
+
+// ToModel converts the measurements to the domain; nil has none
+func (m MeasurementsRequest) ToModel() map[int]model.PackMeasurements {
+	if m == nil {
+		return nil
+	}
+	result := make(map[int]model.PackMeasurements, len(m))
+	for size, measurements := range m {
+		result[size] = measurements.ToModel()
+	}
+	return result
 }
 
-// CalculationResponse represents API response for pack calculation
+// CalculationResponse represents API response for pack calculation.
+// Alternatives lists the ranked distributions, best first, when more than
+// one was requested. SplitRequired reports that no distribution fits a
+// single shipment under the caps, and MinShipments how many are needed at
+// least. ItemsDelta is the items shipped minus the order, negative when a
+// tolerance let the order ship short. Packaging breaks the packs down into
+// outer units when packaging was given.
 type CalculationResponse struct {
-	ID              string      `json:"id"`
-	PacksUsed       map[int]int `json:"packs_used"`
-	TotalItems      int         `json:"total_items"`
-	TotalPacks      int         `json:"total_packs"`
-	ItemsOverage    int         `json:"items_overage"`
-	CalculationTime string      `json:"calculation_time"`
-	Success         bool        `json:"success"`
+	ID              string                `json:"id"`
+	PacksUsed       map[int]int           `json:"packs_used"`
+	TotalItems      int                   `json:"total_items"`
+	TotalPacks      int                   `json:"total_packs"`
+	ItemsOverage    int                   `json:"items_overage"`
+	ItemsDelta      int                   `json:"items_delta"`
+	Objective       string                `json:"objective,omitempty"`
+	TotalCost       int64                 `json:"total_cost,omitempty"`
+	TotalWeight     int64                 `json:"total_weight_grams"`
+	TotalVolume     int64                 `json:"total_volume_mm3"`
+	SplitRequired   bool                  `json:"split_required,omitempty"`
+	MinShipments    int                   `json:"min_shipments,omitempty"`
+	CalculationTime string                `json:"calculation_time"`
+	Success         bool                  `json:"success"`
+	Alternatives    []AlternativeResponse `json:"alternatives,omitempty"`
+
+	Packaging []PackagingUnitResponse `json:"packaging,omitempty"`
+}
+
+// PackagingUnitResponse is Count units of one level of a packaging
+// breakdown; Contents is what one unit holds and is omitted for a pack
+type PackagingUnitResponse struct {
+	Unit         string                 `json:"unit"`
+	PackSize     int                    `json:"pack_size"`
+	Count        int                    `json:"count"`
+	ItemsPerUnit int                    `json:"items_per_unit"`
+	Contents     *PackagingUnitResponse `json:"contents,omitempty"`
+}
+
+// AlternativeResponse is one ranked distribution; Rank starts at 1
+type AlternativeResponse struct {
+	Rank         int         `json:"rank"`
+	PacksUsed    map[int]int `json:"packs_used"`
+	TotalItems   int         `json:"total_items"`
+	TotalPacks   int         `json:"total_packs"`
+	ItemsOverage int         `json:"items_overage"`
+	TotalCost    int64       `json:"total_cost,omitempty"`
 }
 
-// SimpleCalculationRequest for calculations using stored pack configurations
+// SimpleCalculationRequest for calculations using stored pack configurations.
+// Pack handling costs for the min_cost objective and the measurements the
+// caps are checked against come from the stored packs.
 type SimpleCalculationRequest struct {
-	OrderQuantity int `json:"order_quantity" validate:"required,gt=0"`
+	OrderQuantity int                  `json:"order_quantity"         validate:"required,gt=0"`
+	Objective     string               `json:"objective,omitempty"`
+	ItemCost      int64                `json:"item_cost,omitempty"    validate:"gte=0"`
+	Alternatives  int                  `json:"alternatives,omitempty" validate:"gte=0"`
+	Caps          *ShipmentCapsRequest `json:"caps,omitempty"`
+	Tolerance     *ToleranceRequest    `json:"tolerance,omitempty"`
+}
+
+// PackUsageResponse describes how many of a stored pack were used
+type PackUsageResponse struct {
+	PackID string `json:"pack_id"`
+	Name   string `json:"name"`
+	Size   int    `json:"size"`
+	Count  int    `json:"count"`
+}
+
+// StoredPackCalculationResponse represents API response for a calculation
+// against the stored pack configuration
+type StoredPackCalculationResponse struct {
+	*CalculationResponse
+	Packs []PackUsageResponse `json:"packs"`
 }
 
 // ToCalculationResponse converts domain model to API response
 func ToCalculationResponse(result *model.Calculation) *CalculationResponse {
-	return &CalculationResponse{
+	response := &CalculationResponse{
 		ID:              result.ID,
 		PacksUsed:       map[int]int(result.GetDistribution()),
 		TotalItems:      result.TotalItems,
 		TotalPacks:      result.TotalPacks,
 		ItemsOverage:    result.ItemsOverage,
+		ItemsDelta:      result.ItemsDelta,
+		Objective:       result.Objective,
+		TotalCost:       result.TotalCost,
+		TotalWeight:     result.TotalWeightGrams,
+		TotalVolume:     result.TotalVolumeMm3,
+		SplitRequired:   result.SplitRequired,
+		MinShipments:    result.MinShipments,
 		CalculationTime: result.CalculationTime.String(),
 		Success:         true,
 	}
+	for i, alternative := range result.Alternatives {
+		response.Alternatives = append(response.Alternatives, toAlternativeResponse(i+1, alternative))
+	}
+	for _, unit := range result.Packaging {
+		response.Packaging = append(response.Packaging, *toPackagingUnitResponse(unit))
+	}
+	return response
+}
+
+// toPackagingUnitResponse converts a packaging breakdown to API response
+func toPackagingUnitResponse(unit model.PackagingUnit) *PackagingUnitResponse {
+	response := &PackagingUnitResponse{
+		Unit:         unit.Name,
+		PackSize:     unit.PackSize,
+		Count:        unit.Count,
+		ItemsPerUnit: unit.Items,
+	}
+	if unit.Contents != nil {
+		response.Contents = toPackagingUnitResponse(*unit.Contents)
+	}
+	return response
+}
+
+// toAlternativeResponse converts a ranked alternative to API response
+func toAlternativeResponse(rank int, alternative model.Alternative) AlternativeResponse {
+	return AlternativeResponse{
+		Rank:         rank,
+		PacksUsed:    map[int]int(alternative.Distribution),
+		TotalItems:   alternative.TotalItems,
+		TotalPacks:   alternative.TotalPacks,
+		ItemsOverage: alternative.ItemsOverage,
+		TotalCost:    alternative.TotalCost,
+	}
+}
+
+// ToStoredPackCalculationResponse converts a calculation against stored
+// packs to API response, listing only the packs actually used
+func ToStoredPackCalculationResponse(
+	result *model.Calculation,
+	packs []*model.Pack,
+) *StoredPackCalculationResponse {
+	distribution := result.GetDistribution()
+
+	usage := make([]PackUsageResponse, 0, len(distribution))
+	for _, pack := range packs {
+		if count := distribution[pack.Size]; count > 0 {
+			usage = append(usage, PackUsageResponse{
+				PackID: pack.ID,
+				Name:   pack.Name,
+				Size:   pack.Size,
+				Count:  count,
+			})
+		}
+	}
+
+	return &StoredPackCalculationResponse{
+		CalculationResponse: ToCalculationResponse(result),
+		Packs:               usage,
+	}
 }
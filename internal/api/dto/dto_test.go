@@ -55,3 +55,28 @@ func TestToCalculationResponse(t *testing.T) {
 		t.Errorf("CalculationTime should not be empty")
 	}
 }
+
+func TestToStoredPackCalculationResponse(t *testing.T) {
+	small := model.NewPack(250, "Small")
+	small.ID = "pack-small"
+	medium := model.NewPack(500, "Medium")
+	medium.ID = "pack-medium"
+
+	distribution := model.PackDistribution{500: 2}
+	calc := model.NewCalculation([]int{250, 500}, 1000, distribution, time.Millisecond)
+
+	response := ToStoredPackCalculationResponse(calc, []*model.Pack{small, medium})
+
+	if response.TotalItems != 1000 {
+		t.Errorf("Expected TotalItems 1000, got %d", response.TotalItems)
+	}
+
+	if len(response.Packs) != 1 {
+		t.Fatalf("Expected only used packs, got %+v", response.Packs)
+	}
+
+	usage := response.Packs[0]
+	if usage.PackID != "pack-medium" || usage.Name != "Medium" || usage.Count != 2 {
+		t.Errorf("Unexpected pack usage: %+v", usage)
+	}
+}
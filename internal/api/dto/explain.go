@@ -0,0 +1,94 @@
+package dto
+
+import (
+	"pack-calculator/internal/domain/model"
+)
+
+// ExplainRequest represents API request for an explained calculation
+type ExplainRequest struct {
+	PackSizes     []int `json:"pack_sizes"     validate:"required,min=1,dive,gt=0"`
+	OrderQuantity int   `json:"order_quantity" validate:"required,gt=0"`
+}
+
+// ExplainResponse represents API response for an explained calculation
+type ExplainResponse struct {
+	*CalculationResponse
+	Explanation ExplanationResponse `json:"explanation"`
+}
+
+// ExplanationResponse is the evidence that a distribution is optimal.
+// LargestShortTotal is the closest total below the order, omitted when
+// every pack is larger than the order. EqualOverageCandidates compares the
+// pack counts of the ranked distributions with the minimal total.
+type ExplanationResponse struct {
+	MinimalTotalItems      int                   `json:"minimal_total_items"`
+	PackSizeGCD            int                   `json:"pack_size_gcd"`
+	LargestShortTotal      *ShortfallResponse    `json:"largest_short_total,omitempty"`
+	MinimalPacks           int                   `json:"minimal_packs"`
+	EqualOverageCandidates []AlternativeResponse `json:"equal_overage_candidates"`
+	RejectedRunnerUps      []RunnerUpResponse    `json:"rejected_runner_ups"`
+	Steps                  []string              `json:"steps"`
+}
+
+// ShortfallResponse is a distribution that falls short of the order
+type ShortfallResponse struct {
+	PacksUsed  map[int]int `json:"packs_used"`
+	TotalItems int         `json:"total_items"`
+	ItemsShort int         `json:"items_short"`
+}
+
+// RunnerUpResponse is a rejected distribution and the rule it loses on
+type RunnerUpResponse struct {
+	PacksUsed    map[int]int `json:"packs_used"`
+	TotalItems   int         `json:"total_items"`
+	TotalPacks   int         `json:"total_packs"`
+	ItemsOverage int         `json:"items_overage"`
+	Rule         int         `json:"rule"`
+	Reason       string      `json:"reason"`
+}
+
+// ToExplainResponse converts an explained calculation to API response
+func ToExplainResponse(
+	result *model.Calculation,
+	explanation *model.Explanation,
+) *ExplainResponse {
+	response := &ExplainResponse{
+		CalculationResponse: ToCalculationResponse(result),
+		Explanation: ExplanationResponse{
+			MinimalTotalItems:      explanation.MinimalTotal,
+			PackSizeGCD:            explanation.GCD,
+			MinimalPacks:           explanation.MinimalPacks,
+			EqualOverageCandidates: make([]AlternativeResponse, 0, len(explanation.EqualOverage)),
+			RejectedRunnerUps:      make([]RunnerUpResponse, 0, len(explanation.RunnerUps)),
+			Steps:                  explanation.Steps,
+		},
+	}
+
+	if explanation.Shortfall != nil {
+		total := explanation.Shortfall.TotalItems()
+		response.Explanation.LargestShortTotal = &ShortfallResponse{
+			PacksUsed:  map[int]int(explanation.Shortfall),
+			TotalItems: total,
+			ItemsShort: explanation.OrderQuantity - total,
+		}
+	}
+	for i, candidate := range explanation.EqualOverage {
+		response.Explanation.EqualOverageCandidates = append(
+			response.Explanation.EqualOverageCandidates, toAlternativeResponse(i+1, candidate),
+		)
+	}
+	for _, runnerUp := range explanation.RunnerUps {
+		response.Explanation.RejectedRunnerUps = append(
+			response.Explanation.RejectedRunnerUps, RunnerUpResponse{
+				PacksUsed:    map[int]int(runnerUp.Distribution),
+				TotalItems:   runnerUp.TotalItems,
+				TotalPacks:   runnerUp.TotalPacks,
+				ItemsOverage: runnerUp.ItemsOverage,
+				Rule:         runnerUp.Rule,
+				Reason:       runnerUp.Reason,
+			},
+		)
+	}
+
+	return response
+}
@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"time"
+
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/domain/repository"
+)
+
+// CalculationRecordResponse represents a stored calculation
+type CalculationRecordResponse struct {
+	ID              string      `json:"id"`
+	PackSizes       []int       `json:"pack_sizes"`
+	OrderQuantity   int         `json:"order_quantity"`
+	PacksUsed       map[int]int `json:"packs_used"`
+	TotalItems      int         `json:"total_items"`
+	TotalPacks      int         `json:"total_packs"`
+	ItemsOverage    int         `json:"items_overage"`
+	ItemsDelta      int         `json:"items_delta"`
+	Objective       string      `json:"objective,omitempty"`
+	TotalCost       int64       `json:"total_cost,omitempty"`
+	TotalWeight     int64       `json:"total_weight_grams"`
+	TotalVolume     int64       `json:"total_volume_mm3"`
+	SplitRequired   bool        `json:"split_required,omitempty"`
+	CalculationTime string      `json:"calculation_time"`
+	UserID          string      `json:"user_id,omitempty"`
+	CreatedAt       time.Time   `json:"created_at"`
+}
+
+// CalculationListResponse represents a page of stored calculations
+type CalculationListResponse struct {
+	Calculations []CalculationRecordResponse `json:"calculations"`
+	Count        int                         `json:"count"`
+	NextCursor   string                      `json:"next_cursor,omitempty"`
+}
+
+// ToCalculationRecordResponse converts domain model to API response
+func ToCalculationRecordResponse(calculation *model.Calculation) *CalculationRecordResponse {
+	return &CalculationRecordResponse{
+		ID:              calculation.ID,
+		PackSizes:       calculation.GetPackSizes(),
+		OrderQuantity:   calculation.OrderQuantity,
+		PacksUsed:       map[int]int(calculation.GetDistribution()),
+		TotalItems:      calculation.TotalItems,
+		TotalPacks:      calculation.TotalPacks,
+		ItemsOverage:    calculation.ItemsOverage,
+		ItemsDelta:      calculation.ItemsDelta,
+		Objective:       calculation.Objective,
+		TotalCost:       calculation.TotalCost,
+		TotalWeight:     calculation.TotalWeightGrams,
+		TotalVolume:     calculation.TotalVolumeMm3,
+		SplitRequired:   calculation.SplitRequired,
+		CalculationTime: calculation.CalculationTime.String(),
+		UserID:          calculation.UserID,
+		CreatedAt:       calculation.CreatedAt,
+	}
+}
+
+// ToCalculationListResponse converts a page of domain models to API response
+func ToCalculationListResponse(page *repository.CalculationPage) *CalculationListResponse {
+	responses := make([]CalculationRecordResponse, len(page.Calculations))
+	for i, calculation := range page.Calculations {
+		responses[i] = *ToCalculationRecordResponse(calculation)
+	}
+
+	return &CalculationListResponse{
+		Calculations: responses,
+		Count:        len(responses),
+		NextCursor:   page.NextCursor,
+	}
+}
@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"pack-calculator/internal/domain/model"
+)
+
+// OrderPlanRequest represents API request for a multi-product order
+type OrderPlanRequest struct {
+	Lines []OrderLineRequest `json:"lines" validate:"required,min=1,dive"`
+}
+
+// OrderLineRequest is one product of an order
+type OrderLineRequest struct {
+	SKU      string `json:"sku"      validate:"required"`
+	Quantity int    `json:"quantity" validate:"required,gt=0"`
+}
+
+// ToModel converts the request to a domain order
+func (r OrderPlanRequest) ToModel() model.Order {
+	order := model.Order{Lines: make([]model.OrderLine, len(r.Lines))}
+	for i, line := range r.Lines {
+		order.Lines[i] = model.OrderLine{SKU: line.SKU, Quantity: line.Quantity}
+	}
+	return order
+}
+
+// OrderPlanResponse represents API response for a multi-product order:
+// the order-level summary and each line's calculation in request order
+type OrderPlanResponse struct {
+	Summary OrderSummaryResponse `json:"summary"`
+	Lines   []OrderLineResponse  `json:"lines"`
+}
+
+// OrderSummaryResponse sums up every line of an order
+type OrderSummaryResponse struct {
+	LineCount    int `json:"line_count"`
+	OrderedItems int `json:"ordered_items"`
+	TotalItems   int `json:"total_items"`
+	TotalPacks   int `json:"total_packs"`
+	ItemsOverage int `json:"items_overage"`
+}
+
+// OrderLineResponse is the calculation of one order line against the
+// catalog of its SKU
+type OrderLineResponse struct {
+	SKU           string `json:"sku"`
+	OrderQuantity int    `json:"order_quantity"`
+	*StoredPackCalculationResponse
+}
+
+// ToOrderPlanResponse converts an order plan to API response
+func ToOrderPlanResponse(plan *model.OrderPlan) *OrderPlanResponse {
+	response := &OrderPlanResponse{
+		Summary: OrderSummaryResponse{
+			LineCount:    len(plan.Lines),
+			OrderedItems: plan.OrderedItems,
+			TotalItems:   plan.TotalItems,
+			TotalPacks:   plan.TotalPacks,
+			ItemsOverage: plan.ItemsOverage,
+		},
+		Lines: make([]OrderLineResponse, len(plan.Lines)),
+	}
+	for i, line := range plan.Lines {
+		response.Lines[i] = OrderLineResponse{
+			SKU:           line.Line.SKU,
+			OrderQuantity: line.Line.Quantity,
+			StoredPackCalculationResponse: ToStoredPackCalculationResponse(
+				line.Calculation, line.Packs,
+			),
+		}
+	}
+	return response
+}
@@ -6,26 +6,130 @@ import (
 	"pack-calculator/internal/domain/model"
 )
 
+// PackMeasurementsRequest is the weight in grams and the dimensions in
+// millimetres of one pack
+type PackMeasurementsRequest struct {
+	WeightGrams int64 `json:"weight_grams" validate:"gte=0"`
+	LengthMm    int64 `json:"length_mm"    validate:"gte=0"`
+	WidthMm     int64 `json:"width_mm"     validate:"gte=0"`
+	HeightMm    int64 `json:"height_mm"    validate:"gte=0"`
+}
+
+// ToModel converts the request to domain measurements
+func (m PackMeasurementsRequest) ToModel() model.PackMeasurements {
+	return model.PackMeasurements{
+		WeightGrams: m.WeightGrams,
+		LengthMm:    m.LengthMm,
+		WidthMm:     m.WidthMm,
+		HeightMm:    m.HeightMm,
+	}
+}
+
+// PackagingLevelRequest is an outer unit holding Quantity units of the
+// level inside it
+type PackagingLevelRequest struct {
+	Name     string `json:"name"     validate:"required"`
+	Quantity int    `json:"quantity" validate:"gt=0"`
+}
+
+// PackagingRequest lists the outer units packs nest into, innermost first
+type PackagingRequest []PackagingLevelRequest
+
+// ToModel converts the request to domain packaging; nil has none
+func (p PackagingRequest) ToModel() model.Packaging {
+	if p == nil {
+		return nil
+	}
+	packaging := make(model.Packaging, len(p))
+	for i, level := range p {
+		packaging[i] = model.PackagingLevel{Name: level.Name, Quantity: level.Quantity}
+	}
+	return packaging
+}
+
+// PackRuleRequest constrains the packs of one size a calculation may use.
+// MaxCount is unbounded when omitted.
+type PackRuleRequest struct {
+	Required         bool `json:"required"`
+	Forbidden        bool `json:"forbidden"`
+	MinCount         int  `json:"min_count"          validate:"gte=0"`
+	MaxCount         *int `json:"max_count"          validate:"omitempty,gte=0"`
+	MinOrderQuantity int  `json:"min_order_quantity" validate:"gte=0"`
+}
+
+// ToModel converts the request to a domain rule; nil has none
+func (r *PackRuleRequest) ToModel() model.PackRule {
+	if r == nil {
+		return model.PackRule{}
+	}
+	return model.PackRule{
+		Required:         r.Required,
+		Forbidden:        r.Forbidden,
+		MinCount:         r.MinCount,
+		MaxCount:         r.MaxCount,
+		MinOrderQuantity: r.MinOrderQuantity,
+	}
+}
+
 // CreatePackRequest represents API request for creating a pack
 type CreatePackRequest struct {
-	Size int    `json:"size" validate:"required,gt=0"`
-	Name string `json:"name" validate:"required,min=1"`
+	Size         int    `json:"size"          validate:"required,gt=0"`
+	Name         string `json:"name"          validate:"required,min=1"`
+	SKU          string `json:"sku,omitempty" validate:"max=255"`
+	Stock        *int   `json:"stock"         validate:"omitempty,gte=0"`
+	HandlingCost int64  `json:"handling_cost" validate:"gte=0"`
+
+	PackMeasurementsRequest
+	Packaging PackagingRequest `json:"packaging,omitempty" validate:"omitempty,dive"`
+	Rule      *PackRuleRequest `json:"rule,omitempty"`
 }
 
 // UpdatePackRequest represents API request for updating a pack
 type UpdatePackRequest struct {
-	Size int    `json:"size" validate:"required,gt=0"`
-	Name string `json:"name" validate:"required,min=1"`
+	Size         int    `json:"size"          validate:"required,gt=0"`
+	Name         string `json:"name"          validate:"required,min=1"`
+	SKU          string `json:"sku,omitempty" validate:"max=255"`
+	Stock        *int   `json:"stock"         validate:"omitempty,gte=0"`
+	HandlingCost int64  `json:"handling_cost" validate:"gte=0"`
+
+	PackMeasurementsRequest
+	Packaging PackagingRequest `json:"packaging,omitempty" validate:"omitempty,dive"`
+	Rule      *PackRuleRequest `json:"rule,omitempty"`
 }
 
 // PackResponse represents API response for pack operations
 type PackResponse struct {
-	ID        string    `json:"id"`
-	Size      int       `json:"size"`
-	Name      string    `json:"name"`
-	Active    bool      `json:"active"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID           string    `json:"id"`
+	Size         int       `json:"size"`
+	Name         string    `json:"name"`
+	SKU          string    `json:"sku,omitempty"`
+	Active       bool      `json:"active"`
+	Stock        *int      `json:"stock,omitempty"`
+	HandlingCost int64     `json:"handling_cost"`
+	WeightGrams  int64     `json:"weight_grams"`
+	LengthMm     int64     `json:"length_mm"`
+	WidthMm      int64     `json:"width_mm"`
+	HeightMm     int64     `json:"height_mm"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	Packaging []PackagingLevelResponse `json:"packaging,omitempty"`
+	Rule      *PackRuleResponse        `json:"rule,omitempty"`
+}
+
+// PackRuleResponse is the rule of a pack; omitted when it has none
+type PackRuleResponse struct {
+	Required         bool `json:"required,omitempty"`
+	Forbidden        bool `json:"forbidden,omitempty"`
+	MinCount         int  `json:"min_count,omitempty"`
+	MaxCount         *int `json:"max_count,omitempty"`
+	MinOrderQuantity int  `json:"min_order_quantity,omitempty"`
+}
+
+// PackagingLevelResponse is an outer unit of a pack's packaging
+type PackagingLevelResponse struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
 }
 
 // PackListResponse represents API response for multiple packs
@@ -37,13 +141,48 @@ type PackListResponse struct {
 // ToPackResponse converts domain model to API response
 func ToPackResponse(pack *model.Pack) *PackResponse {
 	return &PackResponse{
-		ID:        pack.ID,
-		Size:      pack.Size,
-		Name:      pack.Name,
-		Active:    pack.Active,
-		CreatedAt: pack.CreatedAt,
-		UpdatedAt: pack.UpdatedAt,
+		ID:           pack.ID,
+		Size:         pack.Size,
+		Name:         pack.Name,
+		SKU:          pack.SKU,
+		Active:       pack.Active,
+		Stock:        pack.Stock,
+		HandlingCost: pack.HandlingCost,
+		WeightGrams:  pack.WeightGrams,
+		LengthMm:     pack.LengthMm,
+		WidthMm:      pack.WidthMm,
+		HeightMm:     pack.HeightMm,
+		CreatedAt:    pack.CreatedAt,
+		UpdatedAt:    pack.UpdatedAt,
+		Packaging:    toPackagingLevelResponses(pack.Packaging),
+		Rule:         toPackRuleResponse(pack.Rule),
+	}
+}
+
+// toPackRuleResponse converts a domain rule to API response
+func toPackRuleResponse(rule model.PackRule) *PackRuleResponse {
+	if rule.IsZero() {
+		return nil
+	}
+	return &PackRuleResponse{
+		Required:         rule.Required,
+		Forbidden:        rule.Forbidden,
+		MinCount:         rule.MinCount,
+		MaxCount:         rule.MaxCount,
+		MinOrderQuantity: rule.MinOrderQuantity,
+	}
+}
+
+// toPackagingLevelResponses converts domain packaging to API response
+func toPackagingLevelResponses(packaging model.Packaging) []PackagingLevelResponse {
+	if packaging == nil {
+		return nil
+	}
+	levels := make([]PackagingLevelResponse, len(packaging))
+	for i, level := range packaging {
+		levels[i] = PackagingLevelResponse{Name: level.Name, Quantity: level.Quantity}
 	}
+	return levels
 }
 
 // ToPackListResponse converts domain models to API response
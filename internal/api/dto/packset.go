@@ -0,0 +1,146 @@
+package dto
+
+import (
+	"time"
+
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/domain/repository"
+)
+
+// PackSizeRecommendationRequest represents API request for the pack sizes
+// to stock. Demand is taken from Demand or DemandCSV when one is given and
+// otherwise from the calculation history matching History.
+type PackSizeRecommendationRequest struct {
+	MaxPackSizes   int                   `json:"max_pack_sizes"  validate:"required,gt=0"`
+	CandidateSizes []int                 `json:"candidate_sizes" validate:"omitempty,dive,gt=0"`
+	Top            int                   `json:"top"             validate:"gte=0"`
+	Demand         []DemandBucketRequest `json:"demand"          validate:"omitempty,dive"`
+	DemandCSV      string                `json:"demand_csv"      validate:"excluded_with=Demand"`
+	History        *HistoryDemandRequest `json:"history"`
+}
+
+// DemandBucketRequest is how many orders asked for a quantity
+type DemandBucketRequest struct {
+	Quantity int `json:"quantity" validate:"required,gt=0"`
+	Orders   int `json:"orders"   validate:"required,gt=0"`
+}
+
+// HistoryDemandRequest narrows the calculation history that makes up the
+// demand; every field is optional
+type HistoryDemandRequest struct {
+	MinQuantity   int        `json:"min_quantity"   validate:"gte=0"`
+	MaxQuantity   int        `json:"max_quantity"   validate:"gte=0"`
+	CreatedAfter  *time.Time `json:"created_after"`
+	CreatedBefore *time.Time `json:"created_before"`
+	UserID        string     `json:"user_id"`
+}
+
+// DemandHistogram converts the request's demand buckets to the domain
+func (r PackSizeRecommendationRequest) DemandHistogram() model.DemandHistogram {
+	histogram := make(model.DemandHistogram, len(r.Demand))
+	for i, bucket := range r.Demand {
+		histogram[i] = model.DemandBucket{Quantity: bucket.Quantity, Orders: bucket.Orders}
+	}
+	return histogram
+}
+
+// HistoryFilter converts the history request to a calculation filter
+func (r PackSizeRecommendationRequest) HistoryFilter() repository.CalculationFilter {
+	var filter repository.CalculationFilter
+	if r.History == nil {
+		return filter
+	}
+	filter.MinOrderQuantity = r.History.MinQuantity
+	filter.MaxOrderQuantity = r.History.MaxQuantity
+	filter.UserID = r.History.UserID
+	if r.History.CreatedAfter != nil {
+		filter.CreatedAfter = *r.History.CreatedAfter
+	}
+	if r.History.CreatedBefore != nil {
+		filter.CreatedBefore = *r.History.CreatedBefore
+	}
+	return filter
+}
+
+// PackSizeRecommendationResponse represents API response for a pack size
+// recommendation, best pack set first
+type PackSizeRecommendationResponse struct {
+	OrderCount         int                    `json:"order_count"`
+	DistinctQuantities int                    `json:"distinct_quantities"`
+	Recommendations    []PackSetScoreResponse `json:"recommendations"`
+}
+
+// PackSetScoreResponse is how one candidate pack set serves the demand
+type PackSetScoreResponse struct {
+	Rank            int     `json:"rank"`
+	PackSizes       []int   `json:"pack_sizes"`
+	ExpectedOverage float64 `json:"expected_overage"`
+	ExpectedPacks   float64 `json:"expected_packs"`
+	OverageRate     float64 `json:"overage_rate"`
+}
+
+// ToPackSizeRecommendationResponse converts the scored pack sets for demand
+// to API response
+func ToPackSizeRecommendationResponse(
+	demand model.DemandHistogram,
+	scores []model.PackSetScore,
+) *PackSizeRecommendationResponse {
+	response := &PackSizeRecommendationResponse{
+		OrderCount:         demand.TotalOrders(),
+		DistinctQuantities: len(demand.Merge()),
+		Recommendations:    make([]PackSetScoreResponse, len(scores)),
+	}
+	for i, score := range scores {
+		response.Recommendations[i] = PackSetScoreResponse{
+			Rank:            i + 1,
+			PackSizes:       score.PackSizes,
+			ExpectedOverage: score.ExpectedOverage,
+			ExpectedPacks:   score.ExpectedPacks,
+			OverageRate:     score.OverageRate,
+		}
+	}
+	return response
+}
+
+// PackSetAnalysisResponse represents API response for a pack set analysis
+type PackSetAnalysisResponse struct {
+	PackSizes       []int                  `json:"pack_sizes"`
+	GCD             int                    `json:"gcd"`
+	FrobeniusNumber int                    `json:"frobenius_number"`
+	RedundantSizes  []int                  `json:"redundant_sizes"`
+	MaxOverage      int                    `json:"max_overage"`
+	OverageCurve    []OveragePointResponse `json:"overage_curve"`
+}
+
+// OveragePointResponse is how the optimal distribution ships one order
+// quantity of the curve
+type OveragePointResponse struct {
+	OrderQuantity int `json:"order_quantity"`
+	TotalItems    int `json:"total_items"`
+	TotalPacks    int `json:"total_packs"`
+	ItemsOverage  int `json:"items_overage"`
+}
+
+// ToPackSetAnalysisResponse converts domain analysis to API response
+func ToPackSetAnalysisResponse(analysis *model.PackSetAnalysis) *PackSetAnalysisResponse {
+	response := &PackSetAnalysisResponse{
+		PackSizes:       analysis.PackSizes,
+		GCD:             analysis.GCD,
+		FrobeniusNumber: analysis.FrobeniusNumber,
+		RedundantSizes:  analysis.RedundantSizes,
+		OverageCurve:    make([]OveragePointResponse, len(analysis.OverageCurve)),
+	}
+	if response.RedundantSizes == nil {
+		response.RedundantSizes = []int{}
+	}
+	for i, point := range analysis.OverageCurve {
+		response.MaxOverage = max(response.MaxOverage, point.Overage)
+		response.OverageCurve[i] = OveragePointResponse{
+			OrderQuantity: point.OrderQuantity,
+			TotalItems:    point.TotalItems,
+			TotalPacks:    point.TotalPacks,
+			ItemsOverage:  point.Overage,
+		}
+	}
+	return response
+}
@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"pack-calculator/internal/domain/model"
+)
+
+// ShipmentPlanRequest represents API request for splitting an order into
+// shipments. The order is a recorded calculation when CalculationID is set
+// and is otherwise calculated from PackSizes and OrderQuantity. At least
+// one of MaxPacks and MaxItems must be set.
+type ShipmentPlanRequest struct {
+	CalculationID string `json:"calculation_id,omitempty"`
+	PackSizes     []int  `json:"pack_sizes,omitempty"     validate:"omitempty,dive,gt=0"`
+	OrderQuantity int    `json:"order_quantity,omitempty" validate:"gte=0"`
+	MaxPacks      int    `json:"max_packs"                validate:"gte=0"`
+	MaxItems      int    `json:"max_items"                validate:"gte=0"`
+}
+
+// Capacity returns the per-shipment capacity of the request
+func (r ShipmentPlanRequest) Capacity() model.ShipmentCapacity {
+	return model.ShipmentCapacity{MaxPacks: r.MaxPacks, MaxItems: r.MaxItems}
+}
+
+// ShipmentPlanResponse represents API response for a shipment plan
+type ShipmentPlanResponse struct {
+	Calculation   *CalculationResponse `json:"calculation"`
+	ShipmentCount int                  `json:"shipment_count"`
+	Shipments     []ShipmentResponse   `json:"shipments"`
+}
+
+// ShipmentResponse is one shipment of a plan
+type ShipmentResponse struct {
+	ID            string      `json:"id"`
+	CalculationID string      `json:"calculation_id"`
+	Sequence      int         `json:"sequence"`
+	PacksUsed     map[int]int `json:"packs_used"`
+	TotalItems    int         `json:"total_items"`
+	TotalPacks    int         `json:"total_packs"`
+}
+
+// ToShipmentPlanResponse converts a calculation and its shipments to API
+// response
+func ToShipmentPlanResponse(
+	calculation *model.Calculation,
+	shipments []model.Shipment,
+) *ShipmentPlanResponse {
+	response := &ShipmentPlanResponse{
+		Calculation:   ToCalculationResponse(calculation),
+		ShipmentCount: len(shipments),
+		Shipments:     make([]ShipmentResponse, len(shipments)),
+	}
+	for i, shipment := range shipments {
+		response.Shipments[i] = ShipmentResponse{
+			ID:            shipment.ID,
+			CalculationID: shipment.CalculationID,
+			Sequence:      shipment.Sequence,
+			PacksUsed:     map[int]int(shipment.Packs),
+			TotalItems:    shipment.TotalItems,
+			TotalPacks:    shipment.TotalPacks,
+		}
+	}
+	return response
+}
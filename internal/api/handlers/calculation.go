@@ -9,6 +9,7 @@ import (
 
 	"pack-calculator/internal/api/dto"
 	apihttp "pack-calculator/internal/api/http"
+	"pack-calculator/internal/domain/model"
 	"pack-calculator/internal/domain/service"
 	"pack-calculator/internal/infrastructure/logger"
 )
@@ -25,7 +26,7 @@ func NewCalculationHandler(
 ) *CalculationHandler {
 	return &CalculationHandler{
 		packService: packService,
-		validator:   validator.New(),
+		validator:   newValidator(),
 	}
 }
 
@@ -42,7 +43,7 @@ func (h *CalculationHandler) Calculate(w http.ResponseWriter, r *http.Request) {
 			"request_id": requestID,
 			"error":      err.Error(),
 		})
-		apihttp.WriteErrorResponse(w, http.StatusBadRequest, "Invalid JSON format")
+		apihttp.WriteError(w, r, apihttp.ErrInvalidJSON)
 		return
 	}
 
@@ -58,12 +59,40 @@ func (h *CalculationHandler) Calculate(w http.ResponseWriter, r *http.Request) {
 			"request_id": requestID,
 			"error":      err.Error(),
 		})
-		apihttp.WriteErrorResponse(w, http.StatusBadRequest, "Validation failed: "+err.Error())
+		apihttp.WriteValidationError(w, r, err)
+		return
+	}
+
+	objective, err := service.NewObjective(req.Objective, model.CostModel{
+		ItemCost:     req.ItemCost,
+		HandlingCost: req.PackCosts,
+	})
+	if err != nil {
+		logger.Warn("Invalid calculation objective", map[string]interface{}{
+			"request_id": requestID,
+			"objective":  req.Objective,
+			"error":      err.Error(),
+		})
+		apihttp.WriteError(w, r, err)
 		return
 	}
 
 	// Perform calculation
-	result, err := h.packService.CalculateOptimal(r.Context(), req.PackSizes, req.OrderQuantity)
+	result, err := h.packService.CalculateWithOptions(
+		requestContext(r),
+		req.PackSizes,
+		req.OrderQuantity,
+		service.SolveOptions{
+			Stock:        req.Stock,
+			Objective:    objective,
+			Alternatives: req.Alternatives,
+			Measurements: req.Measurements.ToModel(),
+			Caps:         req.Caps.ToModel(),
+			Packaging:    req.Packaging.ToModel(),
+			Tolerance:    req.Tolerance.ToModel(),
+			Rules:        req.Rules.ToModel(),
+		},
+	)
 	if err != nil {
 		logger.Error("Calculation failed", map[string]interface{}{
 			"request_id":     requestID,
@@ -71,7 +100,7 @@ func (h *CalculationHandler) Calculate(w http.ResponseWriter, r *http.Request) {
 			"order_quantity": req.OrderQuantity,
 			"error":          err.Error(),
 		})
-		apihttp.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		apihttp.WriteError(w, r, err)
 		return
 	}
 
@@ -84,6 +113,8 @@ func (h *CalculationHandler) Calculate(w http.ResponseWriter, r *http.Request) {
 		"total_items":    result.TotalItems,
 		"total_packs":    result.TotalPacks,
 		"items_overage":  result.ItemsOverage,
+		"items_delta":    result.ItemsDelta,
+		"split_required": result.SplitRequired,
 		"duration_ms":    duration.Milliseconds(),
 		"calculation_id": result.ID,
 	})
@@ -92,3 +123,143 @@ func (h *CalculationHandler) Calculate(w http.ResponseWriter, r *http.Request) {
 	response := dto.ToCalculationResponse(result)
 	apihttp.WriteSuccessResponse(w, http.StatusOK, response)
 }
+
+// CalculateBatch handles POST /api/v1/calculate/batch
+func (h *CalculationHandler) CalculateBatch(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	requestID := r.Header.Get("X-Request-ID")
+
+	var req dto.BatchCalculationRequest
+
+	// Parse JSON request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("Invalid JSON request", map[string]interface{}{
+			"request_id": requestID,
+			"error":      err.Error(),
+		})
+		apihttp.WriteError(w, r, apihttp.ErrInvalidJSON)
+		return
+	}
+
+	// Validate request; individual items are validated by the service so
+	// one bad line does not reject the batch
+	if err := h.validator.Struct(req); err != nil {
+		logger.Warn("Request validation failed", map[string]interface{}{
+			"request_id": requestID,
+			"error":      err.Error(),
+		})
+		apihttp.WriteValidationError(w, r, err)
+		return
+	}
+
+	items := make([]service.BatchItem, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = service.BatchItem{
+			Reference:     item.Reference,
+			PackSizes:     item.PackSizes,
+			OrderQuantity: item.OrderQuantity,
+		}
+	}
+
+	// Perform calculations
+	results, err := h.packService.CalculateBatch(requestContext(r), items)
+	if err != nil {
+		logger.Error("Batch calculation failed", map[string]interface{}{
+			"request_id": requestID,
+			"items":      len(items),
+			"error":      err.Error(),
+		})
+		apihttp.WriteError(w, r, err)
+		return
+	}
+
+	response := &dto.BatchCalculationResponse{
+		Items: make([]dto.BatchItemResponse, len(results)),
+		Total: len(results),
+	}
+	for i, result := range results {
+		item := dto.BatchItemResponse{
+			Index:     i,
+			Reference: result.Reference,
+		}
+		if result.Err != nil {
+			mapping := apihttp.MapError(result.Err)
+			message := mapping.Message
+			if mapping.Code != apihttp.CodeInternalError {
+				message = result.Err.Error()
+			}
+			item.Error = &dto.BatchItemError{Code: mapping.Code, Message: message}
+			response.Failed++
+		} else {
+			item.Success = true
+			item.Result = dto.ToCalculationResponse(result.Calculation)
+			response.Succeeded++
+		}
+		response.Items[i] = item
+	}
+
+	// Log batch summary
+	logger.Info("Batch calculation completed", map[string]interface{}{
+		"request_id":  requestID,
+		"items":       response.Total,
+		"succeeded":   response.Succeeded,
+		"failed":      response.Failed,
+		"duration_ms": time.Since(start).Milliseconds(),
+	})
+
+	apihttp.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+// Explain handles POST /api/v1/calculate/explain
+func (h *CalculationHandler) Explain(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	requestID := r.Header.Get("X-Request-ID")
+
+	var req dto.ExplainRequest
+
+	// Parse JSON request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("Invalid JSON request", map[string]interface{}{
+			"request_id": requestID,
+			"error":      err.Error(),
+		})
+		apihttp.WriteError(w, r, apihttp.ErrInvalidJSON)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		logger.Warn("Request validation failed", map[string]interface{}{
+			"request_id": requestID,
+			"error":      err.Error(),
+		})
+		apihttp.WriteValidationError(w, r, err)
+		return
+	}
+
+	// Perform calculation
+	result, err := h.packService.Explain(requestContext(r), req.PackSizes, req.OrderQuantity)
+	if err != nil {
+		logger.Error("Explanation failed", map[string]interface{}{
+			"request_id":     requestID,
+			"pack_sizes":     req.PackSizes,
+			"order_quantity": req.OrderQuantity,
+			"error":          err.Error(),
+		})
+		apihttp.WriteError(w, r, err)
+		return
+	}
+
+	logger.Info("Explanation completed", map[string]interface{}{
+		"request_id":     requestID,
+		"pack_sizes":     req.PackSizes,
+		"order_quantity": req.OrderQuantity,
+		"total_items":    result.Calculation.TotalItems,
+		"total_packs":    result.Calculation.TotalPacks,
+		"duration_ms":    time.Since(start).Milliseconds(),
+		"calculation_id": result.Calculation.ID,
+	})
+
+	response := dto.ToExplainResponse(result.Calculation, result.Explanation)
+	apihttp.WriteSuccessResponse(w, http.StatusOK, response)
+}
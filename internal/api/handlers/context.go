@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"pack-calculator/internal/domain/service"
+)
+
+// requestContext returns the request context, carrying the caller's user
+// ID from the X-User-ID header so calculations are attributed to them
+func requestContext(r *http.Request) context.Context {
+	if userID := r.Header.Get("X-User-ID"); userID != "" {
+		return service.ContextWithUserID(r.Context(), userID)
+	}
+	return r.Context()
+}
@@ -4,12 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"pack-calculator/internal/api/dto"
+	apihttp "pack-calculator/internal/api/http"
 	"pack-calculator/internal/domain/service"
+	"pack-calculator/internal/infrastructure/persistence"
 )
 
 func TestCalculationHandler_Calculate(t *testing.T) {
@@ -58,6 +65,57 @@ func TestCalculationHandler_Calculate(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			expectSuccess:  false,
 		},
+		{
+			name: "Limited stock",
+			requestBody: dto.CalculationRequest{
+				PackSizes:     []int{250, 500, 1000, 2000, 5000},
+				OrderQuantity: 20000,
+				Stock:         map[int]int{5000: 3},
+			},
+			expectedStatus: http.StatusOK,
+			expectSuccess:  true,
+		},
+		{
+			name: "Insufficient stock",
+			requestBody: dto.CalculationRequest{
+				PackSizes:     []int{250, 500},
+				OrderQuantity: 1000,
+				Stock:         map[int]int{250: 1, 500: 1},
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectSuccess:  false,
+		},
+		{
+			name: "Invalid request - negative stock",
+			requestBody: dto.CalculationRequest{
+				PackSizes:     []int{250, 500},
+				OrderQuantity: 100,
+				Stock:         map[int]int{250: -1},
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectSuccess:  false,
+		},
+		{
+			name: "Min cost objective",
+			requestBody: dto.CalculationRequest{
+				PackSizes:     []int{250, 1000},
+				OrderQuantity: 1000,
+				Objective:     "min_cost",
+				PackCosts:     map[int]int64{250: 10, 1000: 500},
+			},
+			expectedStatus: http.StatusOK,
+			expectSuccess:  true,
+		},
+		{
+			name: "Invalid request - unknown objective",
+			requestBody: dto.CalculationRequest{
+				PackSizes:     []int{250, 500},
+				OrderQuantity: 100,
+				Objective:     "cheapest",
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectSuccess:  false,
+		},
 		{
 			name:           "Invalid JSON",
 			requestBody:    "invalid json",
@@ -134,51 +192,1305 @@ func TestCalculationHandler_Calculate(t *testing.T) {
 						}
 					}
 				}
+
+				if tt.name == "Min cost objective" {
+					if data["objective"] != "min_cost" || data["total_cost"] != float64(40) {
+						t.Errorf("Expected min_cost with total cost 40, got %v", data)
+					}
+				}
 			}
 		})
 	}
 }
 
-func TestHealthHandler_Health(t *testing.T) {
-	handler := NewHealthHandler()
+func TestCalculationHandler_ValidationDetails(t *testing.T) {
+	handler := NewCalculationHandler(service.NewPackService())
 
-	req := httptest.NewRequest("GET", "/health", nil)
+	body := []byte(`{"pack_sizes": [250, 0], "order_quantity": 0}`)
+	req := httptest.NewRequest("POST", "/api/v1/calculate", bytes.NewBuffer(body))
 	rr := httptest.NewRecorder()
+	handler.Calculate(rr, req)
 
-	handler.Health(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	var response apihttp.ErrorResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Code != apihttp.CodeValidationFailed {
+		t.Errorf("Expected code %s, got %s", apihttp.CodeValidationFailed, response.Code)
+	}
+
+	fields := make(map[string]string)
+	for _, detail := range response.Details {
+		fields[detail.Field] = detail.Rule
+	}
+
+	expected := map[string]string{"pack_sizes[1]": "gt", "order_quantity": "required"}
+	for field, rule := range expected {
+		if fields[field] != rule {
+			t.Errorf("Expected %s to fail %s, got details %+v", field, rule, response.Details)
+		}
+	}
+}
+
+func TestCalculationHandler_ProblemJSON(t *testing.T) {
+	handler := NewCalculationHandler(service.NewPackService())
+
+	body := []byte(`{"pack_sizes": [250, 0], "order_quantity": 10}`)
+	req := httptest.NewRequest("POST", "/api/v1/calculate", bytes.NewBuffer(body))
+	req.Header.Set("Accept", apihttp.ProblemContentType)
+	req.Header.Set("X-Request-ID", "req-123")
+	rr := httptest.NewRecorder()
+	handler.Calculate(rr, req)
+
+	if ct := rr.Header().Get("Content-Type"); ct != apihttp.ProblemContentType {
+		t.Errorf("Expected content type %s, got %s", apihttp.ProblemContentType, ct)
+	}
+
+	var problem apihttp.ProblemDetails
+	if err := json.Unmarshal(rr.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if problem.Status != http.StatusBadRequest || problem.Instance != "req-123" {
+		t.Errorf("Unexpected problem: %+v", problem)
+	}
+
+	if len(problem.Errors) != 1 || problem.Errors[0].Field != "pack_sizes[1]" {
+		t.Errorf("Expected pack_sizes[1] field error, got %+v", problem.Errors)
+	}
+}
+
+func TestCalculationHandler_Cancellation(t *testing.T) {
+	// Far below the periodic cutoff, so the solve walks every total
+	body, err := json.Marshal(dto.CalculationRequest{
+		PackSizes:     []int{99991, 99989},
+		OrderQuantity: 10000000,
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+
+	t.Run("Client disconnected", func(t *testing.T) {
+		handler := NewCalculationHandler(service.NewPackService())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		req := httptest.NewRequest("POST", "/api/v1/calculate", bytes.NewBuffer(body))
+		req = req.WithContext(ctx)
+		rr := httptest.NewRecorder()
+		handler.Calculate(rr, req)
+
+		if rr.Code != apihttp.StatusClientClosedRequest {
+			t.Errorf("Expected status %d, got %d", apihttp.StatusClientClosedRequest, rr.Code)
+		}
+	})
+
+	t.Run("Solve time exceeded", func(t *testing.T) {
+		handler := NewCalculationHandler(
+			service.NewPackService(service.WithMaxSolveTime(time.Millisecond)),
+		)
+
+		req := httptest.NewRequest("POST", "/api/v1/calculate", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+		handler.Calculate(rr, req)
+
+		if rr.Code != http.StatusRequestTimeout {
+			t.Errorf("Expected status %d, got %d", http.StatusRequestTimeout, rr.Code)
+		}
+	})
+}
+
+func TestCalculationHandler_Limits(t *testing.T) {
+	handler := NewCalculationHandler(
+		service.NewPackService(service.WithLimits(service.Limits{MaxOrderQuantity: 1000})),
+	)
+
+	// Far below the periodic cutoff, so the solve walks every total
+	body, err := json.Marshal(dto.CalculationRequest{
+		PackSizes:     []int{499, 497},
+		OrderQuantity: 1001,
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/api/v1/calculate", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+	handler.Calculate(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+	}
+
+	var response apihttp.ErrorResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Code != "ORDER_TOO_LARGE" {
+		t.Errorf("Expected code ORDER_TOO_LARGE, got %q", response.Code)
+	}
+}
+
+func TestCalculationHandler_Alternatives(t *testing.T) {
+	handler := NewCalculationHandler(
+		service.NewPackService(service.WithLimits(service.Limits{MaxAlternatives: 5})),
+	)
+
+	calculate := func(alternatives int) *httptest.ResponseRecorder {
+		body, err := json.Marshal(dto.CalculationRequest{
+			PackSizes:     []int{250, 500, 1000},
+			OrderQuantity: 263,
+			Alternatives:  alternatives,
+		})
+		if err != nil {
+			t.Fatalf("Failed to marshal request: %v", err)
+		}
+		req := httptest.NewRequest("POST", "/api/v1/calculate", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+		handler.Calculate(rr, req)
+		return rr
+	}
 
+	rr := calculate(3)
 	if rr.Code != http.StatusOK {
-		t.Errorf("Expected status %d, got %d", http.StatusOK, rr.Code)
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
 	}
 
-	var response dto.HealthResponse
+	var response struct {
+		Data dto.CalculationResponse `json:"data"`
+	}
 	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
 		t.Fatalf("Failed to unmarshal response: %v", err)
 	}
 
-	if response.Status != "healthy" {
-		t.Errorf("Expected status 'healthy', got '%s'", response.Status)
+	alternatives := response.Data.Alternatives
+	if len(alternatives) != 3 {
+		t.Fatalf("Expected 3 alternatives, got %+v", alternatives)
+	}
+	if alternatives[0].Rank != 1 || alternatives[0].PacksUsed[500] != 1 {
+		t.Errorf("Expected the optimum ranked first, got %+v", alternatives[0])
+	}
+	if alternatives[1].TotalPacks != 2 || alternatives[2].TotalItems != 1000 {
+		t.Errorf("Unexpected alternatives: %+v", alternatives)
+	}
+
+	rr = calculate(6)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+	}
+	var errResponse apihttp.ErrorResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &errResponse); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if errResponse.Code != "TOO_MANY_ALTERNATIVES" {
+		t.Errorf("Expected code TOO_MANY_ALTERNATIVES, got %q", errResponse.Code)
 	}
 }
 
-func TestHealthHandler_Ready(t *testing.T) {
-	handler := NewHealthHandler()
+func TestCalculationHandler_ShipmentCaps(t *testing.T) {
+	handler := NewCalculationHandler(service.NewPackService())
 
-	req := httptest.NewRequest("GET", "/ready", nil)
+	calculate := func(orderQuantity int, caps dto.ShipmentCapsRequest) *httptest.ResponseRecorder {
+		body, err := json.Marshal(dto.CalculationRequest{
+			PackSizes:     []int{250, 500, 1000},
+			OrderQuantity: orderQuantity,
+			Measurements: dto.MeasurementsRequest{
+				250:  {WeightGrams: 300},
+				500:  {WeightGrams: 550},
+				1000: {WeightGrams: 1050},
+			},
+			Caps: &caps,
+		})
+		if err != nil {
+			t.Fatalf("Failed to marshal request: %v", err)
+		}
+		req := httptest.NewRequest("POST", "/api/v1/calculate", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+		handler.Calculate(rr, req)
+		return rr
+	}
+
+	rr := calculate(5000, dto.ShipmentCapsRequest{MaxWeightGrams: 2000})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	var response struct {
+		Data dto.CalculationResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Data.TotalWeight != 5250 {
+		t.Errorf("Expected total weight 5250, got %d", response.Data.TotalWeight)
+	}
+	if !response.Data.SplitRequired || response.Data.MinShipments != 3 {
+		t.Errorf("Expected a split into 3 shipments, got %+v", response.Data)
+	}
+
+	rr = calculate(1001, dto.ShipmentCapsRequest{MaxPackWeightGrams: 200})
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d, got %d", http.StatusUnprocessableEntity, rr.Code)
+	}
+	var errResponse apihttp.ErrorResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &errResponse); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if errResponse.Code != "PACK_TOO_HEAVY" {
+		t.Errorf("Expected code PACK_TOO_HEAVY, got %q", errResponse.Code)
+	}
+
+	if rr := calculate(1001, dto.ShipmentCapsRequest{MaxPacks: -1}); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a negative cap, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestCalculationHandler_Packaging(t *testing.T) {
+	handler := NewCalculationHandler(service.NewPackService())
+
+	calculate := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/calculate", bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		handler.Calculate(rr, req)
+		return rr
+	}
+
+	rr := calculate(`{"pack_sizes": [250], "order_quantity": 86250, "packaging": {"250": [
+		{"name": "carton", "quantity": 8}, {"name": "pallet", "quantity": 40}]}}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var response struct {
+		Data dto.CalculationResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	// 345 packs are one pallet of 40 cartons, 3 more cartons and 1 pack
+	packaging := response.Data.Packaging
+	if len(packaging) != 3 {
+		t.Fatalf("Expected 3 packaging levels, got %+v", packaging)
+	}
+	pallet := packaging[0]
+	if pallet.Unit != "pallet" || pallet.Count != 1 || pallet.ItemsPerUnit != 80000 {
+		t.Errorf("Unexpected pallets: %+v", pallet)
+	}
+	if pallet.Contents == nil || pallet.Contents.Unit != "carton" || pallet.Contents.Count != 40 ||
+		pallet.Contents.Contents == nil || pallet.Contents.Contents.Count != 8 {
+		t.Errorf("Unexpected pallet contents: %+v", pallet.Contents)
+	}
+	if packaging[1].Unit != "carton" || packaging[1].Count != 3 {
+		t.Errorf("Unexpected cartons: %+v", packaging[1])
+	}
+	if packaging[2].Unit != "pack" || packaging[2].Count != 1 || packaging[2].Contents != nil {
+		t.Errorf("Unexpected loose packs: %+v", packaging[2])
+	}
+
+	rr = calculate(`{"pack_sizes": [250], "order_quantity": 1, "packaging": {"250": [
+		{"name": "carton", "quantity": 0}]}}`)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an empty carton, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	rr = calculate(`{"pack_sizes": [250], "order_quantity": 1}`)
+	if strings.Contains(rr.Body.String(), "packaging") {
+		t.Errorf("Expected no packaging without a hierarchy, got %s", rr.Body.String())
+	}
+}
+
+func TestCalculationHandler_Tolerance(t *testing.T) {
+	handler := NewCalculationHandler(service.NewPackService())
+
+	calculate := func(tolerance string) *httptest.ResponseRecorder {
+		body := `{"pack_sizes": [250, 500, 1000], "order_quantity": 1001, "tolerance": ` +
+			tolerance + `}`
+		req := httptest.NewRequest("POST", "/api/v1/calculate", bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		handler.Calculate(rr, req)
+		return rr
+	}
+
+	rr := calculate(`{"max_shortfall": 5}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	var response struct {
+		Data dto.CalculationResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Data.TotalItems != 1000 || response.Data.ItemsDelta != -1 ||
+		response.Data.ItemsOverage != 0 {
+		t.Errorf("Expected 1000 items, 1 short, got %+v", response.Data)
+	}
+
+	tests := []struct {
+		name      string
+		tolerance string
+		status    int
+		code      string
+	}{
+		{"overage cap not met", `{"max_overage": 100}`,
+			http.StatusUnprocessableEntity, "TOLERANCE_NOT_MET"},
+		{"mixed shortfall units", `{"max_shortfall": 1, "max_shortfall_percent": 1}`,
+			http.StatusBadRequest, "INVALID_TOLERANCE"},
+		{"whole order short", `{"max_shortfall_percent": 100}`,
+			http.StatusBadRequest, "VALIDATION_FAILED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := calculate(tt.tolerance)
+			if rr.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, rr.Code)
+			}
+			var errResponse apihttp.ErrorResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &errResponse); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if errResponse.Code != tt.code {
+				t.Errorf("Expected code %s, got %q", tt.code, errResponse.Code)
+			}
+		})
+	}
+}
+
+func TestCalculationHandler_Rules(t *testing.T) {
+	handler := NewCalculationHandler(service.NewPackService())
+	rules := func(rules string) string {
+		return `{"pack_sizes": [250, 500, 1000], "order_quantity": 1000, "rules": ` + rules + `}`
+	}
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		expectedStatus int
+		expectedCode   string
+		expectedPacks  map[int]int
+	}{
+		{
+			name:           "Required and gated sizes",
+			requestBody:    rules(`{"250": {"required": true}, "1000": {"min_order_quantity": 5000}}`),
+			expectedStatus: http.StatusOK,
+			expectedPacks:  map[int]int{250: 2, 500: 1},
+		},
+		{
+			name:           "Required size missing",
+			requestBody:    rules(`{"2000": {"required": true}}`),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "PACK_RULES_NOT_MET",
+		},
+		{
+			name:           "Required and forbidden",
+			requestBody:    rules(`{"250": {"required": true, "forbidden": true}}`),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "INVALID_PACK_RULE",
+		},
+		{
+			name:           "Negative count",
+			requestBody:    rules(`{"250": {"min_count": -1}}`),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   apihttp.CodeValidationFailed,
+		},
+		{
+			name: "Required packs within the overage cap",
+			requestBody: `{"pack_sizes": [13], "order_quantity": 12, "tolerance": {"max_overage": 2},
+				"rules": {"13": {"required": true, "max_count": 1}}}`,
+			expectedStatus: http.StatusOK,
+			expectedPacks:  map[int]int{13: 1},
+		},
+		{
+			// The required pack uses up every size but overshoots the cap
+			name: "Required packs beyond the overage cap",
+			requestBody: `{"pack_sizes": [13], "order_quantity": 9, "tolerance": {"max_overage": 2},
+				"rules": {"13": {"required": true, "max_count": 1}}}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "PACK_RULES_NOT_MET",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/calculate", strings.NewReader(tt.requestBody))
+			rr := httptest.NewRecorder()
+			handler.Calculate(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, rr.Code, rr.Body)
+			}
+
+			if tt.expectedCode != "" {
+				if response := decodeError(t, rr); response.Code != tt.expectedCode {
+					t.Errorf("Expected code %s, got %q", tt.expectedCode, response.Code)
+				}
+				return
+			}
+
+			var response struct {
+				Data dto.CalculationResponse `json:"data"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if !reflect.DeepEqual(response.Data.PacksUsed, tt.expectedPacks) {
+				t.Errorf("Expected %v, got %v", tt.expectedPacks, response.Data.PacksUsed)
+			}
+		})
+	}
+}
+
+func TestCalculationHandler_Explain(t *testing.T) {
+	handler := NewCalculationHandler(service.NewPackService())
+
+	body := []byte(`{"pack_sizes": [250, 500, 1000, 2000, 5000], "order_quantity": 12001}`)
+	req := httptest.NewRequest("POST", "/api/v1/calculate/explain", bytes.NewBuffer(body))
 	rr := httptest.NewRecorder()
+	handler.Explain(rr, req)
 
-	handler.Ready(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var response struct {
+		Data dto.ExplainResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Data.CalculationResponse == nil || response.Data.TotalItems != 12250 {
+		t.Fatalf("Unexpected calculation: %+v", response.Data.CalculationResponse)
+	}
+	explanation := response.Data.Explanation
+	if explanation.MinimalTotalItems != 12250 || explanation.PackSizeGCD != 250 {
+		t.Errorf("Unexpected explanation: %+v", explanation)
+	}
+	if explanation.LargestShortTotal == nil || explanation.LargestShortTotal.ItemsShort != 1 {
+		t.Errorf("Expected a total 1 item short, got %+v", explanation.LargestShortTotal)
+	}
+	if len(explanation.EqualOverageCandidates) == 0 || len(explanation.RejectedRunnerUps) == 0 {
+		t.Errorf("Expected candidates and runner-ups, got %+v", explanation)
+	}
+	if len(explanation.Steps) == 0 {
+		t.Errorf("Expected explanation steps")
+	}
+
+	body = []byte(`{"pack_sizes": [], "order_quantity": 10}`)
+	req = httptest.NewRequest("POST", "/api/v1/calculate/explain", bytes.NewBuffer(body))
+	rr = httptest.NewRecorder()
+	handler.Explain(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestCalculationHandler_CalculateBatch(t *testing.T) {
+	handler := NewCalculationHandler(service.NewPackService())
+
+	body, err := json.Marshal(dto.BatchCalculationRequest{
+		Items: []dto.BatchItemRequest{
+			{Reference: "line-1", PackSizes: []int{250, 500, 1000}, OrderQuantity: 263},
+			{Reference: "line-2", PackSizes: []int{250, 500, 1000}, OrderQuantity: 0},
+			{Reference: "line-3", PackSizes: []int{23, 31, 53}, OrderQuantity: 500000},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/api/v1/calculate/batch", bytes.NewBuffer(body))
+	rr := httptest.NewRecorder()
+	handler.CalculateBatch(rr, req)
 
 	if rr.Code != http.StatusOK {
-		t.Errorf("Expected status %d, got %d", http.StatusOK, rr.Code)
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
 	}
 
-	var response dto.HealthResponse
+	var response struct {
+		Success bool                         `json:"success"`
+		Data    dto.BatchCalculationResponse `json:"data"`
+	}
 	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
 		t.Fatalf("Failed to unmarshal response: %v", err)
 	}
 
-	if response.Status != "ready" {
-		t.Errorf("Expected status 'ready', got '%s'", response.Status)
+	if response.Data.Total != 3 || response.Data.Succeeded != 2 || response.Data.Failed != 1 {
+		t.Errorf("Unexpected summary: %+v", response.Data)
+	}
+
+	failed := response.Data.Items[1]
+	if failed.Success || failed.Error == nil || failed.Error.Code != "INVALID_ORDER_QUANTITY" {
+		t.Errorf("Expected line-2 to fail with INVALID_ORDER_QUANTITY, got %+v", failed)
+	}
+
+	edge := response.Data.Items[2]
+	if edge.Reference != "line-3" || edge.Result == nil || edge.Result.PacksUsed[53] != 9429 {
+		t.Errorf("Unexpected line-3 result: %+v", edge)
+	}
+}
+
+func TestPackHandler_CRUD(t *testing.T) {
+	handler := NewPackHandler(
+		service.NewPackConfigService(persistence.NewMemoryPackRepository()),
+	)
+	router := apihttp.NewRouter()
+	router.RegisterPackRoutes(
+		handler.List,
+		handler.Get,
+		handler.Create,
+		handler.Update,
+		handler.Delete,
+	)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		router.Handler().ServeHTTP(rr, req)
+		return rr
+	}
+
+	decode := func(rr *httptest.ResponseRecorder, data interface{}) {
+		envelope := struct {
+			Data interface{} `json:"data"`
+		}{Data: data}
+		if err := json.Unmarshal(rr.Body.Bytes(), &envelope); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+	}
+
+	rr := do("POST", "/api/v1/packs", `{"size": 250, "name": "Small"}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d", http.StatusCreated, rr.Code)
+	}
+	var created dto.PackResponse
+	decode(rr, &created)
+
+	rr = do("POST", "/api/v1/packs", `{"size": 250, "name": "Dup"}`)
+	if rr.Code != http.StatusConflict {
+		t.Errorf("Expected status %d for duplicate, got %d", http.StatusConflict, rr.Code)
+	}
+
+	if rr := do("POST", "/api/v1/packs", `{"size": 0}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for invalid pack, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	rr = do("PUT", "/api/v1/packs/"+created.ID,
+		`{"size": 500, "name": "Medium", "stock": 3, "weight_grams": 550, "height_mm": 80}`)
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	var updated dto.PackResponse
+	decode(rr, &updated)
+	if updated.Stock == nil || *updated.Stock != 3 {
+		t.Errorf("Expected stock 3, got %v", updated.Stock)
+	}
+	if updated.WeightGrams != 550 || updated.HeightMm != 80 {
+		t.Errorf("Expected 550g and 80mm, got %+v", updated)
+	}
+
+	rr = do("PUT", "/api/v1/packs/"+created.ID, `{"size": 500, "name": "Medium", "weight_grams": -1}`)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for negative weight, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	rr = do("PUT", "/api/v1/packs/"+created.ID, `{"size": 500, "name": "Medium", "stock": -1}`)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for negative stock, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	rr = do("PUT", "/api/v1/packs/"+created.ID,
+		`{"size": 500, "name": "Medium", "rule": {"required": true, "max_count": 2}}`)
+	var ruled dto.PackResponse
+	decode(rr, &ruled)
+	if ruled.Rule == nil || !ruled.Rule.Required || ruled.Rule.MaxCount == nil ||
+		*ruled.Rule.MaxCount != 2 {
+		t.Errorf("Expected a required pack of at most 2, got %+v", ruled.Rule)
+	}
+
+	rr = do("PUT", "/api/v1/packs/"+created.ID,
+		`{"size": 500, "name": "Medium", "rule": {"min_count": 3, "max_count": 2}}`)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a contradicting rule, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	if rr := do("GET", "/api/v1/packs/missing", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, rr.Code)
+	}
+
+	if rr := do("DELETE", "/api/v1/packs/"+created.ID, ""); rr.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	rr = do("GET", "/api/v1/packs/"+created.ID, "")
+	var fetched dto.PackResponse
+	decode(rr, &fetched)
+	if fetched.Active || fetched.Size != 500 {
+		t.Errorf("Expected inactive 500 pack, got %+v", fetched)
+	}
+
+	var active dto.PackListResponse
+	decode(do("GET", "/api/v1/packs?active=true", ""), &active)
+	if active.Total != 0 {
+		t.Errorf("Expected no active packs, got %d", active.Total)
+	}
+
+	var all dto.PackListResponse
+	decode(do("GET", "/api/v1/packs", ""), &all)
+	if all.Total != 1 {
+		t.Errorf("Expected 1 pack, got %d", all.Total)
+	}
+
+	if rr := do("GET", "/api/v1/packs?active=maybe", ""); rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for bad filter, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	// Sizes are unique per SKU
+	rr = do("POST", "/api/v1/packs", `{"sku": "WIDGET", "size": 500, "name": "Widget"}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d", http.StatusCreated, rr.Code)
+	}
+	var widget dto.PackResponse
+	decode(rr, &widget)
+	if widget.SKU != "WIDGET" {
+		t.Errorf("Expected sku WIDGET, got %q", widget.SKU)
+	}
+
+	var widgets dto.PackListResponse
+	decode(do("GET", "/api/v1/packs?sku=WIDGET", ""), &widgets)
+	if widgets.Total != 1 || widgets.Packs[0].ID != widget.ID {
+		t.Errorf("Expected only the widget pack, got %+v", widgets)
+	}
+
+	var defaults dto.PackListResponse
+	decode(do("GET", "/api/v1/packs?sku=", ""), &defaults)
+	if defaults.Total != 1 || defaults.Packs[0].ID != created.ID {
+		t.Errorf("Expected only the default catalog pack, got %+v", defaults)
+	}
+}
+
+func TestOrderHandler_Calculate(t *testing.T) {
+	repo := persistence.NewMemoryPackRepository()
+	packConfig := service.NewPackConfigService(repo)
+	handler := NewOrderHandler(service.NewOrderService(repo, service.NewPackService()))
+
+	calculate := func() *httptest.ResponseRecorder {
+		body := []byte(`{"order_quantity": 501}`)
+		req := httptest.NewRequest("POST", "/api/v1/orders/calculate", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+		handler.Calculate(rr, req)
+		return rr
+	}
+
+	if rr := calculate(); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status %d without packs, got %d", http.StatusUnprocessableEntity, rr.Code)
+	}
+
+	for _, size := range []int{250, 500} {
+		_, err := packConfig.Create(context.Background(), service.PackInput{Size: size, Name: "Pack"})
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	rr := calculate()
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var response struct {
+		Data dto.StoredPackCalculationResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Data.CalculationResponse == nil || response.Data.TotalItems != 750 {
+		t.Errorf("Unexpected calculation: %+v", response.Data.CalculationResponse)
+	}
+
+	if len(response.Data.Packs) != 2 || response.Data.Packs[0].PackID == "" {
+		t.Errorf("Expected both packs with IDs, got %+v", response.Data.Packs)
+	}
+}
+
+func TestOrderHandler_Plan(t *testing.T) {
+	repo := persistence.NewMemoryPackRepository()
+	packConfig := service.NewPackConfigService(repo)
+	handler := NewOrderHandler(service.NewOrderService(repo, service.NewPackService()))
+
+	for _, input := range []service.PackInput{
+		{SKU: "WIDGET", Size: 250, Name: "Widget"},
+		{SKU: "WIDGET", Size: 500, Name: "Widget"},
+		{SKU: "GADGET", Size: 100, Name: "Gadget"},
+	} {
+		if _, err := packConfig.Create(context.Background(), input); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name: "Two products",
+			body: `{"lines": [{"sku": "WIDGET", "quantity": 501},
+				{"sku": "GADGET", "quantity": 150}]}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "No lines",
+			body:           `{"lines": []}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "VALIDATION_FAILED",
+		},
+		{
+			name: "Duplicate SKU",
+			body: `{"lines": [{"sku": "WIDGET", "quantity": 1},
+				{"sku": "WIDGET", "quantity": 2}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "DUPLICATE_SKU",
+		},
+		{
+			name:           "Unknown SKU",
+			body:           `{"lines": [{"sku": "GIZMO", "quantity": 1}]}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "NO_VALID_PACKS",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/orders/plan", bytes.NewBufferString(tt.body))
+			rr := httptest.NewRecorder()
+			handler.Plan(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, rr.Code, rr.Body)
+			}
+
+			if tt.expectedCode != "" {
+				var errResponse apihttp.ErrorResponse
+				if err := json.Unmarshal(rr.Body.Bytes(), &errResponse); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if errResponse.Code != tt.expectedCode {
+					t.Errorf("Expected code %s, got %q", tt.expectedCode, errResponse.Code)
+				}
+				return
+			}
+
+			var response struct {
+				Data dto.OrderPlanResponse `json:"data"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+
+			summary := response.Data.Summary
+			if summary.LineCount != 2 || summary.OrderedItems != 651 || summary.TotalItems != 950 {
+				t.Errorf("Unexpected summary: %+v", summary)
+			}
+			lines := response.Data.Lines
+			if len(lines) != 2 || lines[0].SKU != "WIDGET" || lines[0].OrderQuantity != 501 ||
+				lines[0].TotalItems != 750 || lines[1].SKU != "GADGET" || lines[1].TotalItems != 200 {
+				t.Errorf("Unexpected lines: %+v", lines)
+			}
+			if len(lines[1].Packs) != 1 || lines[1].Packs[0].PackID == "" {
+				t.Errorf("Expected the gadget pack with its ID, got %+v", lines[1].Packs)
+			}
+		})
+	}
+}
+
+func TestShipmentHandler_Plan(t *testing.T) {
+	repo := persistence.NewMemoryCalculationRepository(0)
+	handler := NewShipmentHandler(
+		service.NewPackService(service.WithCalculationRepository(repo)),
+		service.NewCalculationHistoryService(repo),
+		service.NewShipmentPlanner(service.WithMaxShipments(5)),
+	)
+	router := apihttp.NewRouter()
+	router.RegisterShipmentRoutes(handler.Plan)
+
+	plan := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/shipments/plan", bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		router.Handler().ServeHTTP(rr, req)
+		return rr
+	}
+	decode := func(rr *httptest.ResponseRecorder) dto.ShipmentPlanResponse {
+		var response struct {
+			Data dto.ShipmentPlanResponse `json:"data"`
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		return response.Data
+	}
+
+	rr := plan(`{"pack_sizes": [250, 500, 1000], "order_quantity": 4001, "max_packs": 2}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	created := decode(rr)
+	if created.ShipmentCount != 3 || len(created.Shipments) != 3 {
+		t.Fatalf("Expected 3 shipments, got %+v", created)
+	}
+	calculationID := created.Calculation.ID
+	for i, shipment := range created.Shipments {
+		if shipment.CalculationID != calculationID || shipment.Sequence != i+1 {
+			t.Errorf("Shipment %d not linked to %s: %+v", i, calculationID, shipment)
+		}
+		if shipment.ID != fmt.Sprintf("%s-%d", calculationID, i+1) {
+			t.Errorf("Unexpected shipment ID %q", shipment.ID)
+		}
+	}
+
+	// Re-planning the recorded calculation with another capacity
+	rr = plan(fmt.Sprintf(`{"calculation_id": %q, "max_items": 2500}`, calculationID))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rr.Code, rr.Body.String())
+	}
+	replanned := decode(rr)
+	if replanned.Calculation.ID != calculationID || replanned.ShipmentCount != 2 {
+		t.Errorf("Expected 2 shipments for %s, got %+v", calculationID, replanned)
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "No capacity",
+			body:           `{"pack_sizes": [250], "order_quantity": 251}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "INVALID_SHIPMENT_CAPACITY",
+		},
+		{
+			name:           "Unknown calculation",
+			body:           `{"calculation_id": "missing", "max_packs": 2}`,
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "CALCULATION_NOT_FOUND",
+		},
+		{
+			name:           "Pack larger than a shipment",
+			body:           `{"pack_sizes": [1000], "order_quantity": 1, "max_items": 500}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "PACK_EXCEEDS_CAPACITY",
+		},
+		{
+			name:           "Too many shipments",
+			body:           `{"pack_sizes": [250], "order_quantity": 1500, "max_packs": 1}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "TOO_MANY_SHIPMENTS",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := plan(tt.body)
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, rr.Code)
+			}
+			var errResponse apihttp.ErrorResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &errResponse); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			if errResponse.Code != tt.expectedCode {
+				t.Errorf("Expected code %s, got %q", tt.expectedCode, errResponse.Code)
+			}
+		})
+	}
+}
+
+func TestPackSetHandler_Recommend(t *testing.T) {
+	repo := persistence.NewMemoryCalculationRepository(0)
+	packService := service.NewPackService(service.WithCalculationRepository(repo))
+	handler := NewPackSetHandler(packService, service.NewCalculationHistoryService(repo))
+
+	// Recorded demand: two orders of 250 and one of 500 for warehouse-7
+	ctx := service.ContextWithUserID(context.Background(), "warehouse-7")
+	for _, quantity := range []int{250, 250, 500} {
+		if _, err := packService.CalculateOptimal(ctx, []int{250, 500}, quantity); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if _, err := packService.CalculateOptimal(context.Background(), []int{250}, 12001); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name: "Demand histogram",
+			body: `{"max_pack_sizes": 1, "candidate_sizes": [500, 250],
+				"demand": [{"quantity": 250, "orders": 2}, {"quantity": 500, "orders": 1}]}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Demand CSV",
+			body: `{"max_pack_sizes": 1, "candidate_sizes": [500, 250],
+				"demand_csv": "quantity\n250\n500\n250\n"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Calculation history",
+			body: `{"max_pack_sizes": 1, "candidate_sizes": [500, 250],
+				"history": {"user_id": "warehouse-7"}}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Missing budget",
+			body:           `{"demand": [{"quantity": 250, "orders": 2}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "VALIDATION_FAILED",
+		},
+		{
+			name: "Two demand sources",
+			body: `{"max_pack_sizes": 1, "demand": [{"quantity": 250, "orders": 2}],
+				"demand_csv": "250"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "VALIDATION_FAILED",
+		},
+		{
+			name:           "Malformed CSV",
+			body:           `{"max_pack_sizes": 1, "demand_csv": "250,2\nmany,3"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "INVALID_DEMAND",
+		},
+		{
+			name:           "No recorded demand",
+			body:           `{"max_pack_sizes": 1, "history": {"user_id": "nobody"}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "EMPTY_DEMAND",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := bytes.NewBufferString(tt.body)
+			req := httptest.NewRequest("POST", "/api/v1/packsets/recommend", body)
+			rr := httptest.NewRecorder()
+			handler.Recommend(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, rr.Code, rr.Body)
+			}
+
+			if tt.expectedCode != "" {
+				var errResponse apihttp.ErrorResponse
+				if err := json.Unmarshal(rr.Body.Bytes(), &errResponse); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if errResponse.Code != tt.expectedCode {
+					t.Errorf("Expected code %s, got %q", tt.expectedCode, errResponse.Code)
+				}
+				return
+			}
+
+			var response struct {
+				Data dto.PackSizeRecommendationResponse `json:"data"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+
+			data := response.Data
+			if data.OrderCount != 3 || data.DistinctQuantities != 2 {
+				t.Errorf("Expected 3 orders of 2 quantities, got %+v", data)
+			}
+			if len(data.Recommendations) != 2 {
+				t.Fatalf("Expected both pack sets, got %+v", data.Recommendations)
+			}
+			best := data.Recommendations[0]
+			if best.Rank != 1 || len(best.PackSizes) != 1 || best.PackSizes[0] != 250 ||
+				best.ExpectedOverage != 0 {
+				t.Errorf("Expected 250 without overage first, got %+v", best)
+			}
+		})
+	}
+}
+
+func TestPackSetHandler_Table(t *testing.T) {
+	tables := service.NewSolutionTables(service.WithSolutionTableQuantity(1000))
+	packService := service.NewPackService(
+		service.WithSolutionTables(tables),
+		service.WithLimits(service.Limits{MaxPackSizes: 3}),
+	)
+	handler := NewPackSetHandler(packService, nil)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"Pack set", "?pack_sizes=250,500,1000", http.StatusOK, ""},
+		{"Spaces and duplicates", "?pack_sizes=500,%20250,250", http.StatusOK, ""},
+		{"Missing pack sizes", "", http.StatusBadRequest, "INVALID_QUERY_PARAMETER"},
+		{"Not a number", "?pack_sizes=250,many", http.StatusBadRequest, "INVALID_QUERY_PARAMETER"},
+		{"Zero size", "?pack_sizes=0,250", http.StatusBadRequest, "INVALID_QUERY_PARAMETER"},
+		{"Too many sizes", "?pack_sizes=1,2,3,4", http.StatusUnprocessableEntity,
+			"TOO_MANY_PACK_SIZES"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/packsets/table"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			handler.Table(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, rr.Code, rr.Body)
+			}
+
+			if tt.expectedCode != "" {
+				var errResponse apihttp.ErrorResponse
+				if err := json.Unmarshal(rr.Body.Bytes(), &errResponse); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if errResponse.Code != tt.expectedCode {
+					t.Errorf("Expected code %s, got %q", tt.expectedCode, errResponse.Code)
+				}
+				return
+			}
+
+			if ct := rr.Header().Get("Content-Type"); ct != "application/octet-stream" {
+				t.Errorf("Expected a binary response, got %q", ct)
+			}
+			table, err := service.ReadSolutionTable(rr.Body)
+			if err != nil {
+				t.Fatalf("Failed to load table: %v", err)
+			}
+			if table.Bound() != 1000 {
+				t.Errorf("Expected bound 1000, got %d", table.Bound())
+			}
+			distribution, ok := table.Lookup(263)
+			if !ok || distribution[500] != 1 || len(distribution) != 1 {
+				t.Errorf("Expected {500: 1} for 263, got %v", distribution)
+			}
+		})
+	}
+}
+
+func TestPackSetHandler_Analyze(t *testing.T) {
+	packService := service.NewPackService(service.WithLimits(service.Limits{MaxPackSizes: 3}))
+	handler := NewPackSetHandler(packService, nil)
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"Pack set", "?pack_sizes=6,9,20", http.StatusOK, ""},
+		{"Range", "?pack_sizes=6,9,20&from=1&to=40&step=1", http.StatusOK, ""},
+		{"Missing pack sizes", "?from=1", http.StatusBadRequest, "INVALID_QUERY_PARAMETER"},
+		{"Not a number", "?pack_sizes=6,9,20&to=many", http.StatusBadRequest,
+			"INVALID_QUERY_PARAMETER"},
+		{"Zero step", "?pack_sizes=6,9,20&step=0", http.StatusBadRequest, "INVALID_QUERY_PARAMETER"},
+		{"Backwards range", "?pack_sizes=6,9,20&from=40&to=1", http.StatusBadRequest,
+			"INVALID_QUANTITY_RANGE"},
+		{"Too many points", "?pack_sizes=6,9,20&to=1000000&step=1", http.StatusUnprocessableEntity,
+			"TOO_MANY_CURVE_POINTS"},
+		{"Too many sizes", "?pack_sizes=1,2,3,4", http.StatusUnprocessableEntity,
+			"TOO_MANY_PACK_SIZES"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/packsets/analyze"+tt.query, nil)
+			rr := httptest.NewRecorder()
+			handler.Analyze(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, rr.Code, rr.Body)
+			}
+
+			if tt.expectedCode != "" {
+				var errResponse apihttp.ErrorResponse
+				if err := json.Unmarshal(rr.Body.Bytes(), &errResponse); err != nil {
+					t.Fatalf("Failed to unmarshal response: %v", err)
+				}
+				if errResponse.Code != tt.expectedCode {
+					t.Errorf("Expected code %s, got %q", tt.expectedCode, errResponse.Code)
+				}
+				return
+			}
+
+			var response struct {
+				Data dto.PackSetAnalysisResponse `json:"data"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to unmarshal response: %v", err)
+			}
+			analysis := response.Data
+			if analysis.GCD != 1 || analysis.FrobeniusNumber != 43 ||
+				len(analysis.RedundantSizes) != 0 {
+				t.Errorf("Expected GCD 1 and Frobenius number 43, got %+v", analysis)
+			}
+			// The default range also runs from 1 to twice the largest size
+			curve := analysis.OverageCurve
+			if len(curve) != 40 || curve[len(curve)-1].OrderQuantity != 40 ||
+				analysis.MaxOverage != 5 {
+				t.Errorf("Expected 40 points with up to 5 items over, got %+v", analysis)
+			}
+		})
+	}
+}
+
+func TestHistoryHandler(t *testing.T) {
+	repo := persistence.NewMemoryCalculationRepository(0)
+	calculationHandler := NewCalculationHandler(
+		service.NewPackService(service.WithCalculationRepository(repo)),
+	)
+	historyHandler := NewHistoryHandler(service.NewCalculationHistoryService(repo))
+
+	router := apihttp.NewRouter()
+	router.RegisterHistoryRoutes(historyHandler.List, historyHandler.Get)
+
+	var created dto.CalculationResponse
+	for _, quantity := range []int{263, 12001} {
+		body := []byte(fmt.Sprintf(`{"pack_sizes": [250, 500, 1000], "order_quantity": %d}`, quantity))
+		req := httptest.NewRequest("POST", "/api/v1/calculate", bytes.NewBuffer(body))
+		req.Header.Set("X-User-ID", "support")
+		rr := httptest.NewRecorder()
+		calculationHandler.Calculate(rr, req)
+
+		var response struct {
+			Data dto.CalculationResponse `json:"data"`
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal response: %v", err)
+		}
+		created = response.Data
+	}
+
+	rr := httptest.NewRecorder()
+	router.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/calculations/"+created.ID, nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var fetched struct {
+		Data dto.CalculationRecordResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &fetched); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if fetched.Data.OrderQuantity != 12001 || fetched.Data.UserID != "support" {
+		t.Errorf("Unexpected calculation: %+v", fetched.Data)
+	}
+
+	rr = httptest.NewRecorder()
+	router.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/calculations/missing", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	path := "/api/v1/calculations?min_quantity=1000&user_id=support&limit=10"
+	router.Handler().ServeHTTP(rr, httptest.NewRequest("GET", path, nil))
+
+	var list struct {
+		Data dto.CalculationListResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if list.Data.Count != 1 || list.Data.Calculations[0].ID != created.ID {
+		t.Errorf("Unexpected list: %+v", list.Data)
+	}
+
+	rr = httptest.NewRecorder()
+	router.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/api/v1/calculations?limit=ten", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestHealthHandler_Health(t *testing.T) {
+	handler := NewHealthHandler()
+
+	req := httptest.NewRequest("GET", "/health", nil)
+	rr := httptest.NewRecorder()
+
+	handler.Health(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var response dto.HealthResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Status != "healthy" {
+		t.Errorf("Expected status 'healthy', got '%s'", response.Status)
+	}
+}
+
+func TestHealthHandler_Ready(t *testing.T) {
+	handler := NewHealthHandler()
+
+	req := httptest.NewRequest("GET", "/ready", nil)
+	rr := httptest.NewRecorder()
+
+	handler.Ready(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var response dto.HealthResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Status != "ready" {
+		t.Errorf("Expected status 'ready', got '%s'", response.Status)
+	}
+}
+
+func TestHealthHandler_ReadyCheckFails(t *testing.T) {
+	handler := NewHealthHandler()
+	handler.AddReadinessCheck("database", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+
+	req := httptest.NewRequest("GET", "/ready", nil)
+	rr := httptest.NewRecorder()
+
+	handler.Ready(rr, req)
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+}
+
+// decodeError unmarshals an error response
+func decodeError(t *testing.T, rr *httptest.ResponseRecorder) apihttp.ErrorResponse {
+	t.Helper()
+	var response apihttp.ErrorResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
 	}
+	return response
 }
@@ -1,19 +1,36 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 	"time"
 
 	"pack-calculator/internal/api/dto"
 	apihttp "pack-calculator/internal/api/http"
+	"pack-calculator/internal/infrastructure/logger"
 )
 
+// readinessTimeout bounds how long the readiness checks may take together
+const readinessTimeout = 2 * time.Second
+
+// ReadinessCheck reports whether a dependency is able to serve requests
+type ReadinessCheck func(ctx context.Context) error
+
 // HealthHandler handles health check requests
-type HealthHandler struct{}
+type HealthHandler struct {
+	readinessChecks map[string]ReadinessCheck
+}
 
 // NewHealthHandler creates a new health handler
 func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{}
+	return &HealthHandler{
+		readinessChecks: make(map[string]ReadinessCheck),
+	}
+}
+
+// AddReadinessCheck registers a dependency check consulted by Ready
+func (h *HealthHandler) AddReadinessCheck(name string, check ReadinessCheck) {
+	h.readinessChecks[name] = check
 }
 
 // Health handles GET /health
@@ -29,8 +46,24 @@ func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
 
 // Ready handles GET /ready
 func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
-	// TODO: Add database connectivity check
-	// TODO: Add dependency health checks
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	for name, check := range h.readinessChecks {
+		if err := check(ctx); err != nil {
+			logger.Warn("Readiness check failed", map[string]interface{}{
+				"check": name,
+				"error": err.Error(),
+			})
+
+			response := &dto.HealthResponse{
+				Status: "not ready",
+				Time:   time.Now().UTC().Format(time.RFC3339),
+			}
+			apihttp.WriteJSONResponse(w, http.StatusServiceUnavailable, response)
+			return
+		}
+	}
 
 	response := &dto.HealthResponse{
 		Status: "ready",
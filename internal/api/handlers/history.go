@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"pack-calculator/internal/api/dto"
+	apihttp "pack-calculator/internal/api/http"
+	"pack-calculator/internal/domain/repository"
+	"pack-calculator/internal/domain/service"
+	"pack-calculator/internal/infrastructure/logger"
+)
+
+// HistoryHandler handles calculation history requests
+type HistoryHandler struct {
+	historyService *service.CalculationHistoryService
+}
+
+// NewHistoryHandler creates a new history handler
+func NewHistoryHandler(historyService *service.CalculationHistoryService) *HistoryHandler {
+	return &HistoryHandler{
+		historyService: historyService,
+	}
+}
+
+// Get handles GET /api/v1/calculations/{id}
+func (h *HistoryHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	calculation, err := h.historyService.Get(r.Context(), id)
+	if err != nil {
+		logger.Warn("Get calculation failed", map[string]interface{}{
+			"request_id":     r.Header.Get("X-Request-ID"),
+			"calculation_id": id,
+			"error":          err.Error(),
+		})
+		apihttp.WriteError(w, r, err)
+		return
+	}
+
+	apihttp.WriteSuccessResponse(w, http.StatusOK, dto.ToCalculationRecordResponse(calculation))
+}
+
+// List handles GET /api/v1/calculations
+func (h *HistoryHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseCalculationFilter(r.URL.Query())
+	if err != nil {
+		apihttp.WriteError(w, r, err)
+		return
+	}
+
+	page, err := h.historyService.List(r.Context(), filter)
+	if err != nil {
+		logger.Warn("List calculations failed", map[string]interface{}{
+			"request_id": r.Header.Get("X-Request-ID"),
+			"error":      err.Error(),
+		})
+		apihttp.WriteError(w, r, err)
+		return
+	}
+
+	apihttp.WriteSuccessResponse(w, http.StatusOK, dto.ToCalculationListResponse(page))
+}
+
+// parseCalculationFilter reads the history filters from the query string
+func parseCalculationFilter(query url.Values) (repository.CalculationFilter, error) {
+	filter := repository.CalculationFilter{
+		UserID: query.Get("user_id"),
+		Cursor: query.Get("cursor"),
+	}
+
+	ints := []struct {
+		name   string
+		target *int
+	}{
+		{"min_quantity", &filter.MinOrderQuantity},
+		{"max_quantity", &filter.MaxOrderQuantity},
+		{"min_overage", &filter.MinOverage},
+		{"limit", &filter.Limit},
+	}
+	for _, param := range ints {
+		raw := query.Get(param.name)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 0 {
+			return filter, fmt.Errorf("%w: %s=%q", apihttp.ErrInvalidQuery, param.name, raw)
+		}
+		*param.target = value
+	}
+
+	times := []struct {
+		name   string
+		target *time.Time
+	}{
+		{"created_after", &filter.CreatedAfter},
+		{"created_before", &filter.CreatedBefore},
+	}
+	for _, param := range times {
+		raw := query.Get(param.name)
+		if raw == "" {
+			continue
+		}
+		value, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return filter, fmt.Errorf("%w: %s=%q", apihttp.ErrInvalidQuery, param.name, raw)
+		}
+		*param.target = value
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+
+	"pack-calculator/internal/api/dto"
+	apihttp "pack-calculator/internal/api/http"
+	"pack-calculator/internal/domain/service"
+	"pack-calculator/internal/infrastructure/logger"
+)
+
+// OrderHandler handles calculations against the stored pack configuration
+type OrderHandler struct {
+	orderService *service.OrderService
+	validator    *validator.Validate
+}
+
+// NewOrderHandler creates a new order handler
+func NewOrderHandler(orderService *service.OrderService) *OrderHandler {
+	return &OrderHandler{
+		orderService: orderService,
+		validator:    newValidator(),
+	}
+}
+
+// Calculate handles POST /api/v1/orders/calculate
+func (h *OrderHandler) Calculate(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	requestID := r.Header.Get("X-Request-ID")
+
+	var req dto.SimpleCalculationRequest
+
+	// Parse JSON request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("Invalid JSON request", map[string]interface{}{
+			"request_id": requestID,
+			"error":      err.Error(),
+		})
+		apihttp.WriteError(w, r, apihttp.ErrInvalidJSON)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		logger.Warn("Request validation failed", map[string]interface{}{
+			"request_id": requestID,
+			"error":      err.Error(),
+		})
+		apihttp.WriteValidationError(w, r, err)
+		return
+	}
+
+	// Perform calculation
+	result, err := h.orderService.Calculate(requestContext(r), req.OrderQuantity, service.OrderOptions{
+		Objective:    req.Objective,
+		ItemCost:     req.ItemCost,
+		Alternatives: req.Alternatives,
+		Caps:         req.Caps.ToModel(),
+		Tolerance:    req.Tolerance.ToModel(),
+	})
+	if err != nil {
+		logger.Error("Order calculation failed", map[string]interface{}{
+			"request_id":     requestID,
+			"order_quantity": req.OrderQuantity,
+			"error":          err.Error(),
+		})
+		apihttp.WriteError(w, r, err)
+		return
+	}
+
+	logger.Info("Order calculation completed", map[string]interface{}{
+		"request_id":     requestID,
+		"order_quantity": req.OrderQuantity,
+		"total_items":    result.Calculation.TotalItems,
+		"total_packs":    result.Calculation.TotalPacks,
+		"duration_ms":    time.Since(start).Milliseconds(),
+		"calculation_id": result.Calculation.ID,
+	})
+
+	response := dto.ToStoredPackCalculationResponse(result.Calculation, result.Packs)
+	apihttp.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+// Plan handles POST /api/v1/orders/plan
+func (h *OrderHandler) Plan(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	requestID := r.Header.Get("X-Request-ID")
+
+	var req dto.OrderPlanRequest
+
+	// Parse JSON request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("Invalid JSON request", map[string]interface{}{
+			"request_id": requestID,
+			"error":      err.Error(),
+		})
+		apihttp.WriteError(w, r, apihttp.ErrInvalidJSON)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		logger.Warn("Request validation failed", map[string]interface{}{
+			"request_id": requestID,
+			"error":      err.Error(),
+		})
+		apihttp.WriteValidationError(w, r, err)
+		return
+	}
+
+	// Perform calculation
+	plan, err := h.orderService.Plan(requestContext(r), req.ToModel())
+	if err != nil {
+		logger.Error("Order plan failed", map[string]interface{}{
+			"request_id": requestID,
+			"lines":      len(req.Lines),
+			"error":      err.Error(),
+		})
+		apihttp.WriteError(w, r, err)
+		return
+	}
+
+	logger.Info("Order plan completed", map[string]interface{}{
+		"request_id":    requestID,
+		"lines":         len(plan.Lines),
+		"total_items":   plan.TotalItems,
+		"total_packs":   plan.TotalPacks,
+		"items_overage": plan.ItemsOverage,
+		"duration_ms":   time.Since(start).Milliseconds(),
+	})
+
+	apihttp.WriteSuccessResponse(w, http.StatusOK, dto.ToOrderPlanResponse(plan))
+}
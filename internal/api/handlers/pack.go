@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+
+	"pack-calculator/internal/api/dto"
+	apihttp "pack-calculator/internal/api/http"
+	"pack-calculator/internal/domain/repository"
+	"pack-calculator/internal/domain/service"
+	"pack-calculator/internal/infrastructure/logger"
+)
+
+// PackHandler handles pack configuration HTTP requests
+type PackHandler struct {
+	packConfigService *service.PackConfigService
+	validator         *validator.Validate
+}
+
+// NewPackHandler creates a new pack handler
+func NewPackHandler(packConfigService *service.PackConfigService) *PackHandler {
+	return &PackHandler{
+		packConfigService: packConfigService,
+		validator:         newValidator(),
+	}
+}
+
+// List handles GET /api/v1/packs
+func (h *PackHandler) List(w http.ResponseWriter, r *http.Request) {
+	var filter repository.PackFilter
+	query := r.URL.Query()
+	if raw := query.Get("active"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			apihttp.WriteError(w, r, fmt.Errorf("%w: active=%q", apihttp.ErrInvalidQuery, raw))
+			return
+		}
+		filter.ActiveOnly = parsed
+	}
+	if query.Has("sku") {
+		sku := query.Get("sku")
+		filter.SKU = &sku
+	}
+
+	packs, err := h.packConfigService.List(r.Context(), filter)
+	if err != nil {
+		h.logFailure(r, "List packs failed", err)
+		apihttp.WriteError(w, r, err)
+		return
+	}
+
+	apihttp.WriteSuccessResponse(w, http.StatusOK, dto.ToPackListResponse(packs))
+}
+
+// Get handles GET /api/v1/packs/{id}
+func (h *PackHandler) Get(w http.ResponseWriter, r *http.Request) {
+	pack, err := h.packConfigService.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.logFailure(r, "Get pack failed", err)
+		apihttp.WriteError(w, r, err)
+		return
+	}
+
+	apihttp.WriteSuccessResponse(w, http.StatusOK, dto.ToPackResponse(pack))
+}
+
+// Create handles POST /api/v1/packs
+func (h *PackHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreatePackRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	pack, err := h.packConfigService.Create(r.Context(), service.PackInput{
+		Size:         req.Size,
+		Name:         req.Name,
+		SKU:          req.SKU,
+		Stock:        req.Stock,
+		HandlingCost: req.HandlingCost,
+		Measurements: req.ToModel(),
+		Packaging:    req.Packaging.ToModel(),
+		Rule:         req.Rule.ToModel(),
+	})
+	if err != nil {
+		h.logFailure(r, "Create pack failed", err)
+		apihttp.WriteError(w, r, err)
+		return
+	}
+
+	apihttp.WriteSuccessResponse(w, http.StatusCreated, dto.ToPackResponse(pack))
+}
+
+// Update handles PUT /api/v1/packs/{id}
+func (h *PackHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var req dto.UpdatePackRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	pack, err := h.packConfigService.Update(r.Context(), mux.Vars(r)["id"], service.PackInput{
+		Size:         req.Size,
+		Name:         req.Name,
+		SKU:          req.SKU,
+		Stock:        req.Stock,
+		HandlingCost: req.HandlingCost,
+		Measurements: req.ToModel(),
+		Packaging:    req.Packaging.ToModel(),
+		Rule:         req.Rule.ToModel(),
+	})
+	if err != nil {
+		h.logFailure(r, "Update pack failed", err)
+		apihttp.WriteError(w, r, err)
+		return
+	}
+
+	apihttp.WriteSuccessResponse(w, http.StatusOK, dto.ToPackResponse(pack))
+}
+
+// Delete handles DELETE /api/v1/packs/{id} by deactivating the pack
+func (h *PackHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	pack, err := h.packConfigService.Delete(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.logFailure(r, "Delete pack failed", err)
+		apihttp.WriteError(w, r, err)
+		return
+	}
+
+	apihttp.WriteSuccessResponse(w, http.StatusOK, dto.ToPackResponse(pack))
+}
+
+// decode parses and validates a JSON request body, writing the error
+// response itself when either step fails
+func (h *PackHandler) decode(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	requestID := r.Header.Get("X-Request-ID")
+
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		logger.Warn("Invalid JSON request", map[string]interface{}{
+			"request_id": requestID,
+			"error":      err.Error(),
+		})
+		apihttp.WriteError(w, r, apihttp.ErrInvalidJSON)
+		return false
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		logger.Warn("Request validation failed", map[string]interface{}{
+			"request_id": requestID,
+			"error":      err.Error(),
+		})
+		apihttp.WriteValidationError(w, r, err)
+		return false
+	}
+
+	return true
+}
+
+// logFailure logs a failed pack operation
+func (h *PackHandler) logFailure(r *http.Request, message string, err error) {
+	logger.Warn(message, map[string]interface{}{
+		"request_id": r.Header.Get("X-Request-ID"),
+		"pack_id":    mux.Vars(r)["id"],
+		"error":      err.Error(),
+	})
+}
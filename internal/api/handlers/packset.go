@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+
+	"pack-calculator/internal/api/dto"
+	apihttp "pack-calculator/internal/api/http"
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/domain/service"
+	"pack-calculator/internal/infrastructure/logger"
+)
+
+// PackSetHandler handles analysis of pack size sets
+type PackSetHandler struct {
+	packService    *service.PackService
+	historyService *service.CalculationHistoryService
+	validator      *validator.Validate
+}
+
+// NewPackSetHandler creates a new pack set handler
+func NewPackSetHandler(
+	packService *service.PackService,
+	historyService *service.CalculationHistoryService,
+) *PackSetHandler {
+	return &PackSetHandler{
+		packService:    packService,
+		historyService: historyService,
+		validator:      newValidator(),
+	}
+}
+
+// Recommend handles POST /api/v1/packsets/recommend
+func (h *PackSetHandler) Recommend(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	requestID := r.Header.Get("X-Request-ID")
+
+	var req dto.PackSizeRecommendationRequest
+
+	// Parse JSON request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("Invalid JSON request", map[string]interface{}{
+			"request_id": requestID,
+			"error":      err.Error(),
+		})
+		apihttp.WriteError(w, r, apihttp.ErrInvalidJSON)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		logger.Warn("Request validation failed", map[string]interface{}{
+			"request_id": requestID,
+			"error":      err.Error(),
+		})
+		apihttp.WriteValidationError(w, r, err)
+		return
+	}
+
+	// Collect the demand from the request or the calculation history
+	ctx := requestContext(r)
+	var (
+		demand model.DemandHistogram
+		source string
+		err    error
+	)
+	switch {
+	case len(req.Demand) > 0:
+		demand, source = req.DemandHistogram(), "request"
+	case req.DemandCSV != "":
+		demand, err = model.ParseDemandCSV(strings.NewReader(req.DemandCSV))
+		source = "csv"
+	default:
+		demand, err = h.historyService.Demand(ctx, req.HistoryFilter())
+		source = "history"
+	}
+
+	var scores []model.PackSetScore
+	if err == nil {
+		scores, err = h.packService.RecommendPackSizes(ctx, demand, service.RecommendOptions{
+			MaxPackSizes: req.MaxPackSizes,
+			Candidates:   req.CandidateSizes,
+			Top:          req.Top,
+		})
+	}
+	if err != nil {
+		logger.Error("Pack size recommendation failed", map[string]interface{}{
+			"request_id":     requestID,
+			"demand_source":  source,
+			"max_pack_sizes": req.MaxPackSizes,
+			"error":          err.Error(),
+		})
+		apihttp.WriteError(w, r, err)
+		return
+	}
+
+	logger.Info("Pack size recommendation completed", map[string]interface{}{
+		"request_id":     requestID,
+		"demand_source":  source,
+		"orders":         demand.TotalOrders(),
+		"max_pack_sizes": req.MaxPackSizes,
+		"best":           scores[0].PackSizes,
+		"duration_ms":    time.Since(start).Milliseconds(),
+	})
+
+	response := dto.ToPackSizeRecommendationResponse(demand, scores)
+	apihttp.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+// Table handles GET /api/v1/packsets/table. The solution table of the
+// pack_sizes query parameter is returned as a binary attachment that
+// service.ReadSolutionTable loads.
+func (h *PackSetHandler) Table(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	requestID := r.Header.Get("X-Request-ID")
+
+	packSizes, err := parsePackSizesQuery(r.URL.Query())
+	if err != nil {
+		apihttp.WriteError(w, r, err)
+		return
+	}
+
+	table, err := h.packService.SolutionTable(requestContext(r), packSizes)
+	var data []byte
+	if err == nil {
+		data, err = table.MarshalBinary()
+	}
+	if err != nil {
+		logger.Error("Solution table export failed", map[string]interface{}{
+			"request_id": requestID,
+			"pack_sizes": packSizes,
+			"error":      err.Error(),
+		})
+		apihttp.WriteError(w, r, err)
+		return
+	}
+
+	logger.Info("Solution table exported", map[string]interface{}{
+		"request_id":  requestID,
+		"pack_sizes":  table.PackSizes(),
+		"bound":       table.Bound(),
+		"bytes":       len(data),
+		"duration_ms": time.Since(start).Milliseconds(),
+	})
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="solution-table.pkst"`)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// Analyze handles GET /api/v1/packsets/analyze. The pack_sizes query
+// parameter names the set; from, to and step optionally pick the order
+// quantities of the overage curve.
+func (h *PackSetHandler) Analyze(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	requestID := r.Header.Get("X-Request-ID")
+
+	query := r.URL.Query()
+	packSizes, err := parsePackSizesQuery(query)
+	var opts service.AnalyzeOptions
+	if err == nil {
+		opts, err = parseAnalyzeOptions(query)
+	}
+	if err != nil {
+		apihttp.WriteError(w, r, err)
+		return
+	}
+
+	analysis, err := h.packService.AnalyzePackSet(requestContext(r), packSizes, opts)
+	if err != nil {
+		logger.Error("Pack set analysis failed", map[string]interface{}{
+			"request_id": requestID,
+			"pack_sizes": packSizes,
+			"error":      err.Error(),
+		})
+		apihttp.WriteError(w, r, err)
+		return
+	}
+
+	logger.Info("Pack set analysis completed", map[string]interface{}{
+		"request_id":  requestID,
+		"pack_sizes":  analysis.PackSizes,
+		"gcd":         analysis.GCD,
+		"frobenius":   analysis.FrobeniusNumber,
+		"points":      len(analysis.OverageCurve),
+		"duration_ms": time.Since(start).Milliseconds(),
+	})
+
+	apihttp.WriteSuccessResponse(w, http.StatusOK, dto.ToPackSetAnalysisResponse(analysis))
+}
+
+// parsePackSizesQuery reads the comma-separated pack_sizes query parameter
+func parsePackSizesQuery(query url.Values) ([]int, error) {
+	raw := query.Get("pack_sizes")
+	if raw == "" {
+		return nil, fmt.Errorf("%w: pack_sizes is required", apihttp.ErrInvalidQuery)
+	}
+
+	fields := strings.Split(raw, ",")
+	packSizes := make([]int, len(fields))
+	for i, field := range fields {
+		size, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("%w: pack_sizes=%q", apihttp.ErrInvalidQuery, raw)
+		}
+		packSizes[i] = size
+	}
+	return packSizes, nil
+}
+
+// parseAnalyzeOptions reads the from, to and step query parameters of the
+// overage curve
+func parseAnalyzeOptions(query url.Values) (service.AnalyzeOptions, error) {
+	var opts service.AnalyzeOptions
+	ints := []struct {
+		name   string
+		target *int
+	}{
+		{"from", &opts.From},
+		{"to", &opts.To},
+		{"step", &opts.Step},
+	}
+	for _, param := range ints {
+		raw := query.Get(param.name)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil || value <= 0 {
+			return opts, fmt.Errorf("%w: %s=%q", apihttp.ErrInvalidQuery, param.name, raw)
+		}
+		*param.target = value
+	}
+	return opts, nil
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+
+	"pack-calculator/internal/api/dto"
+	apihttp "pack-calculator/internal/api/http"
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/domain/service"
+	"pack-calculator/internal/infrastructure/logger"
+)
+
+// ShipmentHandler handles splitting orders into shipments
+type ShipmentHandler struct {
+	packService    *service.PackService
+	historyService *service.CalculationHistoryService
+	planner        *service.ShipmentPlanner
+	validator      *validator.Validate
+}
+
+// NewShipmentHandler creates a new shipment handler
+func NewShipmentHandler(
+	packService *service.PackService,
+	historyService *service.CalculationHistoryService,
+	planner *service.ShipmentPlanner,
+) *ShipmentHandler {
+	return &ShipmentHandler{
+		packService:    packService,
+		historyService: historyService,
+		planner:        planner,
+		validator:      newValidator(),
+	}
+}
+
+// Plan handles POST /api/v1/shipments/plan
+func (h *ShipmentHandler) Plan(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	requestID := r.Header.Get("X-Request-ID")
+
+	var req dto.ShipmentPlanRequest
+
+	// Parse JSON request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		logger.Warn("Invalid JSON request", map[string]interface{}{
+			"request_id": requestID,
+			"error":      err.Error(),
+		})
+		apihttp.WriteError(w, r, apihttp.ErrInvalidJSON)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		logger.Warn("Request validation failed", map[string]interface{}{
+			"request_id": requestID,
+			"error":      err.Error(),
+		})
+		apihttp.WriteValidationError(w, r, err)
+		return
+	}
+	capacity := req.Capacity()
+	if err := capacity.Validate(); err != nil {
+		logger.Warn("Invalid shipment capacity", map[string]interface{}{
+			"request_id": requestID,
+			"error":      err.Error(),
+		})
+		apihttp.WriteError(w, r, err)
+		return
+	}
+
+	// Look up or perform the calculation to split
+	ctx := requestContext(r)
+	var (
+		calculation *model.Calculation
+		err         error
+	)
+	if req.CalculationID != "" {
+		calculation, err = h.historyService.Get(ctx, req.CalculationID)
+	} else {
+		calculation, err = h.packService.CalculateOptimal(ctx, req.PackSizes, req.OrderQuantity)
+	}
+	if err != nil {
+		logger.Error("Shipment plan calculation failed", map[string]interface{}{
+			"request_id":     requestID,
+			"calculation_id": req.CalculationID,
+			"order_quantity": req.OrderQuantity,
+			"error":          err.Error(),
+		})
+		apihttp.WriteError(w, r, err)
+		return
+	}
+
+	shipments, err := h.planner.Plan(ctx, calculation.ID, calculation.GetDistribution(), capacity)
+	if err != nil {
+		logger.Error("Shipment planning failed", map[string]interface{}{
+			"request_id":     requestID,
+			"calculation_id": calculation.ID,
+			"max_packs":      capacity.MaxPacks,
+			"max_items":      capacity.MaxItems,
+			"error":          err.Error(),
+		})
+		apihttp.WriteError(w, r, err)
+		return
+	}
+
+	logger.Info("Shipment planning completed", map[string]interface{}{
+		"request_id":     requestID,
+		"calculation_id": calculation.ID,
+		"total_packs":    calculation.TotalPacks,
+		"shipments":      len(shipments),
+		"duration_ms":    time.Since(start).Milliseconds(),
+	})
+
+	response := dto.ToShipmentPlanResponse(calculation, shipments)
+	apihttp.WriteSuccessResponse(w, http.StatusOK, response)
+}
@@ -0,0 +1,401 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"pack-calculator/internal/domain/model"
+)
+
+// Error codes that do not originate from a domain error
+const (
+	CodeInvalidJSON      = "INVALID_JSON"
+	CodeInvalidQuery     = "INVALID_QUERY_PARAMETER"
+	CodeValidationFailed = "VALIDATION_FAILED"
+	CodeInternalError    = "INTERNAL_ERROR"
+)
+
+// Request errors that do not originate from the domain
+var (
+	ErrInvalidJSON  = errors.New("invalid JSON format")
+	ErrInvalidQuery = errors.New("invalid query parameter")
+)
+
+// ErrorMapping describes how an error is presented over HTTP
+type ErrorMapping struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+// FieldError describes a single failed validation rule
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// errorMappings is checked in order with errors.Is, so wrapped domain
+// errors resolve to the same mapping as the sentinel itself
+var errorMappings = []struct {
+	err     error
+	mapping ErrorMapping
+}{
+	// Request errors
+	{ErrInvalidJSON, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    CodeInvalidJSON,
+		Message: "Invalid JSON format",
+	}},
+	{ErrInvalidQuery, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    CodeInvalidQuery,
+		Message: "Invalid query parameter",
+	}},
+
+	// Pack validation errors
+	{model.ErrInvalidPackSize, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    "INVALID_PACK_SIZE",
+		Message: "Pack size must be greater than zero",
+	}},
+	{model.ErrInvalidPackName, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    "INVALID_PACK_NAME",
+		Message: "Pack name cannot be empty",
+	}},
+	{model.ErrPackNotFound, ErrorMapping{
+		Status:  http.StatusNotFound,
+		Code:    "PACK_NOT_FOUND",
+		Message: "Pack not found",
+	}},
+	{model.ErrPackAlreadyExists, ErrorMapping{
+		Status:  http.StatusConflict,
+		Code:    "PACK_ALREADY_EXISTS",
+		Message: "Pack already exists",
+	}},
+	{model.ErrInvalidPackStock, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    "INVALID_PACK_STOCK",
+		Message: "Pack stock cannot be negative",
+	}},
+
+	// Calculation errors
+	{model.ErrInvalidOrderQuantity, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    "INVALID_ORDER_QUANTITY",
+		Message: "Order quantity must be greater than zero",
+	}},
+	{model.ErrEmptyPackSizes, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    "EMPTY_PACK_SIZES",
+		Message: "Pack sizes cannot be empty",
+	}},
+	{model.ErrCalculationFailed, ErrorMapping{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "CALCULATION_FAILED",
+		Message: "Unable to calculate pack distribution",
+	}},
+	{model.ErrCalculationCanceled, ErrorMapping{
+		Status:  StatusClientClosedRequest,
+		Code:    "CALCULATION_CANCELED",
+		Message: "Calculation was canceled",
+	}},
+	{model.ErrCalculationTimeout, ErrorMapping{
+		Status:  http.StatusRequestTimeout,
+		Code:    "CALCULATION_TIMEOUT",
+		Message: "Calculation exceeded the time limit",
+	}},
+	{model.ErrCalculationNotFound, ErrorMapping{
+		Status:  http.StatusNotFound,
+		Code:    "CALCULATION_NOT_FOUND",
+		Message: "Calculation not found",
+	}},
+	{model.ErrInvalidCursor, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    "INVALID_CURSOR",
+		Message: "Invalid pagination cursor",
+	}},
+	{model.ErrUnknownObjective, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    "INVALID_OBJECTIVE",
+		Message: "Objective must be lexicographic, min_cost or min_packs",
+	}},
+	{model.ErrInvalidCost, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    "INVALID_COST",
+		Message: "Costs cannot be negative",
+	}},
+	{model.ErrInvalidMeasurements, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    "INVALID_MEASUREMENTS",
+		Message: "Pack weight and dimensions cannot be negative",
+	}},
+	{model.ErrInvalidShipmentCaps, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    "INVALID_SHIPMENT_CAPS",
+		Message: "Shipment caps cannot be negative",
+	}},
+	{model.ErrInvalidPackaging, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    "INVALID_PACKAGING",
+		Message: "Packaging levels need a name and a positive quantity",
+	}},
+	{model.ErrEmptyOrder, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    "EMPTY_ORDER",
+		Message: "Order has no line items",
+	}},
+	{model.ErrInvalidSKU, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    "INVALID_SKU",
+		Message: "Order line SKU cannot be empty",
+	}},
+	{model.ErrDuplicateSKU, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    "DUPLICATE_SKU",
+		Message: "Order lists a SKU more than once",
+	}},
+	{model.ErrEmptyDemand, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    "EMPTY_DEMAND",
+		Message: "Demand histogram has no orders",
+	}},
+	{model.ErrInvalidDemand, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    "INVALID_DEMAND",
+		Message: "Demand quantities and order counts must be positive",
+	}},
+	{model.ErrInvalidPackSizeBudget, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    "INVALID_PACK_SIZE_BUDGET",
+		Message: "Pack size budget must be greater than zero",
+	}},
+	{model.ErrInvalidTolerance, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    "INVALID_TOLERANCE",
+		Message: "Tolerance caps cannot be negative or mix units",
+	}},
+	{model.ErrInvalidPackRule, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    "INVALID_PACK_RULE",
+		Message: "Pack rule counts cannot be negative or contradict each other",
+	}},
+	{model.ErrInvalidQuantityRange, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    "INVALID_QUANTITY_RANGE",
+		Message: "Quantity range must be positive and ascending",
+	}},
+	{model.ErrInvalidSolutionTable, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    "INVALID_SOLUTION_TABLE",
+		Message: "Solution table is corrupt or unsupported",
+	}},
+
+	// Business rule errors
+	{model.ErrNoValidPacks, ErrorMapping{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "NO_VALID_PACKS",
+		Message: "No valid pack configurations available",
+	}},
+	{model.ErrPackRulesNotMet, ErrorMapping{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "PACK_RULES_NOT_MET",
+		Message: "No pack combination satisfies the pack rules",
+	}},
+	{model.ErrOrderTooLarge, ErrorMapping{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "ORDER_TOO_LARGE",
+		Message: "Order quantity exceeds the maximum limit",
+	}},
+	{model.ErrTooManyPackSizes, ErrorMapping{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "TOO_MANY_PACK_SIZES",
+		Message: "Too many pack sizes",
+	}},
+	{model.ErrPackSizeTooLarge, ErrorMapping{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "PACK_SIZE_TOO_LARGE",
+		Message: "Pack size exceeds the maximum limit",
+	}},
+	{model.ErrTooManyCurvePoints, ErrorMapping{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "TOO_MANY_CURVE_POINTS",
+		Message: "Number of overage curve points exceeds the maximum limit",
+	}},
+	{model.ErrBatchTooLarge, ErrorMapping{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "BATCH_TOO_LARGE",
+		Message: "Batch size exceeds the maximum limit",
+	}},
+	{model.ErrInsufficientStock, ErrorMapping{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "INSUFFICIENT_STOCK",
+		Message: "No pack combination fits the available stock",
+	}},
+	{model.ErrTooManyAlternatives, ErrorMapping{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "TOO_MANY_ALTERNATIVES",
+		Message: "Number of alternatives exceeds the maximum limit",
+	}},
+	{model.ErrPackTooHeavy, ErrorMapping{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "PACK_TOO_HEAVY",
+		Message: "Every pack size exceeds the parcel weight cap",
+	}},
+	{model.ErrShipmentCapsExceeded, ErrorMapping{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "SHIPMENT_CAPS_EXCEEDED",
+		Message: "No pack combination fits in a single shipment",
+	}},
+	{model.ErrInvalidShipmentCapacity, ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    "INVALID_SHIPMENT_CAPACITY",
+		Message: "Shipment capacity needs max packs or max items",
+	}},
+	{model.ErrPackExceedsCapacity, ErrorMapping{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "PACK_EXCEEDS_CAPACITY",
+		Message: "A pack holds more items than a shipment",
+	}},
+	{model.ErrTooManyShipments, ErrorMapping{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "TOO_MANY_SHIPMENTS",
+		Message: "Number of shipments exceeds the maximum limit",
+	}},
+	{model.ErrTooManyCandidatePackSets, ErrorMapping{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "TOO_MANY_CANDIDATE_PACK_SETS",
+		Message: "Number of candidate pack sets exceeds the maximum limit",
+	}},
+	{model.ErrToleranceNotMet, ErrorMapping{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "TOLERANCE_NOT_MET",
+		Message: "No pack combination is within the tolerance",
+	}},
+}
+
+// MapError resolves an error to its HTTP presentation. Errors without a
+// mapping are reported as internal errors so their text is not leaked.
+func MapError(err error) ErrorMapping {
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			return m.mapping
+		}
+	}
+	return ErrorMapping{http.StatusInternalServerError, CodeInternalError, "Internal server error"}
+}
+
+// WriteError writes the error response for a domain error, as problem+json
+// when the client asks for it and as the legacy envelope otherwise. Either
+// way the response varies with Accept, so caches keep the formats apart.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	w.Header().Add("Vary", "Accept")
+	mapping := MapError(err)
+
+	detail := mapping.Message
+	if mapping.Code != CodeInternalError {
+		detail = err.Error()
+	}
+
+	if WantsProblem(r) {
+		writeProblem(w, r, mapping, detail, nil)
+		return
+	}
+
+	response := ErrorResponse{
+		Success: false,
+		Error:   detail,
+		Code:    mapping.Code,
+	}
+	if mapping.Code != CodeInternalError {
+		response.Message = mapping.Message
+	}
+
+	writeErrorResponse(w, mapping.Status, response)
+}
+
+// WriteValidationError writes a 400 response listing each failed field,
+// varying with Accept like WriteError
+func WriteValidationError(w http.ResponseWriter, r *http.Request, err error) {
+	w.Header().Add("Vary", "Accept")
+	mapping := ErrorMapping{
+		Status:  http.StatusBadRequest,
+		Code:    CodeValidationFailed,
+		Message: "Validation failed",
+	}
+	details := ValidationDetails(err)
+
+	if WantsProblem(r) {
+		writeProblem(w, r, mapping, "One or more fields failed validation", details)
+		return
+	}
+
+	response := ErrorResponse{
+		Success: false,
+		Error:   mapping.Message,
+		Code:    mapping.Code,
+		Details: details,
+	}
+
+	writeErrorResponse(w, mapping.Status, response)
+}
+
+// ValidationDetails converts validator errors into field errors
+func ValidationDetails(err error) []FieldError {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	details := make([]FieldError, len(validationErrors))
+	for i, fe := range validationErrors {
+		details[i] = FieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: fieldMessage(fe),
+		}
+	}
+	return details
+}
+
+// fieldPath strips the struct name from the validator namespace,
+// e.g. "CalculationRequest.pack_sizes[1]" becomes "pack_sizes[1]"
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// fieldMessage builds a human-readable message for a failed rule
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must contain at least %s item(s)", fe.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fe.Param())
+	case "max":
+		if fe.Kind() == reflect.Slice {
+			return fmt.Sprintf("must contain at most %s item(s)", fe.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fe.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "gte":
+		return fmt.Sprintf("must be greater than or equal to %s", fe.Param())
+	default:
+		return fmt.Sprintf("failed %s validation", fe.Tag())
+	}
+}
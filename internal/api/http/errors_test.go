@@ -0,0 +1,90 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pack-calculator/internal/domain/model"
+)
+
+func TestMapError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"Empty pack sizes", model.ErrEmptyPackSizes, http.StatusBadRequest, "EMPTY_PACK_SIZES"},
+		{"Pack not found", model.ErrPackNotFound, http.StatusNotFound, "PACK_NOT_FOUND"},
+		{"Pack exists", model.ErrPackAlreadyExists, http.StatusConflict, "PACK_ALREADY_EXISTS"},
+		{
+			name:           "Wrapped limit error",
+			err:            fmt.Errorf("%w: 1001 > 1000", model.ErrOrderTooLarge),
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedCode:   "ORDER_TOO_LARGE",
+		},
+		{
+			name:           "Timeout",
+			err:            model.ErrCalculationTimeout,
+			expectedStatus: http.StatusRequestTimeout,
+			expectedCode:   "CALCULATION_TIMEOUT",
+		},
+		{
+			name:           "Corrupt solution table",
+			err:            fmt.Errorf("%w: checksum mismatch", model.ErrInvalidSolutionTable),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "INVALID_SOLUTION_TABLE",
+		},
+		{
+			name:           "Unknown error",
+			err:            errors.New("boom"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   CodeInternalError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapping := MapError(tt.err)
+
+			if mapping.Status != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, mapping.Status)
+			}
+
+			if mapping.Code != tt.expectedCode {
+				t.Errorf("Expected code %s, got %s", tt.expectedCode, mapping.Code)
+			}
+		})
+	}
+}
+
+func TestMapError_AllSentinelsMapped(t *testing.T) {
+	for _, sentinel := range model.DomainErrors {
+		if mapping := MapError(sentinel); mapping.Code == CodeInternalError || mapping.Message == "" {
+			t.Errorf("Sentinel %q has no usable mapping", sentinel)
+		}
+	}
+}
+
+func TestWriteError_HidesInternalErrors(t *testing.T) {
+	rr := httptest.NewRecorder()
+	WriteError(rr, httptest.NewRequest("GET", "/", nil), errors.New("database password is hunter2"))
+
+	var response ErrorResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Error != "Internal server error" {
+		t.Errorf("Internal error text leaked: %q", response.Error)
+	}
+
+	// The legacy envelope is chosen by Accept too
+	if vary := rr.Header().Get("Vary"); vary != "Accept" {
+		t.Errorf("Expected Vary: Accept, got %q", vary)
+	}
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ProblemContentType is the RFC 7807 media type for error responses
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix namespaces the problem type URIs derived from error codes
+const problemTypePrefix = "urn:pack-calculator:problem:"
+
+// ProblemDetails is an RFC 7807 error response. Code and Errors are
+// extension members carrying the same data as the legacy envelope.
+type ProblemDetails struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// WantsProblem reports whether the Accept header prefers problem+json
+// over plain JSON. Without an explicit preference the legacy envelope wins.
+func WantsProblem(r *http.Request) bool {
+	if r == nil {
+		return false
+	}
+
+	problemQ, jsonQ := 0.0, 0.0
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, q := parseMediaRange(part)
+		switch mediaType {
+		case ProblemContentType:
+			problemQ = q
+		case "application/json":
+			jsonQ = q
+		}
+	}
+
+	return problemQ > 0 && problemQ >= jsonQ
+}
+
+// parseMediaRange splits a single Accept entry into its media type and
+// quality value
+func parseMediaRange(part string) (string, float64) {
+	params := strings.Split(part, ";")
+	mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+
+	q := 1.0
+	for _, param := range params[1:] {
+		key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || strings.ToLower(strings.TrimSpace(key)) != "q" {
+			continue
+		}
+		if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+			q = parsed
+		}
+	}
+
+	return mediaType, q
+}
+
+// problemType derives a stable type URI from an error code,
+// e.g. ORDER_TOO_LARGE becomes urn:pack-calculator:problem:order-too-large
+func problemType(code string) string {
+	if code == "" || code == CodeInternalError {
+		return "about:blank"
+	}
+	return problemTypePrefix + strings.ReplaceAll(strings.ToLower(code), "_", "-")
+}
+
+// writeProblem encodes an RFC 7807 response; instance is the request ID
+// assigned by the logging middleware
+func writeProblem(
+	w http.ResponseWriter,
+	r *http.Request,
+	mapping ErrorMapping,
+	detail string,
+	fieldErrors []FieldError,
+) {
+	problem := ProblemDetails{
+		Type:     problemType(mapping.Code),
+		Title:    mapping.Message,
+		Status:   mapping.Status,
+		Detail:   detail,
+		Instance: r.Header.Get("X-Request-ID"),
+		Code:     mapping.Code,
+		Errors:   fieldErrors,
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(mapping.Status)
+	json.NewEncoder(w).Encode(problem)
+}
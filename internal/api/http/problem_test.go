@@ -0,0 +1,78 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pack-calculator/internal/domain/model"
+)
+
+func TestWantsProblem(t *testing.T) {
+	tests := []struct {
+		name     string
+		accept   string
+		expected bool
+	}{
+		{"No Accept header", "", false},
+		{"Plain JSON", "application/json", false},
+		{"Wildcard", "*/*", false},
+		{"Problem JSON", "application/problem+json", true},
+		{"Problem JSON preferred", "application/json;q=0.5, application/problem+json", true},
+		{"Plain JSON preferred", "application/json, application/problem+json;q=0.8", false},
+		{"Equal preference", "application/problem+json, application/json", true},
+		{"Problem JSON refused", "application/problem+json;q=0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+
+			if got := WantsProblem(req); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWriteError_Problem(t *testing.T) {
+	req := httptest.NewRequest("POST", "/api/v1/calculate", nil)
+	req.Header.Set("Accept", ProblemContentType)
+	req.Header.Set("X-Request-ID", "a1b2c3d4")
+
+	rr := httptest.NewRecorder()
+	WriteError(rr, req, model.ErrEmptyPackSizes)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	if ct := rr.Header().Get("Content-Type"); ct != ProblemContentType {
+		t.Errorf("Expected content type %s, got %s", ProblemContentType, ct)
+	}
+
+	if vary := rr.Header().Get("Vary"); vary != "Accept" {
+		t.Errorf("Expected Vary: Accept, got %q", vary)
+	}
+
+	var problem ProblemDetails
+	if err := json.Unmarshal(rr.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if problem.Type != "urn:pack-calculator:problem:empty-pack-sizes" {
+		t.Errorf("Unexpected type %q", problem.Type)
+	}
+
+	if problem.Status != http.StatusBadRequest || problem.Code != "EMPTY_PACK_SIZES" {
+		t.Errorf("Unexpected status/code: %d %s", problem.Status, problem.Code)
+	}
+
+	if problem.Instance != "a1b2c3d4" {
+		t.Errorf("Expected instance a1b2c3d4, got %q", problem.Instance)
+	}
+}
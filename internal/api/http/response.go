@@ -5,6 +5,10 @@ import (
 	"net/http"
 )
 
+// StatusClientClosedRequest is the non-standard status used when the client
+// goes away before the response is written
+const StatusClientClosedRequest = 499
+
 type Response struct {
 	Success bool        `json:"success"`
 	Data    interface{} `json:"data,omitempty"`
@@ -13,9 +17,11 @@ type Response struct {
 }
 
 type ErrorResponse struct {
-	Success bool   `json:"success"`
-	Error   string `json:"error"`
-	Code    string `json:"code,omitempty"`
+	Success bool         `json:"success"`
+	Error   string       `json:"error"`
+	Code    string       `json:"code,omitempty"`
+	Message string       `json:"message,omitempty"`
+	Details []FieldError `json:"details,omitempty"`
 }
 
 // WriteSuccessResponse writes a successful JSON response
@@ -31,22 +37,10 @@ func WriteSuccessResponse(w http.ResponseWriter, statusCode int, data interface{
 	json.NewEncoder(w).Encode(response)
 }
 
-// WriteErrorResponse writes an error JSON response
-func WriteErrorResponse(w http.ResponseWriter, statusCode int, message string, code ...string) {
+// writeErrorResponse encodes a prepared error response
+func writeErrorResponse(w http.ResponseWriter, statusCode int, response ErrorResponse) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
-
-	errorCode := ""
-	if len(code) > 0 {
-		errorCode = code[0]
-	}
-
-	response := ErrorResponse{
-		Success: false,
-		Error:   message,
-		Code:    errorCode,
-	}
-
 	json.NewEncoder(w).Encode(response)
 }
 
@@ -21,11 +21,67 @@ func NewRouter() *Router {
 }
 
 // RegisterCalculationRoutes registers calculation-related routes
-func (r *Router) RegisterCalculationRoutes(calculateHandler http.HandlerFunc) {
+func (r *Router) RegisterCalculationRoutes(
+	calculateHandler, batchHandler, explainHandler http.HandlerFunc,
+) {
 	api := r.router.PathPrefix("/api/v1").Subrouter()
 
 	// Calculation routes
 	api.HandleFunc("/calculate", calculateHandler).Methods("POST")
+	api.HandleFunc("/calculate/batch", batchHandler).Methods("POST")
+	api.HandleFunc("/calculate/explain", explainHandler).Methods("POST")
+}
+
+// RegisterPackRoutes registers pack configuration routes
+func (r *Router) RegisterPackRoutes(
+	listHandler, getHandler, createHandler, updateHandler, deleteHandler http.HandlerFunc,
+) {
+	api := r.router.PathPrefix("/api/v1").Subrouter()
+
+	// Pack routes
+	api.HandleFunc("/packs", listHandler).Methods("GET")
+	api.HandleFunc("/packs", createHandler).Methods("POST")
+	api.HandleFunc("/packs/{id}", getHandler).Methods("GET")
+	api.HandleFunc("/packs/{id}", updateHandler).Methods("PUT")
+	api.HandleFunc("/packs/{id}", deleteHandler).Methods("DELETE")
+}
+
+// RegisterOrderRoutes registers routes that calculate against stored packs
+func (r *Router) RegisterOrderRoutes(calculateHandler, planHandler http.HandlerFunc) {
+	api := r.router.PathPrefix("/api/v1").Subrouter()
+
+	// Order routes
+	api.HandleFunc("/orders/calculate", calculateHandler).Methods("POST")
+	api.HandleFunc("/orders/plan", planHandler).Methods("POST")
+}
+
+// RegisterShipmentRoutes registers shipment planning routes
+func (r *Router) RegisterShipmentRoutes(planHandler http.HandlerFunc) {
+	api := r.router.PathPrefix("/api/v1").Subrouter()
+
+	// Shipment routes
+	api.HandleFunc("/shipments/plan", planHandler).Methods("POST")
+}
+
+// RegisterPackSetRoutes registers pack size analysis routes
+func (r *Router) RegisterPackSetRoutes(
+	recommendHandler, tableHandler, analyzeHandler http.HandlerFunc,
+) {
+	api := r.router.PathPrefix("/api/v1").Subrouter()
+
+	// Pack set routes
+	api.HandleFunc("/packsets/recommend", recommendHandler).Methods("POST")
+	api.HandleFunc("/packsets/table", tableHandler).Methods("GET")
+	api.HandleFunc("/packsets/analyze", analyzeHandler).Methods("GET")
+}
+
+// RegisterHistoryRoutes registers calculation history routes
+func (r *Router) RegisterHistoryRoutes(listHandler, getHandler http.HandlerFunc) {
+	api := r.router.PathPrefix("/api/v1").Subrouter()
+
+	// History routes
+	api.HandleFunc("/calculations", listHandler).Methods("GET")
+	api.HandleFunc("/calculations/{id}", getHandler).Methods("GET")
 }
 
 // RegisterHealthRoutes registers health check routes
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
@@ -9,9 +10,12 @@ import (
 
 // Config holds all application configuration
 type Config struct {
-	Server  ServerConfig  `mapstructure:"server"`
-	Logging LoggingConfig `mapstructure:"logging"`
-	App     AppConfig     `mapstructure:"app"`
+	Server         ServerConfig         `mapstructure:"server"`
+	Logging        LoggingConfig        `mapstructure:"logging"`
+	App            AppConfig            `mapstructure:"app"`
+	Limits         LimitsConfig         `mapstructure:"limits"`
+	SolutionTables SolutionTablesConfig `mapstructure:"solution_tables"`
+	Database       DatabaseConfig       `mapstructure:"database"`
 }
 
 // ServerConfig holds HTTP server configuration
@@ -31,9 +35,70 @@ type LoggingConfig struct {
 
 // AppConfig holds application-specific configuration
 type AppConfig struct {
-	Name        string `mapstructure:"name"`
-	Version     string `mapstructure:"version"`
-	Environment string `mapstructure:"environment"`
+	Name         string        `mapstructure:"name"`
+	Version      string        `mapstructure:"version"`
+	Environment  string        `mapstructure:"environment"`
+	MaxSolveTime time.Duration `mapstructure:"max_solve_time"`
+	BatchWorkers int           `mapstructure:"batch_workers"`
+	// HistorySize caps the in-memory calculation history when the
+	// database is disabled
+	HistorySize int `mapstructure:"history_size"`
+	// IDFormat selects the identifier format: "ulid" or "uuidv7"
+	IDFormat string `mapstructure:"id_format"`
+}
+
+// LimitsConfig holds input limits that protect the solver from
+// oversized requests; zero disables a limit
+type LimitsConfig struct {
+	MaxOrderQuantity     int `mapstructure:"max_order_quantity"`
+	MaxPackSizes         int `mapstructure:"max_pack_sizes"`
+	MaxPackSize          int `mapstructure:"max_pack_size"`
+	MaxBatchSize         int `mapstructure:"max_batch_size"`
+	MaxAlternatives      int `mapstructure:"max_alternatives"`
+	MaxShipments         int `mapstructure:"max_shipments"`
+	MaxCandidatePackSets int `mapstructure:"max_candidate_pack_sets"`
+}
+
+// SolutionTablesConfig holds the precomputed solution tables that answer
+// calculations of hot pack sets
+type SolutionTablesConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxQuantity is the largest order quantity a table answers
+	MaxQuantity int `mapstructure:"max_quantity"`
+	// MaxTables caps how many pack sets have a table
+	MaxTables int `mapstructure:"max_tables"`
+	// HotAfter is how many solves of a pack set build its table; zero
+	// only builds tables for the stored catalogs
+	HotAfter int `mapstructure:"hot_after"`
+	// MaxBuilds caps how many tables are built at once
+	MaxBuilds int `mapstructure:"max_builds"`
+	// BuildTimeout bounds each table build
+	BuildTimeout time.Duration `mapstructure:"build_timeout"`
+}
+
+// DatabaseConfig holds PostgreSQL connection and pool configuration.
+// When Enabled is false the service keeps all state in memory.
+type DatabaseConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	Host            string        `mapstructure:"host"`
+	Port            int           `mapstructure:"port"`
+	User            string        `mapstructure:"user"`
+	Password        string        `mapstructure:"password"`
+	Name            string        `mapstructure:"name"`
+	SSLMode         string        `mapstructure:"sslmode"`
+	MaxOpenConns    int           `mapstructure:"max_open_conns"`
+	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time"`
+	AutoMigrate     bool          `mapstructure:"auto_migrate"`
+}
+
+// DSN returns the PostgreSQL connection string
+func (c DatabaseConfig) DSN() string {
+	return fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		c.Host, c.Port, c.User, c.Password, c.Name, c.SSLMode,
+	)
 }
 
 // Load loads configuration using Viper
@@ -71,4 +136,39 @@ func setDefaults() {
 	viper.SetDefault("app.name", "pack-calculator")
 	viper.SetDefault("app.version", "1.0.0")
 	viper.SetDefault("app.environment", "development")
+	viper.SetDefault("app.max_solve_time", 10*time.Second)
+	viper.SetDefault("app.batch_workers", 4)
+	viper.SetDefault("app.history_size", 10000)
+	viper.SetDefault("app.id_format", "ulid")
+
+	// Limits defaults
+	viper.SetDefault("limits.max_order_quantity", 10000000)
+	viper.SetDefault("limits.max_pack_sizes", 20)
+	viper.SetDefault("limits.max_pack_size", 1000000)
+	viper.SetDefault("limits.max_batch_size", 1000)
+	viper.SetDefault("limits.max_alternatives", 10)
+	viper.SetDefault("limits.max_shipments", 1000)
+	viper.SetDefault("limits.max_candidate_pack_sets", 5000)
+
+	// Solution tables defaults
+	viper.SetDefault("solution_tables.enabled", true)
+	viper.SetDefault("solution_tables.max_quantity", 100000)
+	viper.SetDefault("solution_tables.max_tables", 32)
+	viper.SetDefault("solution_tables.hot_after", 100)
+	viper.SetDefault("solution_tables.max_builds", 2)
+	viper.SetDefault("solution_tables.build_timeout", time.Minute)
+
+	// Database defaults
+	viper.SetDefault("database.enabled", false)
+	viper.SetDefault("database.host", "localhost")
+	viper.SetDefault("database.port", 5432)
+	viper.SetDefault("database.user", "postgres")
+	viper.SetDefault("database.password", "")
+	viper.SetDefault("database.name", "pack_calculator")
+	viper.SetDefault("database.sslmode", "disable")
+	viper.SetDefault("database.max_open_conns", 25)
+	viper.SetDefault("database.max_idle_conns", 5)
+	viper.SetDefault("database.conn_max_lifetime", 30*time.Minute)
+	viper.SetDefault("database.conn_max_idle_time", 5*time.Minute)
+	viper.SetDefault("database.auto_migrate", true)
 }
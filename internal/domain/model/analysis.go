@@ -0,0 +1,28 @@
+package model
+
+// PackSetAnalysis describes which totals a pack set ships exactly and how
+// much an order overships across a range of quantities
+type PackSetAnalysis struct {
+	PackSizes []int
+	// GCD is the greatest common divisor of the pack sizes, which divides
+	// every reachable total
+	GCD int
+	// FrobeniusNumber is the largest multiple of GCD that no combination of
+	// the pack sizes reaches exactly; every larger multiple is reachable.
+	// It is 0 when every multiple is reachable.
+	FrobeniusNumber int
+	// RedundantSizes are the sizes the smaller sizes add up to exactly.
+	// Dropping them never adds overage, only packs.
+	RedundantSizes []int
+	// OverageCurve is the optimal distribution's overage per order
+	// quantity, in ascending order
+	OverageCurve []OveragePoint
+}
+
+// OveragePoint is how the optimal distribution ships one order quantity
+type OveragePoint struct {
+	OrderQuantity int
+	TotalItems    int
+	TotalPacks    int
+	Overage       int
+}
@@ -10,7 +10,45 @@ import (
 // PackDistribution represents how many packs of each size are used
 type PackDistribution map[int]int
 
-// Calculation represents a pack calculation event
+// PackStock limits how many packs of each size are available. Sizes
+// without an entry are unlimited.
+type PackStock map[int]int
+
+// CostModel prices a distribution: every item shipped beyond or short of
+// the order costs ItemCost and every pack costs the HandlingCost of its size.
+// Amounts are in minor currency units; sizes without a handling cost are
+// free to use.
+type CostModel struct {
+	ItemCost     int64
+	HandlingCost map[int]int64
+}
+
+// Validate checks that no cost is negative
+func (c CostModel) Validate() error {
+	if c.ItemCost < 0 {
+		return ErrInvalidCost
+	}
+	for _, cost := range c.HandlingCost {
+		if cost < 0 {
+			return ErrInvalidCost
+		}
+	}
+	return nil
+}
+
+// Cost returns the cost of fulfilling orderQuantity with distribution
+func (c CostModel) Cost(distribution PackDistribution, orderQuantity int) int64 {
+	delta := distribution.TotalItems() - orderQuantity
+	cost := int64(max(delta, -delta)) * c.ItemCost
+	for size, count := range distribution {
+		cost += int64(count) * c.HandlingCost[size]
+	}
+	return cost
+}
+
+// Calculation represents a pack calculation event. ItemsDelta is the
+// signed difference between the items shipped and ordered; it is negative
+// when a tolerance let the order ship short.
 type Calculation struct {
 	ID                string         `json:"id"                  gorm:"primaryKey;type:varchar(255)"`
 	PackSizes         datatypes.JSON `json:"pack_sizes"          gorm:"type:jsonb"`
@@ -19,10 +57,51 @@ type Calculation struct {
 	TotalItems        int            `json:"total_items"         gorm:"not null"`
 	TotalPacks        int            `json:"total_packs"         gorm:"not null"`
 	ItemsOverage      int            `json:"items_overage"       gorm:"not null"`
+	ItemsDelta        int            `json:"items_delta"         gorm:"not null;default:0"`
+	Objective         string         `json:"objective"           gorm:"type:varchar(32)"`
+	TotalCost         int64          `json:"total_cost"          gorm:"not null;default:0"`
+	TotalWeightGrams  int64          `json:"total_weight_grams"  gorm:"not null;default:0"`
+	TotalVolumeMm3    int64          `json:"total_volume_mm3"    gorm:"not null;default:0"`
+	SplitRequired     bool           `json:"split_required"      gorm:"not null;default:false"`
+	MinShipments      int            `json:"min_shipments"       gorm:"not null;default:0"`
 	CalculationTimeMs int64          `json:"calculation_time_ms" gorm:"not null"`
 	CalculationTime   time.Duration  `json:"calculation_time"    gorm:"-"`
 	CreatedAt         time.Time      `json:"created_at"          gorm:"not null;index"`
 	UserID            string         `json:"user_id,omitempty"   gorm:"type:varchar(255);index"`
+
+	// Alternatives lists the ranked distributions when more than one was
+	// requested; they are not persisted
+	Alternatives []Alternative `json:"alternatives,omitempty" gorm:"-"`
+	// Packaging is the distribution rolled up into outer units when any
+	// pack size has packaging; it is not persisted
+	Packaging []PackagingUnit `json:"packaging,omitempty" gorm:"-"`
+}
+
+// Alternative is one of the ranked distributions offered for an order,
+// best first, with the totals a dispatcher compares them by
+type Alternative struct {
+	Distribution PackDistribution `json:"distribution"`
+	TotalItems   int              `json:"total_items"`
+	TotalPacks   int              `json:"total_packs"`
+	ItemsOverage int              `json:"items_overage"`
+	TotalCost    int64            `json:"total_cost"`
+}
+
+// NewAlternative summarises distribution as an alternative for
+// orderQuantity
+func NewAlternative(distribution PackDistribution, orderQuantity int) Alternative {
+	totalItems := distribution.TotalItems()
+	overage := 0
+	if totalItems > orderQuantity {
+		overage = totalItems - orderQuantity
+	}
+
+	return Alternative{
+		Distribution: distribution,
+		TotalItems:   totalItems,
+		TotalPacks:   distribution.TotalPacks(),
+		ItemsOverage: overage,
+	}
 }
 
 // NewCalculation creates a new calculation
@@ -46,6 +125,7 @@ func NewCalculation(
 		TotalItems:        totalItems,
 		TotalPacks:        totalPacks,
 		ItemsOverage:      overage,
+		ItemsDelta:        totalItems - orderQuantity,
 		CalculationTime:   calculationTime,
 		CalculationTimeMs: calculationTime.Milliseconds(),
 		CreatedAt:         time.Now(),
@@ -59,6 +139,13 @@ func (c *Calculation) GetDistribution() PackDistribution {
 	return dist
 }
 
+// GetPackSizes returns the pack sizes the calculation was solved against
+func (c *Calculation) GetPackSizes() []int {
+	var sizes []int
+	json.Unmarshal(c.PackSizes, &sizes)
+	return sizes
+}
+
 // TotalItems calculates total items in the distribution
 func (pd PackDistribution) TotalItems() int {
 	total := 0
@@ -0,0 +1,139 @@
+package model
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DemandBucket is how many orders asked for exactly Quantity items
+type DemandBucket struct {
+	Quantity int `json:"quantity"`
+	Orders   int `json:"orders"`
+}
+
+// DemandHistogram is the distribution of order quantities that a pack set
+// is evaluated against
+type DemandHistogram []DemandBucket
+
+// Validate checks that there is demand and every bucket is positive
+func (h DemandHistogram) Validate() error {
+	if len(h) == 0 {
+		return ErrEmptyDemand
+	}
+	for _, bucket := range h {
+		if bucket.Quantity <= 0 || bucket.Orders <= 0 {
+			return ErrInvalidDemand
+		}
+	}
+	return nil
+}
+
+// TotalOrders returns the number of orders in the histogram
+func (h DemandHistogram) TotalOrders() int {
+	total := 0
+	for _, bucket := range h {
+		total += bucket.Orders
+	}
+	return total
+}
+
+// TotalItems returns the number of items ordered across the histogram
+func (h DemandHistogram) TotalItems() int {
+	total := 0
+	for _, bucket := range h {
+		total += bucket.Quantity * bucket.Orders
+	}
+	return total
+}
+
+// Merge returns the histogram with the buckets of equal quantities
+// combined, ordered by quantity
+func (h DemandHistogram) Merge() DemandHistogram {
+	orders := make(map[int]int, len(h))
+	for _, bucket := range h {
+		orders[bucket.Quantity] += bucket.Orders
+	}
+	merged := make(DemandHistogram, 0, len(orders))
+	for quantity, count := range orders {
+		merged = append(merged, DemandBucket{Quantity: quantity, Orders: count})
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Quantity < merged[j].Quantity
+	})
+	return merged
+}
+
+// ParseDemandCSV reads a histogram with one "quantity,orders" record per
+// line. The orders column may be left out to count each line as one order,
+// so a plain export of order quantities works as is, and a header line is
+// skipped. Buckets of equal quantities are merged.
+func ParseDemandCSV(r io.Reader) (DemandHistogram, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var histogram DemandHistogram
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidDemand, err)
+		}
+
+		if line == 1 && isDemandHeader(record) {
+			continue
+		}
+		bucket, err := parseDemandRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidDemand, line, err)
+		}
+		histogram = append(histogram, bucket)
+	}
+
+	if err := histogram.Validate(); err != nil {
+		return nil, err
+	}
+	return histogram.Merge(), nil
+}
+
+// isDemandHeader reports whether record names the columns rather than
+// holding a quantity
+func isDemandHeader(record []string) bool {
+	_, err := strconv.Atoi(strings.TrimSpace(record[0]))
+	return err != nil
+}
+
+// parseDemandRecord parses a "quantity[,orders]" record
+func parseDemandRecord(record []string) (DemandBucket, error) {
+	if len(record) == 0 || len(record) > 2 {
+		return DemandBucket{}, fmt.Errorf("expected 1 or 2 fields, got %d", len(record))
+	}
+	quantity, err := strconv.Atoi(strings.TrimSpace(record[0]))
+	if err != nil {
+		return DemandBucket{}, err
+	}
+	bucket := DemandBucket{Quantity: quantity, Orders: 1}
+	if len(record) == 2 {
+		if bucket.Orders, err = strconv.Atoi(strings.TrimSpace(record[1])); err != nil {
+			return DemandBucket{}, err
+		}
+	}
+	return bucket, nil
+}
+
+// PackSetScore is how a candidate pack set serves a demand histogram: the
+// items shipped beyond the order and the packs used, per order on average,
+// and the overage as a fraction of all items ordered
+type PackSetScore struct {
+	PackSizes       []int
+	ExpectedOverage float64
+	ExpectedPacks   float64
+	OverageRate     float64
+}
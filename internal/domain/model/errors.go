@@ -9,13 +9,105 @@ var (
 	ErrInvalidPackName   = errors.New("pack name cannot be empty")
 	ErrPackNotFound      = errors.New("pack not found")
 	ErrPackAlreadyExists = errors.New("pack already exists")
+	ErrInvalidPackStock  = errors.New("pack stock cannot be negative")
 
 	// Calculation errors
 	ErrInvalidOrderQuantity = errors.New("order quantity must be greater than zero")
 	ErrEmptyPackSizes       = errors.New("pack sizes cannot be empty")
 	ErrCalculationFailed    = errors.New("unable to calculate pack distribution")
+	ErrCalculationCanceled  = errors.New("calculation canceled")
+	ErrCalculationTimeout   = errors.New("calculation exceeded time limit")
+	ErrCalculationNotFound  = errors.New("calculation not found")
+	ErrInvalidCursor        = errors.New("invalid pagination cursor")
+	ErrUnknownObjective     = errors.New("unknown optimisation objective")
+	ErrInvalidCost          = errors.New("costs cannot be negative")
+	ErrInvalidMeasurements  = errors.New("pack weight and dimensions cannot be negative")
+	ErrInvalidShipmentCaps  = errors.New("shipment caps cannot be negative")
+	ErrInvalidPackaging     = errors.New("packaging levels need a name and a positive quantity")
+	ErrInvalidTolerance     = errors.New("tolerance caps cannot be negative or mix units")
+	ErrInvalidPackRule      = errors.New("pack rule counts cannot be negative or contradict")
+
+	// Order errors
+	ErrEmptyOrder   = errors.New("order has no line items")
+	ErrInvalidSKU   = errors.New("order line SKU cannot be empty")
+	ErrDuplicateSKU = errors.New("order lists a SKU more than once")
+
+	// Pack size recommendation errors
+	ErrEmptyDemand              = errors.New("demand histogram has no orders")
+	ErrInvalidDemand            = errors.New("demand quantities and order counts must be positive")
+	ErrInvalidPackSizeBudget    = errors.New("pack size budget must be greater than zero")
+	ErrTooManyCandidatePackSets = errors.New("number of candidate pack sets exceeds maximum limit")
+
+	// Solution table errors
+	ErrInvalidSolutionTable = errors.New("solution table is corrupt or unsupported")
+
+	// Pack set analysis errors
+	ErrInvalidQuantityRange = errors.New("quantity range must be positive and ascending")
+	ErrTooManyCurvePoints   = errors.New("number of overage curve points exceeds maximum limit")
+
+	// Shipment planning errors
+	ErrInvalidShipmentCapacity = errors.New("shipment capacity needs max packs or max items")
+	ErrPackExceedsCapacity     = errors.New("a pack holds more items than a shipment")
+	ErrTooManyShipments        = errors.New("number of shipments exceeds maximum limit")
 
 	// Business rule errors
-	ErrNoValidPacks  = errors.New("no valid pack configurations available")
-	ErrOrderTooLarge = errors.New("order quantity exceeds maximum limit")
+	ErrNoValidPacks         = errors.New("no valid pack configurations available")
+	ErrPackRulesNotMet      = errors.New("no pack combination satisfies the pack rules")
+	ErrOrderTooLarge        = errors.New("order quantity exceeds maximum limit")
+	ErrTooManyPackSizes     = errors.New("number of pack sizes exceeds maximum limit")
+	ErrPackSizeTooLarge     = errors.New("pack size exceeds maximum limit")
+	ErrBatchTooLarge        = errors.New("batch size exceeds maximum limit")
+	ErrInsufficientStock    = errors.New("no pack combination fits the available stock")
+	ErrTooManyAlternatives  = errors.New("number of alternatives exceeds maximum limit")
+	ErrPackTooHeavy         = errors.New("every pack size exceeds the parcel weight cap")
+	ErrShipmentCapsExceeded = errors.New("no pack combination fits in a single shipment")
+	ErrToleranceNotMet      = errors.New("no pack combination is within the tolerance")
 )
+
+// DomainErrors lists every domain error above, so that the layers
+// presenting them can check they handle each one. Add new errors here too.
+var DomainErrors = []error{
+	ErrInvalidPackSize,
+	ErrInvalidPackName,
+	ErrPackNotFound,
+	ErrPackAlreadyExists,
+	ErrInvalidPackStock,
+	ErrInvalidOrderQuantity,
+	ErrEmptyPackSizes,
+	ErrCalculationFailed,
+	ErrCalculationCanceled,
+	ErrCalculationTimeout,
+	ErrCalculationNotFound,
+	ErrInvalidCursor,
+	ErrUnknownObjective,
+	ErrInvalidCost,
+	ErrInvalidMeasurements,
+	ErrInvalidShipmentCaps,
+	ErrInvalidPackaging,
+	ErrInvalidTolerance,
+	ErrInvalidPackRule,
+	ErrEmptyOrder,
+	ErrInvalidSKU,
+	ErrDuplicateSKU,
+	ErrEmptyDemand,
+	ErrInvalidDemand,
+	ErrInvalidPackSizeBudget,
+	ErrTooManyCandidatePackSets,
+	ErrInvalidSolutionTable,
+	ErrInvalidQuantityRange,
+	ErrTooManyCurvePoints,
+	ErrInvalidShipmentCapacity,
+	ErrPackExceedsCapacity,
+	ErrTooManyShipments,
+	ErrNoValidPacks,
+	ErrPackRulesNotMet,
+	ErrOrderTooLarge,
+	ErrTooManyPackSizes,
+	ErrPackSizeTooLarge,
+	ErrBatchTooLarge,
+	ErrInsufficientStock,
+	ErrTooManyAlternatives,
+	ErrPackTooHeavy,
+	ErrShipmentCapsExceeded,
+	ErrToleranceNotMet,
+}
@@ -0,0 +1,37 @@
+package model
+
+// Explanation justifies an optimal distribution against the business
+// rules: ship the fewest items (rule 2), then the fewest packs (rule 3)
+type Explanation struct {
+	OrderQuantity int
+	Distribution  PackDistribution
+	// MinimalTotal is the smallest total of at least OrderQuantity that
+	// the pack sizes reach exactly
+	MinimalTotal int
+	// GCD is the greatest common divisor of the pack sizes, which divides
+	// every reachable total
+	GCD int
+	// Shortfall is a distribution with the largest reachable total below
+	// OrderQuantity, nil when every pack is larger than the order
+	Shortfall PackDistribution
+	// MinimalPacks is the fewest packs that reach MinimalTotal
+	MinimalPacks int
+	// EqualOverage lists the ranked distributions reaching MinimalTotal,
+	// fewest packs first, the optimum included
+	EqualOverage []Alternative
+	// RunnerUps are the best distributions after the optimum, each with
+	// the rule it loses on. They end with one that ships more items when
+	// such a distribution exists that no pack can be dropped from.
+	RunnerUps []Rejection
+	// Steps states the argument in order
+	Steps []string
+}
+
+// Rejection is a runner-up distribution and why the optimum beats it
+type Rejection struct {
+	Alternative
+	// Rule is 2 when the runner-up ships more items and 3 when it ships
+	// as many but does not use fewer packs
+	Rule   int
+	Reason string
+}
@@ -1,6 +1,9 @@
 package model
 
 import (
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -93,11 +96,33 @@ func TestNewCalculation(t *testing.T) {
 		t.Errorf("Expected overage 200, got %d", calc.ItemsOverage)
 	}
 
+	if calc.ItemsDelta != 200 {
+		t.Errorf("Expected items delta 200, got %d", calc.ItemsDelta)
+	}
+
+	short := NewCalculation([]int{500}, 501, PackDistribution{500: 1}, calculationTime)
+	if short.ItemsDelta != -1 || short.ItemsOverage != 0 {
+		t.Errorf("Expected 1 item short and no overage, got delta %d and overage %d",
+			short.ItemsDelta, short.ItemsOverage)
+	}
+
 	if calc.CalculationTime != calculationTime {
 		t.Errorf("Expected calculation time %v, got %v", calculationTime, calc.CalculationTime)
 	}
 }
 
+func TestNewAlternative(t *testing.T) {
+	alternative := NewAlternative(PackDistribution{250: 2, 500: 1}, 900)
+
+	if alternative.TotalItems != 1000 || alternative.TotalPacks != 3 {
+		t.Errorf("Expected 1000 items in 3 packs, got %+v", alternative)
+	}
+
+	if alternative.ItemsOverage != 100 {
+		t.Errorf("Expected overage 100, got %d", alternative.ItemsOverage)
+	}
+}
+
 func TestNewPack(t *testing.T) {
 	size := 250
 	name := "Test Pack"
@@ -144,16 +169,20 @@ func TestPack_IsValid(t *testing.T) {
 	tests := []struct {
 		name     string
 		size     int
+		stock    *int
 		expected bool
 	}{
-		{"Valid pack", 250, true},
-		{"Zero size", 0, false},
-		{"Negative size", -10, false},
+		{"Valid pack", 250, nil, true},
+		{"Zero size", 0, nil, false},
+		{"Negative size", -10, nil, false},
+		{"Limited stock", 250, intPtr(3), true},
+		{"Out of stock", 250, intPtr(0), true},
+		{"Negative stock", 250, intPtr(-1), false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			pack := &Pack{Size: tt.size}
+			pack := &Pack{Size: tt.size, Stock: tt.stock}
 			if pack.IsValid() != tt.expected {
 				t.Errorf("Expected %v, got %v", tt.expected, pack.IsValid())
 			}
@@ -201,3 +230,255 @@ func TestPack_Update(t *testing.T) {
 		t.Errorf("UpdatedAt should be updated after update")
 	}
 }
+
+func TestCostModel_Cost(t *testing.T) {
+	costs := CostModel{ItemCost: 2, HandlingCost: map[int]int64{250: 30, 500: 40}}
+	distribution := PackDistribution{250: 1, 500: 1}
+
+	// 249 overage items plus both handling costs
+	if cost := costs.Cost(distribution, 501); cost != 2*249+30+40 {
+		t.Errorf("Expected %d, got %d", 2*249+30+40, cost)
+	}
+
+	// A shortfall is charged like an overage
+	if cost := costs.Cost(PackDistribution{500: 1}, 501); cost != 2*1+40 {
+		t.Errorf("Expected %d, got %d", 2*1+40, cost)
+	}
+}
+
+func TestTolerance(t *testing.T) {
+	tests := []struct {
+		name      string
+		tolerance Tolerance
+		valid     bool
+		shortfall int
+	}{
+		{"zero", Tolerance{}, true, 0},
+		{"items", Tolerance{MaxShortfall: 5}, true, 5},
+		{"percent", Tolerance{MaxShortfallPercent: 2.5}, true, 25},
+		{"at least one item ships", Tolerance{MaxShortfall: 5000}, true, 999},
+		{"overage only", Tolerance{MaxOverage: 100}, true, 0},
+		{"negative", Tolerance{MaxOverage: -1}, false, 0},
+		{"mixed units", Tolerance{MaxShortfall: 1, MaxShortfallPercent: 1}, false, 0},
+		{"whole order", Tolerance{MaxShortfallPercent: 100}, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tolerance.Validate()
+			if tt.valid != (err == nil) {
+				t.Fatalf("Expected valid %v, got %v", tt.valid, err)
+			}
+			if !errors.Is(err, ErrInvalidTolerance) && err != nil {
+				t.Errorf("Expected ErrInvalidTolerance, got %v", err)
+			}
+			if tt.valid {
+				if shortfall := tt.tolerance.Shortfall(1000); shortfall != tt.shortfall {
+					t.Errorf("Expected shortfall %d, got %d", tt.shortfall, shortfall)
+				}
+			}
+		})
+	}
+}
+
+func TestShipmentCaps_MinShipments(t *testing.T) {
+	measurements := map[int]PackMeasurements{
+		250: {WeightGrams: 300, LengthMm: 100, WidthMm: 100, HeightMm: 50},
+		500: {WeightGrams: 550, LengthMm: 100, WidthMm: 100, HeightMm: 100},
+	}
+	distribution := PackDistribution{250: 1, 500: 3}
+
+	if weight := distribution.WeightGrams(measurements); weight != 1950 {
+		t.Errorf("Expected weight 1950, got %d", weight)
+	}
+	if volume := distribution.VolumeMm3(measurements); volume != 3500000 {
+		t.Errorf("Expected volume 3500000, got %d", volume)
+	}
+
+	tests := []struct {
+		name     string
+		caps     ShipmentCaps
+		expected int
+	}{
+		{"No caps", ShipmentCaps{}, 1},
+		{"Parcel cap only", ShipmentCaps{MaxPackWeightGrams: 100}, 1},
+		{"Pack count", ShipmentCaps{MaxPacks: 3}, 2},
+		{"Weight", ShipmentCaps{MaxWeightGrams: 500}, 4},
+		{"Volume", ShipmentCaps{MaxVolumeMm3: 1000000}, 4},
+		{"Largest bound wins", ShipmentCaps{MaxPacks: 1, MaxWeightGrams: 1000}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.caps.MinShipments(distribution, measurements); got != tt.expected {
+				t.Errorf("Expected %d shipments, got %d", tt.expected, got)
+			}
+			if fits := tt.caps.Fits(distribution, measurements); fits != (tt.expected == 1) {
+				t.Errorf("Expected fits %v, got %v", tt.expected == 1, fits)
+			}
+		})
+	}
+
+	if err := (ShipmentCaps{MaxPacks: -1}).Validate(); err != ErrInvalidShipmentCaps {
+		t.Errorf("Expected ErrInvalidShipmentCaps, got %v", err)
+	}
+	if err := (PackMeasurements{HeightMm: -1}).Validate(); err != ErrInvalidMeasurements {
+		t.Errorf("Expected ErrInvalidMeasurements, got %v", err)
+	}
+}
+
+func TestPackDistribution_RollUp(t *testing.T) {
+	packaging := map[int]Packaging{
+		250: {{Name: "carton", Quantity: 8}, {Name: "pallet", Quantity: 40}},
+	}
+	pack := PackagingUnit{Name: PackUnitName, PackSize: 250, Count: 8, Items: 250}
+	carton := PackagingUnit{Name: "carton", PackSize: 250, Count: 40, Items: 2000, Contents: &pack}
+
+	tests := []struct {
+		name         string
+		distribution PackDistribution
+		expected     []PackagingUnit
+	}{
+		{
+			name:         "Pallets, cartons and loose packs",
+			distribution: PackDistribution{250: 8*40 + 3*8 + 5},
+			expected: []PackagingUnit{
+				{Name: "pallet", PackSize: 250, Count: 1, Items: 80000, Contents: &carton},
+				{Name: "carton", PackSize: 250, Count: 3, Items: 2000, Contents: &pack},
+				{Name: PackUnitName, PackSize: 250, Count: 5, Items: 250},
+			},
+		},
+		{
+			name:         "Too few packs for a carton",
+			distribution: PackDistribution{250: 7},
+			expected:     []PackagingUnit{{Name: PackUnitName, PackSize: 250, Count: 7, Items: 250}},
+		},
+		{
+			name:         "Full cartons only",
+			distribution: PackDistribution{250: 16, 500: 2},
+			expected: []PackagingUnit{
+				{Name: PackUnitName, PackSize: 500, Count: 2, Items: 500},
+				{Name: "carton", PackSize: 250, Count: 2, Items: 2000, Contents: &pack},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.distribution.RollUp(packaging); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+
+	invalid := Packaging{{Name: "carton", Quantity: 0}}
+	if err := invalid.Validate(); err != ErrInvalidPackaging {
+		t.Errorf("Expected ErrInvalidPackaging, got %v", err)
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestParseDemandCSV(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    DemandHistogram
+		expectedErr error
+	}{
+		{
+			name:  "Histogram with header",
+			input: "quantity,orders\n500,5\n250, 10\n500,1\n",
+			expected: DemandHistogram{
+				{Quantity: 250, Orders: 10},
+				{Quantity: 500, Orders: 6},
+			},
+		},
+		{
+			name:  "Order quantities",
+			input: "263\n12001\n263\n",
+			expected: DemandHistogram{
+				{Quantity: 263, Orders: 2},
+				{Quantity: 12001, Orders: 1},
+			},
+		},
+		{
+			name:        "Header only",
+			input:       "quantity,orders\n",
+			expectedErr: ErrEmptyDemand,
+		},
+		{
+			name:        "Malformed line",
+			input:       "250,10\nlots,2\n",
+			expectedErr: ErrInvalidDemand,
+		},
+		{
+			name:        "Too many fields",
+			input:       "250,10,3\n500,1\n",
+			expectedErr: ErrInvalidDemand,
+		},
+		{
+			name:        "Zero orders",
+			input:       "250,0\n",
+			expectedErr: ErrInvalidDemand,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			demand, err := ParseDemandCSV(strings.NewReader(tt.input))
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Expected %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(demand, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, demand)
+			}
+		})
+	}
+}
+
+func TestPackRules(t *testing.T) {
+	negative, two := -1, 2
+
+	tests := []struct {
+		name     string
+		rule     PackRule
+		valid    bool
+		minPacks int
+	}{
+		{"zero", PackRule{}, true, 0},
+		{"required", PackRule{Required: true}, true, 1},
+		{"required with a min count", PackRule{Required: true, MinCount: 3}, true, 3},
+		{"bounded", PackRule{MinCount: 2, MaxCount: &two}, true, 2},
+		{"negative max count", PackRule{MaxCount: &negative}, false, 0},
+		{"min above max", PackRule{MinCount: 3, MaxCount: &two}, false, 0},
+		{"required and forbidden", PackRule{Required: true, Forbidden: true}, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := PackRules{250: tt.rule}.Validate()
+			if tt.valid != (err == nil) {
+				t.Fatalf("Expected valid %v, got %v", tt.valid, err)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidPackRule) {
+				t.Errorf("Expected ErrInvalidPackRule, got %v", err)
+			}
+			if tt.valid && tt.rule.MinPacks() != tt.minPacks {
+				t.Errorf("Expected %d packs at least, got %d", tt.minPacks, tt.rule.MinPacks())
+			}
+		})
+	}
+
+	threshold := PackRule{MinOrderQuantity: 1000}
+	if threshold.Allows(999) || !threshold.Allows(1000) {
+		t.Errorf("Expected the size from 1000 items only")
+	}
+}
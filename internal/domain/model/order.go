@@ -0,0 +1,53 @@
+package model
+
+// OrderLine is one product of a customer order
+type OrderLine struct {
+	SKU      string
+	Quantity int
+}
+
+// Order is a customer order of one or more products, each solved against
+// its own pack catalog
+type Order struct {
+	Lines []OrderLine
+}
+
+// Validate checks that the order has lines, every line names a product
+// and orders a positive quantity, and no product is listed twice
+func (o Order) Validate() error {
+	if len(o.Lines) == 0 {
+		return ErrEmptyOrder
+	}
+	seen := make(map[string]bool, len(o.Lines))
+	for _, line := range o.Lines {
+		if line.SKU == "" {
+			return ErrInvalidSKU
+		}
+		if line.Quantity <= 0 {
+			return ErrInvalidOrderQuantity
+		}
+		if seen[line.SKU] {
+			return ErrDuplicateSKU
+		}
+		seen[line.SKU] = true
+	}
+	return nil
+}
+
+// OrderPlan is the calculation of every line of an order, in order, with
+// the totals across all lines
+type OrderPlan struct {
+	Lines        []OrderLinePlan
+	OrderedItems int
+	TotalItems   int
+	TotalPacks   int
+	ItemsOverage int
+}
+
+// OrderLinePlan is the calculation of one order line against the packs of
+// its SKU's catalog
+type OrderLinePlan struct {
+	Line        OrderLine
+	Calculation *Calculation
+	Packs       []*Pack
+}
@@ -2,14 +2,28 @@ package model
 
 import "time"
 
-// Pack represents a pack configuration with a specific size
+// Pack represents a pack configuration with a specific size. SKU names the
+// product whose catalog the pack belongs to; packs without one form the
+// default catalog.
+// Stock is how many packs of the size are available; nil is unlimited.
+// HandlingCost is the cost of shipping one pack in minor currency units.
+// The embedded measurements give its weight and dimensions, and Packaging
+// the cartons, pallets and other outer units the packs are nested into.
+// Rule constrains how calculations against the catalog use the pack.
 type Pack struct {
-	ID        string    `json:"id"         gorm:"primaryKey;type:varchar(255)"`
-	Size      int       `json:"size"       gorm:"not null;index"`
-	Name      string    `json:"name"       gorm:"type:varchar(255)"`
-	Active    bool      `json:"active"     gorm:"not null;default:true;index"`
-	CreatedAt time.Time `json:"created_at" gorm:"not null"`
-	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
+	ID           string    `json:"id"            gorm:"primaryKey;type:varchar(255)"`
+	Size         int       `json:"size"          gorm:"not null;index"`
+	Name         string    `json:"name"          gorm:"type:varchar(255)"`
+	SKU          string    `json:"sku,omitempty" gorm:"type:varchar(255);not null;default:'';index"`
+	Active       bool      `json:"active"        gorm:"not null;default:true;index"`
+	Stock        *int      `json:"stock,omitempty"`
+	HandlingCost int64     `json:"handling_cost" gorm:"not null;default:0"`
+	CreatedAt    time.Time `json:"created_at"    gorm:"not null"`
+	UpdatedAt    time.Time `json:"updated_at"    gorm:"not null"`
+
+	PackMeasurements `gorm:"embedded"`
+	Packaging        Packaging `json:"packaging,omitempty" gorm:"serializer:json;type:jsonb"`
+	Rule             PackRule  `json:"rule"                gorm:"embedded;embeddedPrefix:rule_"`
 }
 
 // NewPack creates a new pack instance
@@ -26,7 +40,9 @@ func NewPack(size int, name string) *Pack {
 
 // IsValid checks if pack has valid configuration
 func (p *Pack) IsValid() bool {
-	return p.Size > 0
+	return p.Size > 0 && (p.Stock == nil || *p.Stock >= 0) && p.HandlingCost >= 0 &&
+		p.PackMeasurements.Validate() == nil && p.Packaging.Validate() == nil &&
+		p.Rule.Validate() == nil
 }
 
 // Deactivate marks the pack as inactive
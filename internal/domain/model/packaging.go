@@ -0,0 +1,93 @@
+package model
+
+import "sort"
+
+// PackagingLevel is an outer unit, such as a carton or a pallet, that holds
+// Quantity units of the level inside it
+type PackagingLevel struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+}
+
+// Packaging nests packs of one size into outer units, innermost first: a
+// carton of 8 packs followed by a pallet of 40 cartons
+type Packaging []PackagingLevel
+
+// Validate checks that every level is named and holds at least one unit
+func (p Packaging) Validate() error {
+	for _, level := range p {
+		if level.Name == "" || level.Quantity <= 0 {
+			return ErrInvalidPackaging
+		}
+	}
+	return nil
+}
+
+// PackUnitName names loose packs in a packaging breakdown
+const PackUnitName = "pack"
+
+// PackagingUnit is Count identical units of one packaging level. Each unit
+// holds Items items in packs of PackSize; Contents is what one unit holds
+// and is nil for a pack.
+type PackagingUnit struct {
+	Name     string
+	PackSize int
+	Count    int
+	Items    int
+	Contents *PackagingUnit
+}
+
+// RollUp packs distribution into the fewest outer units the packaging of
+// each size allows and returns the breakdown, largest pack size and
+// outermost unit first. Only full outer units are formed, so what is left
+// over at each level ships in the next level down; sizes without packaging
+// ship as loose packs.
+func (pd PackDistribution) RollUp(packaging map[int]Packaging) []PackagingUnit {
+	sizes := make([]int, 0, len(pd))
+	for size, count := range pd {
+		if count > 0 {
+			sizes = append(sizes, size)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+
+	var units []PackagingUnit
+	for _, size := range sizes {
+		remaining := pd[size]
+		levels := packagingUnits(size, remaining, packaging[size])
+		for i := len(levels) - 1; i >= 0; i-- {
+			perUnit := levels[i].Items / size
+			if count := remaining / perUnit; count > 0 {
+				unit := levels[i]
+				unit.Count = count
+				units = append(units, unit)
+				remaining %= perUnit
+			}
+		}
+	}
+	return units
+}
+
+// packagingUnits returns one unit of each level of packaging, loose pack
+// first, up to the largest that packs no more than count packs
+func packagingUnits(size, count int, packaging Packaging) []PackagingUnit {
+	units := []PackagingUnit{{Name: PackUnitName, PackSize: size, Count: 1, Items: size}}
+	packs := 1
+	for _, level := range packaging {
+		// Stop before a unit holds more packs than there are
+		if packs > count/level.Quantity {
+			break
+		}
+		packs *= level.Quantity
+		inner := units[len(units)-1]
+		inner.Count = level.Quantity
+		units = append(units, PackagingUnit{
+			Name:     level.Name,
+			PackSize: size,
+			Count:    1,
+			Items:    packs * size,
+			Contents: &inner,
+		})
+	}
+	return units
+}
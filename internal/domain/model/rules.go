@@ -0,0 +1,75 @@
+package model
+
+import "fmt"
+
+// PackRule constrains the packs of one size a distribution may use.
+// Required asks for at least one pack and Forbidden for none. MinCount and
+// MaxCount bound the number of packs, a nil MaxCount being unbounded, and
+// the size is only used for orders of at least MinOrderQuantity items.
+type PackRule struct {
+	Required         bool `json:"required,omitempty"           gorm:"not null;default:false"`
+	Forbidden        bool `json:"forbidden,omitempty"          gorm:"not null;default:false"`
+	MinCount         int  `json:"min_count,omitempty"          gorm:"not null;default:0"`
+	MaxCount         *int `json:"max_count,omitempty"`
+	MinOrderQuantity int  `json:"min_order_quantity,omitempty" gorm:"not null;default:0"`
+}
+
+// IsZero reports whether the rule leaves the size unconstrained
+func (r PackRule) IsZero() bool {
+	return !r.Required && !r.Forbidden && r.MinCount == 0 && r.MaxCount == nil &&
+		r.MinOrderQuantity == 0
+}
+
+// Validate checks that no count is negative and that the rule does not
+// contradict itself
+func (r PackRule) Validate() error {
+	if r.MinCount < 0 || r.MinOrderQuantity < 0 || (r.MaxCount != nil && *r.MaxCount < 0) {
+		return ErrInvalidPackRule
+	}
+	if r.Forbidden && r.MinPacks() > 0 {
+		return fmt.Errorf("%w: a required size cannot be forbidden", ErrInvalidPackRule)
+	}
+	if r.MaxCount != nil && r.MinPacks() > *r.MaxCount {
+		return fmt.Errorf("%w: min count %d exceeds max count %d",
+			ErrInvalidPackRule, r.MinPacks(), *r.MaxCount)
+	}
+	return nil
+}
+
+// MinPacks returns how many packs of the size every distribution uses at
+// least
+func (r PackRule) MinPacks() int {
+	if r.Required {
+		return max(r.MinCount, 1)
+	}
+	return r.MinCount
+}
+
+// Allows reports whether the size may be used for orderQuantity
+func (r PackRule) Allows(orderQuantity int) bool {
+	return !r.Forbidden && orderQuantity >= r.MinOrderQuantity
+}
+
+// PackRules maps a pack size to its rule; sizes without an entry are
+// unconstrained
+type PackRules map[int]PackRule
+
+// Validate checks every rule
+func (r PackRules) Validate() error {
+	for size, rule := range r {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("size %d: %w", size, err)
+		}
+	}
+	return nil
+}
+
+// IsZero reports whether no rule constrains any size
+func (r PackRules) IsZero() bool {
+	for _, rule := range r {
+		if !rule.IsZero() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+package model
+
+// PackMeasurements is the weight in grams and the outer dimensions in
+// millimetres of one pack. Zero values are unknown and count as nothing.
+type PackMeasurements struct {
+	WeightGrams int64 `json:"weight_grams" gorm:"not null;default:0"`
+	LengthMm    int64 `json:"length_mm"    gorm:"not null;default:0"`
+	WidthMm     int64 `json:"width_mm"     gorm:"not null;default:0"`
+	HeightMm    int64 `json:"height_mm"    gorm:"not null;default:0"`
+}
+
+// VolumeMm3 returns the volume of the pack in cubic millimetres
+func (m PackMeasurements) VolumeMm3() int64 {
+	return m.LengthMm * m.WidthMm * m.HeightMm
+}
+
+// Validate checks that no measurement is negative
+func (m PackMeasurements) Validate() error {
+	if m.WeightGrams < 0 || m.LengthMm < 0 || m.WidthMm < 0 || m.HeightMm < 0 {
+		return ErrInvalidMeasurements
+	}
+	return nil
+}
+
+// ShipmentCaps are courier limits; zero disables a cap. MaxPackWeight caps
+// every parcel, so heavier packs cannot be used at all. The other caps
+// limit a single shipment and can be met by splitting the order.
+type ShipmentCaps struct {
+	MaxPackWeightGrams int64
+	MaxWeightGrams     int64
+	MaxVolumeMm3       int64
+	MaxPacks           int
+}
+
+// IsZero reports whether no cap is set
+func (c ShipmentCaps) IsZero() bool {
+	return c == ShipmentCaps{}
+}
+
+// Validate checks that no cap is negative
+func (c ShipmentCaps) Validate() error {
+	if c.MaxPackWeightGrams < 0 || c.MaxWeightGrams < 0 || c.MaxVolumeMm3 < 0 || c.MaxPacks < 0 {
+		return ErrInvalidShipmentCaps
+	}
+	return nil
+}
+
+// Fits reports whether distribution ships in a single shipment within the
+// caps, given the measurements of each pack size
+func (c ShipmentCaps) Fits(
+	distribution PackDistribution,
+	measurements map[int]PackMeasurements,
+) bool {
+	return c.MinShipments(distribution, measurements) <= 1
+}
+
+// MinShipments returns the fewest shipments the caps allow distribution to
+// be split into. It is a lower bound: packs cannot be divided, so an
+// actual split may need more.
+func (c ShipmentCaps) MinShipments(
+	distribution PackDistribution,
+	measurements map[int]PackMeasurements,
+) int {
+	shipments := 1
+	if c.MaxPacks > 0 {
+		packs := int64(distribution.TotalPacks())
+		shipments = max(shipments, int(ceilDiv(packs, int64(c.MaxPacks))))
+	}
+	if c.MaxWeightGrams > 0 {
+		weight := distribution.WeightGrams(measurements)
+		shipments = max(shipments, int(ceilDiv(weight, c.MaxWeightGrams)))
+	}
+	if c.MaxVolumeMm3 > 0 {
+		volume := distribution.VolumeMm3(measurements)
+		shipments = max(shipments, int(ceilDiv(volume, c.MaxVolumeMm3)))
+	}
+	return shipments
+}
+
+// WeightGrams returns the total weight of the distribution
+func (pd PackDistribution) WeightGrams(measurements map[int]PackMeasurements) int64 {
+	total := int64(0)
+	for packSize, quantity := range pd {
+		total += int64(quantity) * measurements[packSize].WeightGrams
+	}
+	return total
+}
+
+// VolumeMm3 returns the total volume of the distribution
+func (pd PackDistribution) VolumeMm3(measurements map[int]PackMeasurements) int64 {
+	total := int64(0)
+	for packSize, quantity := range pd {
+		total += int64(quantity) * measurements[packSize].VolumeMm3()
+	}
+	return total
+}
+
+// ceilDiv divides a by b rounding up, for non-negative a and positive b
+func ceilDiv(a, b int64) int64 {
+	return (a + b - 1) / b
+}
+
+// ShipmentCapacity limits each shipment of a split order; zero disables a
+// limit, but at least one must be set
+type ShipmentCapacity struct {
+	MaxPacks int
+	MaxItems int
+}
+
+// Validate checks that a limit is set and none is negative
+func (c ShipmentCapacity) Validate() error {
+	if c.MaxPacks < 0 || c.MaxItems < 0 || c == (ShipmentCapacity{}) {
+		return ErrInvalidShipmentCapacity
+	}
+	return nil
+}
+
+// Shipment is one part of an order split across shipments. Its ID is the
+// calculation ID followed by the sequence number.
+type Shipment struct {
+	ID            string
+	CalculationID string
+	Sequence      int
+	Packs         PackDistribution
+	TotalItems    int
+	TotalPacks    int
+}
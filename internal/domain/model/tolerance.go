@@ -0,0 +1,42 @@
+package model
+
+// Tolerance lets an order ship fewer items than ordered. The shortfall is
+// capped by MaxShortfall items or by MaxShortfallPercent of the order, of
+// which at most one may be set, and MaxOverage caps the items shipped
+// beyond the order; zero disables a cap. The zero Tolerance ships at least
+// the order quantity.
+type Tolerance struct {
+	MaxShortfall        int
+	MaxShortfallPercent float64
+	MaxOverage          int
+}
+
+// IsZero reports whether the tolerance is unset
+func (t Tolerance) IsZero() bool {
+	return t == Tolerance{}
+}
+
+// Validate checks that no cap is negative, that the shortfall is given in
+// one unit only and that a percentage leaves part of the order to ship
+func (t Tolerance) Validate() error {
+	if t.MaxShortfall < 0 || t.MaxShortfallPercent < 0 || t.MaxOverage < 0 {
+		return ErrInvalidTolerance
+	}
+	if t.MaxShortfall > 0 && t.MaxShortfallPercent > 0 {
+		return ErrInvalidTolerance
+	}
+	if t.MaxShortfallPercent >= 100 {
+		return ErrInvalidTolerance
+	}
+	return nil
+}
+
+// Shortfall returns how many items short of orderQuantity may ship; at
+// least one item always ships
+func (t Tolerance) Shortfall(orderQuantity int) int {
+	shortfall := t.MaxShortfall
+	if t.MaxShortfallPercent > 0 {
+		shortfall = int(float64(orderQuantity) * t.MaxShortfallPercent / 100)
+	}
+	return min(shortfall, orderQuantity-1)
+}
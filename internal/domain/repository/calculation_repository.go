@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"pack-calculator/internal/domain/model"
+)
+
+// CalculationFilter narrows and pages the calculations returned by
+// CalculationRepository.List. Zero values leave a filter unset.
+type CalculationFilter struct {
+	MinOrderQuantity int
+	MaxOrderQuantity int
+	CreatedAfter     time.Time
+	CreatedBefore    time.Time
+	UserID           string
+	MinOverage       int
+
+	// Cursor continues a previous page; Limit caps the page size
+	Cursor string
+	Limit  int
+}
+
+// CalculationPage is one page of calculations, newest first. NextCursor is
+// empty on the last page.
+type CalculationPage struct {
+	Calculations []*model.Calculation
+	NextCursor   string
+}
+
+// CalculationRepository stores the history of completed calculations.
+// Lookups of unknown IDs return model.ErrCalculationNotFound and malformed
+// cursors return model.ErrInvalidCursor.
+type CalculationRepository interface {
+	Save(ctx context.Context, calculation *model.Calculation) error
+	GetByID(ctx context.Context, id string) (*model.Calculation, error)
+	List(ctx context.Context, filter CalculationFilter) (*CalculationPage, error)
+}
+
+// CalculationCursor is the keyset position of the last calculation on a
+// page; lists are ordered by creation time, then ID, both descending
+type CalculationCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// EncodeCursor serialises a cursor into an opaque token
+func EncodeCursor(cursor CalculationCursor) string {
+	raw := fmt.Sprintf("%d|%s", cursor.CreatedAt.UnixNano(), cursor.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a token produced by EncodeCursor
+func DecodeCursor(token string) (CalculationCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return CalculationCursor{}, model.ErrInvalidCursor
+	}
+
+	nanos, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return CalculationCursor{}, model.ErrInvalidCursor
+	}
+
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return CalculationCursor{}, model.ErrInvalidCursor
+	}
+
+	return CalculationCursor{CreatedAt: time.Unix(0, unixNano).UTC(), ID: id}, nil
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+
+	"pack-calculator/internal/domain/model"
+)
+
+// PackFilter narrows the packs returned by PackRepository.List
+type PackFilter struct {
+	ActiveOnly bool
+	// SKU restricts the packs to one product's catalog when set; the empty
+	// SKU is the default catalog
+	SKU *string
+}
+
+// PackRepository stores pack configurations.
+//
+// Create and Update return model.ErrPackAlreadyExists when another active
+// pack of the same SKU already has the same size, and lookups of unknown
+// IDs return model.ErrPackNotFound. Packs are never removed; they are deactivated.
+type PackRepository interface {
+	Create(ctx context.Context, pack *model.Pack) error
+	GetByID(ctx context.Context, id string) (*model.Pack, error)
+	List(ctx context.Context, filter PackFilter) ([]*model.Pack, error)
+	Update(ctx context.Context, pack *model.Pack) error
+}
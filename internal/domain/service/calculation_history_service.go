@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/domain/repository"
+)
+
+const (
+	// DefaultHistoryPageSize is used when a list request sets no limit
+	DefaultHistoryPageSize = 50
+	// MaxHistoryPageSize caps the page size of a single list request
+	MaxHistoryPageSize = 200
+	// MaxDemandCalculations caps how many calculations, newest first, make
+	// up a demand histogram
+	MaxDemandCalculations = 100000
+)
+
+// CalculationHistoryService looks up previously returned calculations
+type CalculationHistoryService struct {
+	repo repository.CalculationRepository
+}
+
+// NewCalculationHistoryService creates a calculation history service
+func NewCalculationHistoryService(
+	repo repository.CalculationRepository,
+) *CalculationHistoryService {
+	return &CalculationHistoryService{
+		repo: repo,
+	}
+}
+
+// Get returns a single calculation
+func (s *CalculationHistoryService) Get(
+	ctx context.Context,
+	id string,
+) (*model.Calculation, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// List returns a page of calculations, newest first. The page size is
+// clamped to MaxHistoryPageSize.
+func (s *CalculationHistoryService) List(
+	ctx context.Context,
+	filter repository.CalculationFilter,
+) (*repository.CalculationPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultHistoryPageSize
+	}
+	if filter.Limit > MaxHistoryPageSize {
+		filter.Limit = MaxHistoryPageSize
+	}
+	return s.repo.List(ctx, filter)
+}
+
+// Demand returns the histogram of the order quantities of the calculations
+// matching filter, counting at most MaxDemandCalculations of the newest.
+// The filter's cursor and limit are ignored.
+func (s *CalculationHistoryService) Demand(
+	ctx context.Context,
+	filter repository.CalculationFilter,
+) (model.DemandHistogram, error) {
+	filter.Cursor = ""
+	filter.Limit = MaxHistoryPageSize
+
+	orders := make(map[int]int)
+	counted := 0
+	for counted < MaxDemandCalculations {
+		page, err := s.repo.List(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, calculation := range page.Calculations {
+			if counted == MaxDemandCalculations {
+				break
+			}
+			orders[calculation.OrderQuantity]++
+			counted++
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+
+	histogram := make(model.DemandHistogram, 0, len(orders))
+	for quantity, count := range orders {
+		histogram = append(histogram, model.DemandBucket{Quantity: quantity, Orders: count})
+	}
+	return histogram.Merge(), nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/domain/repository"
+	"pack-calculator/internal/infrastructure/persistence"
+)
+
+func TestCalculationHistoryService(t *testing.T) {
+	repo := persistence.NewMemoryCalculationRepository(0)
+	packService := NewPackService(WithCalculationRepository(repo))
+	history := NewCalculationHistoryService(repo)
+
+	ctx := ContextWithUserID(context.Background(), "warehouse-7")
+	result, err := packService.CalculateOptimal(ctx, []int{250, 500, 1000}, 263)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	stored, err := history.Get(context.Background(), result.ID)
+	if err != nil {
+		t.Fatalf("Calculation was not recorded: %v", err)
+	}
+
+	if stored.UserID != "warehouse-7" || stored.TotalItems != result.TotalItems {
+		t.Errorf("Recorded calculation does not match: %+v", stored)
+	}
+
+	results, err := packService.CalculateBatch(context.Background(), []BatchItem{
+		{PackSizes: []int{250, 500}, OrderQuantity: 1},
+		{PackSizes: []int{250, 500}, OrderQuantity: 2},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	page, err := history.List(context.Background(), repository.CalculationFilter{})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	if len(page.Calculations) != 1+len(results) {
+		t.Errorf("Expected batch items to be recorded, got %d calculations", len(page.Calculations))
+	}
+
+	page, _ = history.List(context.Background(), repository.CalculationFilter{UserID: "warehouse-7"})
+	if len(page.Calculations) != 1 {
+		t.Errorf("Expected 1 calculation for warehouse-7, got %d", len(page.Calculations))
+	}
+}
+
+func TestCalculationHistoryService_Demand(t *testing.T) {
+	repo := persistence.NewMemoryCalculationRepository(0)
+	packService := NewPackService(WithCalculationRepository(repo))
+	history := NewCalculationHistoryService(repo)
+	ctx := context.Background()
+
+	// More calculations than fit on one history page
+	quantities := map[int]int{263: MaxHistoryPageSize, 501: 3, 12001: 1}
+	for quantity, count := range quantities {
+		for i := 0; i < count; i++ {
+			if _, err := packService.CalculateOptimal(ctx, []int{250, 500, 1000}, quantity); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+	}
+
+	demand, err := history.Demand(ctx, repository.CalculationFilter{Limit: 1})
+	if err != nil {
+		t.Fatalf("Demand failed: %v", err)
+	}
+
+	expected := model.DemandHistogram{
+		{Quantity: 263, Orders: MaxHistoryPageSize},
+		{Quantity: 501, Orders: 3},
+		{Quantity: 12001, Orders: 1},
+	}
+	if !reflect.DeepEqual(demand, expected) {
+		t.Errorf("Expected %v, got %v", expected, demand)
+	}
+
+	demand, err = history.Demand(ctx, repository.CalculationFilter{MinOrderQuantity: 500})
+	if err != nil {
+		t.Fatalf("Demand failed: %v", err)
+	}
+	if demand.TotalOrders() != 4 {
+		t.Errorf("Expected the 4 filtered orders, got %v", demand)
+	}
+}
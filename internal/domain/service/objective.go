@@ -0,0 +1,169 @@
+package service
+
+import (
+	"fmt"
+
+	"pack-calculator/internal/domain/model"
+)
+
+// Objective names accepted by NewObjective
+const (
+	ObjectiveLexicographic = "lexicographic"
+	ObjectiveMinCost       = "min_cost"
+	ObjectiveMinPacks      = "min_packs"
+)
+
+// Candidate is a reachable total together with the lowest summed pack cost
+// of shipping exactly that many items
+type Candidate struct {
+	TotalItems int
+	PackCost   int64
+}
+
+// Objective ranks the totals that fulfil an order. The solver finds the
+// lowest summed PackCost for every exact total and keeps the candidate
+// that no other is Less than. Pack costs must not be negative.
+type Objective interface {
+	// Name identifies the objective in requests and records
+	Name() string
+	// PackCost is the cost of using one pack of size
+	PackCost(size int) int64
+	// Less reports whether a fulfils orderQuantity better than b
+	Less(orderQuantity int, a, b Candidate) bool
+}
+
+// NewObjective returns the objective with the given name; an empty name is
+// Lexicographic. costs are only used by ObjectiveMinCost.
+func NewObjective(name string, costs model.CostModel) (Objective, error) {
+	switch name {
+	case "", ObjectiveLexicographic:
+		return Lexicographic(), nil
+	case ObjectiveMinPacks:
+		return MinPackCount(), nil
+	case ObjectiveMinCost:
+		if err := costs.Validate(); err != nil {
+			return nil, err
+		}
+		return MinTotalCost(costs), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", model.ErrUnknownObjective, name)
+	}
+}
+
+type lexicographicObjective struct{}
+
+// Lexicographic ships the fewest items (rule 2) and, among those, uses the
+// fewest packs (rule 3)
+func Lexicographic() Objective {
+	return lexicographicObjective{}
+}
+
+func (lexicographicObjective) Name() string { return ObjectiveLexicographic }
+
+func (lexicographicObjective) PackCost(int) int64 { return 1 }
+
+func (lexicographicObjective) Less(_ int, a, b Candidate) bool {
+	if a.TotalItems != b.TotalItems {
+		return a.TotalItems < b.TotalItems
+	}
+	return a.PackCost < b.PackCost
+}
+
+type minPackCountObjective struct{}
+
+// MinPackCount uses the fewest packs and, among those, ships the fewest
+// items
+func MinPackCount() Objective {
+	return minPackCountObjective{}
+}
+
+func (minPackCountObjective) Name() string { return ObjectiveMinPacks }
+
+func (minPackCountObjective) PackCost(int) int64 { return 1 }
+
+func (minPackCountObjective) Less(_ int, a, b Candidate) bool {
+	if a.PackCost != b.PackCost {
+		return a.PackCost < b.PackCost
+	}
+	return a.TotalItems < b.TotalItems
+}
+
+type minTotalCostObjective struct {
+	costs model.CostModel
+}
+
+// MinTotalCost minimises the cost of the overage items plus the handling
+// cost of every pack and, among equal costs, ships the fewest items
+func MinTotalCost(costs model.CostModel) Objective {
+	return minTotalCostObjective{costs: costs}
+}
+
+func (minTotalCostObjective) Name() string { return ObjectiveMinCost }
+
+func (o minTotalCostObjective) PackCost(size int) int64 {
+	return o.costs.HandlingCost[size]
+}
+
+func (o minTotalCostObjective) Less(orderQuantity int, a, b Candidate) bool {
+	costA := int64(a.TotalItems-orderQuantity)*o.costs.ItemCost + a.PackCost
+	costB := int64(b.TotalItems-orderQuantity)*o.costs.ItemCost + b.PackCost
+	if costA != costB {
+		return costA < costB
+	}
+	return a.TotalItems < b.TotalItems
+}
+
+// underFillObjective ranks totals on both sides of the order, weighing a
+// shortfall like an overage of as many items. Of two totals the wrapped
+// objective cannot tell apart, the one that fulfils the order wins.
+type underFillObjective struct {
+	Objective
+}
+
+func (o underFillObjective) Less(orderQuantity int, a, b Candidate) bool {
+	mirroredA, mirroredB := mirrorShortfall(orderQuantity, a), mirrorShortfall(orderQuantity, b)
+	if o.Objective.Less(orderQuantity, mirroredA, mirroredB) {
+		return true
+	}
+	if o.Objective.Less(orderQuantity, mirroredB, mirroredA) {
+		return false
+	}
+	return a.TotalItems > b.TotalItems
+}
+
+// mirrorShortfall returns c with a shortfall turned into the same overage
+func mirrorShortfall(orderQuantity int, c Candidate) Candidate {
+	if c.TotalItems < orderQuantity {
+		c.TotalItems = 2*orderQuantity - c.TotalItems
+	}
+	return c
+}
+
+// objectiveName returns the name of objective, defaulting to Lexicographic
+func objectiveName(objective Objective) string {
+	if objective == nil {
+		return ObjectiveLexicographic
+	}
+	return objective.Name()
+}
+
+// totalCost prices distribution when objective minimises cost
+func totalCost(objective Objective, distribution model.PackDistribution, orderQuantity int) int64 {
+	if o, ok := objective.(minTotalCostObjective); ok {
+		return o.costs.Cost(distribution, orderQuantity)
+	}
+	return 0
+}
+
+// hasUnitPackCost reports whether every pack costs one, so the solve can
+// use a pack count table
+func hasUnitPackCost(objective Objective, sizeSets ...[]int) bool {
+	for _, sizes := range sizeSets {
+		for _, size := range sizes {
+			if objective.PackCost(size) != 1 {
+				return false
+			}
+		}
+	}
+	return true
+}
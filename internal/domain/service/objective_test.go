@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"pack-calculator/internal/domain/model"
+)
+
+func TestNewObjective(t *testing.T) {
+	tests := []struct {
+		name        string
+		costs       model.CostModel
+		expected    string
+		expectedErr error
+	}{
+		{name: "", expected: ObjectiveLexicographic},
+		{name: ObjectiveLexicographic, expected: ObjectiveLexicographic},
+		{name: ObjectiveMinPacks, expected: ObjectiveMinPacks},
+		{name: ObjectiveMinCost, costs: model.CostModel{ItemCost: 1}, expected: ObjectiveMinCost},
+		{name: "cheapest", expectedErr: model.ErrUnknownObjective},
+		{
+			name:        ObjectiveMinCost,
+			costs:       model.CostModel{HandlingCost: map[int]int64{250: -1}},
+			expectedErr: model.ErrInvalidCost,
+		},
+	}
+
+	for _, tt := range tests {
+		objective, err := NewObjective(tt.name, tt.costs)
+		if tt.expectedErr != nil {
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("%q: expected %v, got %v", tt.name, tt.expectedErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tt.name, err)
+		}
+		if objective.Name() != tt.expected {
+			t.Errorf("%q: expected %s, got %s", tt.name, tt.expected, objective.Name())
+		}
+	}
+}
+
+func TestPackCalculator_SolveWithObjective(t *testing.T) {
+	calculator := NewPackCalculator()
+
+	tests := []struct {
+		name          string
+		packSizes     []int
+		orderQuantity int
+		opts          SolveOptions
+		expected      model.PackDistribution
+	}{
+		{
+			name:          "Lexicographic ships fewest items",
+			packSizes:     []int{3, 5},
+			orderQuantity: 9,
+			expected:      model.PackDistribution{3: 3},
+		},
+		{
+			name:          "Min packs accepts overage",
+			packSizes:     []int{3, 5},
+			orderQuantity: 9,
+			opts:          SolveOptions{Objective: MinPackCount()},
+			expected:      model.PackDistribution{5: 2},
+		},
+		{
+			name:          "Min cost avoids expensive packs",
+			packSizes:     []int{250, 1000},
+			orderQuantity: 1000,
+			opts: SolveOptions{Objective: MinTotalCost(model.CostModel{
+				HandlingCost: map[int]int64{250: 10, 1000: 500},
+			})},
+			expected: model.PackDistribution{250: 4},
+		},
+		{
+			name:          "Min cost trades overage for handling",
+			packSizes:     []int{250, 1000},
+			orderQuantity: 751,
+			opts: SolveOptions{Objective: MinTotalCost(model.CostModel{
+				ItemCost:     1,
+				HandlingCost: map[int]int64{250: 200, 1000: 200},
+			})},
+			expected: model.PackDistribution{1000: 1},
+		},
+		{
+			name:          "Min cost within stock",
+			packSizes:     []int{250, 1000},
+			orderQuantity: 1000,
+			opts: SolveOptions{
+				Stock: model.PackStock{250: 2},
+				Objective: MinTotalCost(model.CostModel{
+					HandlingCost: map[int]int64{250: 10, 1000: 500},
+				}),
+			},
+			expected: model.PackDistribution{1000: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calculator.Solve(
+				context.Background(), tt.packSizes, tt.orderQuantity, tt.opts,
+			)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+// TestPackCalculator_SolveWithObjective_BruteForce compares every objective
+// with exhaustive search on small random instances
+func TestPackCalculator_SolveWithObjective_BruteForce(t *testing.T) {
+	calculator := NewPackCalculator()
+	rng := rand.New(rand.NewSource(2))
+
+	for i := 0; i < 500; i++ {
+		packSizes, stock, orderQuantity := randomStockInstance(rng)
+		if rng.Intn(2) == 0 {
+			stock = nil
+		}
+
+		costs := model.CostModel{
+			ItemCost:     int64(rng.Intn(5)),
+			HandlingCost: make(map[int]int64),
+		}
+		for _, size := range packSizes {
+			costs.HandlingCost[size] = int64(rng.Intn(20))
+		}
+
+		for _, objective := range []Objective{MinPackCount(), MinTotalCost(costs)} {
+			opts := SolveOptions{Stock: stock, Objective: objective}
+			checkAgainstBruteForce(t, calculator, packSizes, orderQuantity, opts)
+		}
+	}
+}
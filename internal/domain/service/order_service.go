@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/domain/repository"
+	"pack-calculator/internal/infrastructure/logger"
+)
+
+// OrderService calculates orders against the stored pack configuration
+type OrderService struct {
+	packRepo    repository.PackRepository
+	packService *PackService
+}
+
+// StoredPackCalculation is a calculation together with the stored packs
+// whose sizes it was solved against
+type StoredPackCalculation struct {
+	Calculation *model.Calculation
+	Packs       []*model.Pack
+}
+
+// OrderOptions selects how an order is optimised
+type OrderOptions struct {
+	// Objective names the objective, see NewObjective; empty is
+	// lexicographic
+	Objective string
+	// ItemCost prices each overage item for the min_cost objective; pack
+	// handling costs come from the stored packs
+	ItemCost int64
+	// Alternatives is how many ranked distributions to list, see
+	// PackCalculator.Rank
+	Alternatives int
+	// Caps are the courier limits; pack weights and dimensions come from
+	// the stored packs
+	Caps model.ShipmentCaps
+	// Tolerance lets the order ship short of the quantity, see
+	// SolveOptions.Tolerance
+	Tolerance model.Tolerance
+}
+
+// NewOrderService creates an order service
+func NewOrderService(packRepo repository.PackRepository, packService *PackService) *OrderService {
+	return &OrderService{
+		packRepo:    packRepo,
+		packService: packService,
+	}
+}
+
+// Calculate solves orderQuantity using the currently active packs of the
+// default catalog, their stock, handling costs, measurements and
+// packaging. It returns ErrNoValidPacks when no pack is active.
+func (s *OrderService) Calculate(
+	ctx context.Context,
+	orderQuantity int,
+	opts OrderOptions,
+) (*StoredPackCalculation, error) {
+	defaultCatalog := ""
+	packs, err := s.packRepo.List(ctx, repository.PackFilter{
+		ActiveOnly: true,
+		SKU:        &defaultCatalog,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(packs) == 0 {
+		logger.Warn("No active packs configured", map[string]interface{}{
+			"order_quantity": orderQuantity,
+		})
+		return nil, model.ErrNoValidPacks
+	}
+
+	return s.calculate(ctx, packs, orderQuantity, opts)
+}
+
+// Plan solves every line of order against the active packs of its SKU,
+// like Calculate does for the default catalog, and sums up the order. It
+// returns ErrNoValidPacks naming the SKU when a product has no active
+// packs; a failing line fails the order.
+func (s *OrderService) Plan(ctx context.Context, order model.Order) (*model.OrderPlan, error) {
+	if err := order.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.packService.checkBatchSize(len(order.Lines)); err != nil {
+		return nil, err
+	}
+
+	packs, err := s.packRepo.List(ctx, repository.PackFilter{ActiveOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	catalogs := make(map[string][]*model.Pack)
+	for _, pack := range packs {
+		catalogs[pack.SKU] = append(catalogs[pack.SKU], pack)
+	}
+
+	plan := &model.OrderPlan{Lines: make([]model.OrderLinePlan, len(order.Lines))}
+	for i, line := range order.Lines {
+		catalog := catalogs[line.SKU]
+		if len(catalog) == 0 {
+			logger.Warn("No active packs configured for SKU", map[string]interface{}{
+				"sku":            line.SKU,
+				"order_quantity": line.Quantity,
+			})
+			return nil, fmt.Errorf("%w: sku %s", model.ErrNoValidPacks, line.SKU)
+		}
+
+		result, err := s.calculate(ctx, catalog, line.Quantity, OrderOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("sku %s: %w", line.SKU, err)
+		}
+
+		plan.Lines[i] = model.OrderLinePlan{
+			Line:        line,
+			Calculation: result.Calculation,
+			Packs:       result.Packs,
+		}
+		plan.OrderedItems += line.Quantity
+		plan.TotalItems += result.Calculation.TotalItems
+		plan.TotalPacks += result.Calculation.TotalPacks
+		plan.ItemsOverage += result.Calculation.ItemsOverage
+	}
+
+	logger.Debug("Order plan completed", map[string]interface{}{
+		"lines":         len(plan.Lines),
+		"total_items":   plan.TotalItems,
+		"total_packs":   plan.TotalPacks,
+		"items_overage": plan.ItemsOverage,
+	})
+
+	return plan, nil
+}
+
+// calculate solves orderQuantity against packs with their stock, handling
+// costs, measurements and packaging
+func (s *OrderService) calculate(
+	ctx context.Context,
+	packs []*model.Pack,
+	orderQuantity int,
+	opts OrderOptions,
+) (*StoredPackCalculation, error) {
+	packSizes := make([]int, len(packs))
+	stock := make(model.PackStock)
+	costs := model.CostModel{ItemCost: opts.ItemCost, HandlingCost: make(map[int]int64)}
+	measurements := make(map[int]model.PackMeasurements, len(packs))
+	packaging := make(map[int]model.Packaging)
+	rules := make(model.PackRules)
+	for i, pack := range packs {
+		packSizes[i] = pack.Size
+		if pack.Stock != nil {
+			stock[pack.Size] = *pack.Stock
+		}
+		costs.HandlingCost[pack.Size] = pack.HandlingCost
+		measurements[pack.Size] = pack.PackMeasurements
+		if len(pack.Packaging) > 0 {
+			packaging[pack.Size] = pack.Packaging
+		}
+		if !pack.Rule.IsZero() {
+			rules[pack.Size] = pack.Rule
+		}
+	}
+
+	objective, err := NewObjective(opts.Objective, costs)
+	if err != nil {
+		return nil, err
+	}
+
+	calculation, err := s.packService.CalculateWithOptions(ctx, packSizes, orderQuantity, SolveOptions{
+		Stock:        stock,
+		Objective:    objective,
+		Alternatives: opts.Alternatives,
+		Measurements: measurements,
+		Caps:         opts.Caps,
+		Packaging:    packaging,
+		Tolerance:    opts.Tolerance,
+		Rules:        rules,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &StoredPackCalculation{
+		Calculation: calculation,
+		Packs:       packs,
+	}, nil
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/infrastructure/persistence"
+)
+
+func TestOrderService_Calculate(t *testing.T) {
+	repo := persistence.NewMemoryPackRepository()
+	packConfig := NewPackConfigService(repo)
+	orders := NewOrderService(repo, NewPackService())
+	ctx := context.Background()
+
+	// No active packs yet
+	if _, err := orders.Calculate(ctx, 263, OrderOptions{}); !errors.Is(err, model.ErrNoValidPacks) {
+		t.Errorf("Expected ErrNoValidPacks, got %v", err)
+	}
+
+	for _, size := range []int{250, 500, 1000} {
+		if _, err := packConfig.Create(ctx, PackInput{Size: size, Name: "Pack"}); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	// Inactive packs are not used
+	large, _ := packConfig.Create(ctx, PackInput{Size: 5000, Name: "Large"})
+	if _, err := packConfig.Delete(ctx, large.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	result, err := orders.Calculate(ctx, 12001, OrderOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(result.Packs) != 3 {
+		t.Errorf("Expected 3 active packs, got %d", len(result.Packs))
+	}
+
+	distribution := result.Calculation.GetDistribution()
+	if distribution[5000] != 0 {
+		t.Errorf("Inactive pack size was used: %v", distribution)
+	}
+
+	if result.Calculation.TotalItems != 12250 {
+		t.Errorf("Expected 12250 items, got %d", result.Calculation.TotalItems)
+	}
+
+	// Stored stock limits the packs available
+	thousands := result.Packs[2]
+	stock := 2
+	_, err = packConfig.Update(ctx, thousands.ID, PackInput{Size: 1000, Name: "Pack", Stock: &stock})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	result, err = orders.Calculate(ctx, 12001, OrderOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if count := result.Calculation.GetDistribution()[1000]; count > stock {
+		t.Errorf("Expected at most %d packs of 1000, got %d", stock, count)
+	}
+	if result.Calculation.TotalItems != 12250 {
+		t.Errorf("Expected 12250 items, got %d", result.Calculation.TotalItems)
+	}
+
+	// Stored handling costs drive the min_cost objective
+	expensive := PackInput{Size: 1000, Name: "Pack", HandlingCost: 900}
+	if _, err := packConfig.Update(ctx, thousands.ID, expensive); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	result, err = orders.Calculate(ctx, 1000, OrderOptions{Objective: ObjectiveMinCost})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Calculation.Objective != ObjectiveMinCost || result.Calculation.TotalCost != 0 {
+		t.Errorf("Expected free packs to win, got %+v", result.Calculation)
+	}
+	if result.Calculation.GetDistribution()[1000] != 0 {
+		t.Errorf("Expensive pack was used: %v", result.Calculation.GetDistribution())
+	}
+
+	// Stored packaging rolls the packs up into cartons
+	cartons := PackInput{
+		Size:      1000,
+		Name:      "Pack",
+		Packaging: model.Packaging{{Name: "carton", Quantity: 4}},
+	}
+	if _, err := packConfig.Update(ctx, thousands.ID, cartons); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	result, err = orders.Calculate(ctx, 9000, OrderOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	packaging := result.Calculation.Packaging
+	if len(packaging) != 2 || packaging[0].Name != "carton" || packaging[0].Count != 2 ||
+		packaging[1].Name != model.PackUnitName || packaging[1].Count != 1 {
+		t.Errorf("Expected 2 cartons and 1 loose pack, got %+v", packaging)
+	}
+
+	invalid := PackInput{Size: 1000, Name: "Pack", Packaging: model.Packaging{{Name: "carton"}}}
+	_, err = packConfig.Update(ctx, thousands.ID, invalid)
+	if !errors.Is(err, model.ErrInvalidPackaging) {
+		t.Errorf("Expected ErrInvalidPackaging, got %v", err)
+	}
+
+	// Stored rules require the branded small pack
+	small := result.Packs[0]
+	branded := PackInput{Size: 250, Name: "Branded", Rule: model.PackRule{Required: true}}
+	if _, err := packConfig.Update(ctx, small.ID, branded); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	result, err = orders.Calculate(ctx, 1000, OrderOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if distribution := result.Calculation.GetDistribution(); distribution[250] != 2 ||
+		distribution[500] != 1 {
+		t.Errorf("Expected 2 packs of 250 and 1 of 500, got %v", distribution)
+	}
+
+	branded.Rule.MinOrderQuantity = 2000
+	if _, err := packConfig.Update(ctx, small.ID, branded); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	_, err = orders.Calculate(ctx, 1000, OrderOptions{})
+	if !errors.Is(err, model.ErrPackRulesNotMet) {
+		t.Errorf("Expected ErrPackRulesNotMet, got %v", err)
+	}
+
+	branded.Rule = model.PackRule{Required: true, Forbidden: true}
+	if _, err := packConfig.Update(ctx, small.ID, branded); !errors.Is(err, model.ErrInvalidPackRule) {
+		t.Errorf("Expected ErrInvalidPackRule, got %v", err)
+	}
+}
+
+func TestOrderService_Plan(t *testing.T) {
+	repo := persistence.NewMemoryPackRepository()
+	packConfig := NewPackConfigService(repo)
+	orders := NewOrderService(repo, NewPackService(WithLimits(Limits{MaxBatchSize: 3})))
+	ctx := context.Background()
+
+	catalogs := []PackInput{
+		{SKU: "WIDGET", Size: 250, Name: "Widget"},
+		{SKU: "WIDGET", Size: 500, Name: "Widget"},
+		{SKU: "GADGET", Size: 23, Name: "Gadget"},
+		{SKU: "GADGET", Size: 31, Name: "Gadget"},
+		{SKU: "GADGET", Size: 53, Name: "Gadget"},
+		{Size: 1000, Name: "Default"},
+	}
+	for _, input := range catalogs {
+		if _, err := packConfig.Create(ctx, input); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	order := model.Order{Lines: []model.OrderLine{
+		{SKU: "WIDGET", Quantity: 501},
+		{SKU: "GADGET", Quantity: 263},
+	}}
+	plan, err := orders.Plan(ctx, order)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(plan.Lines) != 2 ||
+		plan.Lines[0].Line.SKU != "WIDGET" || plan.Lines[1].Line.SKU != "GADGET" {
+		t.Fatalf("Expected lines in order, got %+v", plan.Lines)
+	}
+	if widgets := plan.Lines[0].Calculation; widgets.TotalItems != 750 {
+		t.Errorf("Expected 750 widgets, got %d", widgets.TotalItems)
+	}
+	if gadgets := plan.Lines[1].Calculation; gadgets.TotalItems != 263 {
+		t.Errorf("Expected 263 gadgets, got %d", gadgets.TotalItems)
+	}
+	if len(plan.Lines[1].Packs) != 3 {
+		t.Errorf("Expected the 3 gadget packs, got %d", len(plan.Lines[1].Packs))
+	}
+	if plan.OrderedItems != 764 || plan.TotalItems != 1013 || plan.ItemsOverage != 249 {
+		t.Errorf("Unexpected totals: %+v", plan)
+	}
+	if plan.TotalPacks != plan.Lines[0].Calculation.TotalPacks+plan.Lines[1].Calculation.TotalPacks {
+		t.Errorf("Expected total packs summed across lines, got %d", plan.TotalPacks)
+	}
+
+	// Packs with a SKU are not part of the default catalog
+	result, err := orders.Calculate(ctx, 501, OrderOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Packs) != 1 || result.Calculation.TotalItems != 1000 {
+		t.Errorf("Expected only the default pack, got %+v", result.Calculation)
+	}
+
+	tests := []struct {
+		name        string
+		order       model.Order
+		expectedErr error
+	}{
+		{
+			name:        "Empty order",
+			order:       model.Order{},
+			expectedErr: model.ErrEmptyOrder,
+		},
+		{
+			name:        "Missing SKU",
+			order:       model.Order{Lines: []model.OrderLine{{Quantity: 1}}},
+			expectedErr: model.ErrInvalidSKU,
+		},
+		{
+			name: "Duplicate SKU",
+			order: model.Order{Lines: []model.OrderLine{
+				{SKU: "WIDGET", Quantity: 1},
+				{SKU: "WIDGET", Quantity: 2},
+			}},
+			expectedErr: model.ErrDuplicateSKU,
+		},
+		{
+			name:        "Invalid quantity",
+			order:       model.Order{Lines: []model.OrderLine{{SKU: "WIDGET"}}},
+			expectedErr: model.ErrInvalidOrderQuantity,
+		},
+		{
+			name:        "Unknown SKU",
+			order:       model.Order{Lines: []model.OrderLine{{SKU: "GIZMO", Quantity: 1}}},
+			expectedErr: model.ErrNoValidPacks,
+		},
+		{
+			name: "Too many lines",
+			order: model.Order{Lines: []model.OrderLine{
+				{SKU: "A", Quantity: 1},
+				{SKU: "B", Quantity: 1},
+				{SKU: "C", Quantity: 1},
+				{SKU: "D", Quantity: 1},
+			}},
+			expectedErr: model.ErrBatchTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := orders.Plan(ctx, tt.order); !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Expected %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math"
 	"sort"
@@ -8,89 +10,513 @@ import (
 	"pack-calculator/internal/domain/model"
 )
 
+const (
+	// unreachable marks totals that cannot be composed from the given pack
+	// sizes in a pack count table
+	unreachable = math.MaxInt32
+
+	// cancelCheckInterval is how many table entries are filled between
+	// context checks; a power of two keeps the check to a mask
+	cancelCheckInterval = 1 << 16
+)
+
 type PackCalculator struct{}
 
 func NewPackCalculator() *PackCalculator {
 	return &PackCalculator{}
 }
 
+// Calculate returns the pack distribution that ships the fewest items
+// (rule 2) and, among those, uses the fewest packs (rule 3).
+//
+// The solver fills a bottom-up table of the minimum pack count needed to
+// reach every exact total up to orderQuantity+max(packSizes)-1. Any total
+// at or beyond that bound can have a pack removed and still fulfil the
+// order, so it is never optimal. Each reachable total stores the size of
+// the last pack added, which lets the winning distribution be rebuilt
+// without copying a distribution per state.
+//
+// The context is polled while the table is filled; once it is done the
+// solve stops and returns ErrCalculationTimeout or ErrCalculationCanceled
+// wrapping the context error.
 func (pc *PackCalculator) Calculate(
+	ctx context.Context,
 	packSizes []int,
 	orderQuantity int,
 ) (model.PackDistribution, error) {
-	if len(packSizes) == 0 {
-		return nil, model.ErrEmptyPackSizes
+	distributions, err := pc.CalculateMany(ctx, packSizes, []int{orderQuantity})
+	if err != nil {
+		return nil, err
+	}
+	return distributions[0], nil
+}
+
+// CalculateMany solves several order quantities against the same pack
+// sizes with a single table sized for the largest quantity, once whole
+// largest packs are set aside from quantities beyond the periodic cutoff.
+// Results are returned in the order of the given quantities.
+func (pc *PackCalculator) CalculateMany(
+	ctx context.Context,
+	packSizes []int,
+	orderQuantities []int,
+) ([]model.PackDistribution, error) {
+	if _, err := validateInput(packSizes, orderQuantities); err != nil {
+		return nil, err
 	}
-	if orderQuantity <= 0 {
-		return nil, model.ErrInvalidOrderQuantity
+
+	if err := ctx.Err(); err != nil {
+		return nil, contextError(err)
 	}
-	for _, size := range packSizes {
-		if size <= 0 {
-			return nil, model.ErrInvalidPackSize
+
+	// Quantities beyond the periodic cutoff are solved as their remainder
+	// with whole largest packs set aside, and the table counts in multiples
+	// of the GCD of the sizes
+	sizes := normalizePackSizes(packSizes)
+	scale := packSizesGCD(sizes)
+	reduced := scaleDown(sizes, scale)
+	shifts := make([]int, len(orderQuantities))
+	maxRemainder := 0
+	for i, quantity := range orderQuantities {
+		shifts[i] = periodicShift(sizes, quantity)
+		maxRemainder = max(maxRemainder, quantity-shifts[i]*sizes[0])
+	}
+	limit := ceilDiv(maxRemainder, scale) + reduced[0]
+	table, err := buildPackTable(ctx, reduced, unitCosts(reduced), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	distributions := make([]model.PackDistribution, len(orderQuantities))
+	for i, quantity := range orderQuantities {
+		remainder := ceilDiv(quantity-shifts[i]*sizes[0], scale)
+		distribution, err := table.distribution(remainder)
+		if err != nil {
+			return nil, err
+		}
+		distributions[i] = make(model.PackDistribution, len(distribution))
+		for size, count := range distribution {
+			distributions[i][size*scale] = count
+		}
+		if shifts[i] > 0 {
+			distributions[i][sizes[0]] += shifts[i]
 		}
 	}
+	return distributions, nil
+}
 
-	// Sort descending for consistency
-	sort.Sort(sort.Reverse(sort.IntSlice(packSizes)))
+// SolveOptions adjusts a solve beyond the pack sizes and order quantity
+type SolveOptions struct {
+	// Stock limits how many packs of a size may be used
+	Stock model.PackStock
+	// Objective ranks the distributions that fulfil the order; nil is
+	// Lexicographic
+	Objective Objective
+	// Alternatives is how many ranked distributions Rank returns; Solve
+	// ignores it
+	Alternatives int
+	// Measurements gives the weight and dimensions of a pack per size;
+	// sizes without an entry weigh nothing
+	Measurements map[int]model.PackMeasurements
+	// Caps removes sizes heavier than the parcel weight cap. Rank only
+	// lists distributions that fit a single shipment; Solve ignores the
+	// shipment caps.
+	Caps model.ShipmentCaps
+	// Packaging nests the packs of a size into outer units for the
+	// breakdown of the result; the solver ignores it
+	Packaging map[int]model.Packaging
+	// Tolerance lets Solve ship short of the order; it cannot be combined
+	// with ranked alternatives
+	Tolerance model.Tolerance
+	// Rules require, forbid and bound the packs of each size
+	Rules model.PackRules
+}
+
+// tooHeavy reports whether a pack of size exceeds the parcel weight cap
+func (o SolveOptions) tooHeavy(size int) bool {
+	limit := o.Caps.MaxPackWeightGrams
+	return limit > 0 && o.Measurements[size].WeightGrams > limit
+}
 
-	type state struct {
-		overage      int
-		packCount    int
-		distribution model.PackDistribution
+// validate checks the stock, measurements, caps, packaging, tolerance and
+// rules
+func (o SolveOptions) validate() error {
+	for _, available := range o.Stock {
+		if available < 0 {
+			return model.ErrInvalidPackStock
+		}
+	}
+	for _, measurements := range o.Measurements {
+		if err := measurements.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, packaging := range o.Packaging {
+		if err := packaging.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := o.Tolerance.Validate(); err != nil {
+		return err
 	}
+	if !o.Tolerance.IsZero() && o.Alternatives > 1 {
+		return fmt.Errorf("%w: alternatives cannot be ranked", model.ErrInvalidTolerance)
+	}
+	if err := o.Rules.Validate(); err != nil {
+		return err
+	}
+	return o.Caps.Validate()
+}
 
-	memo := make(map[int]state)
+// Solve returns the best distribution for orderQuantity under opts.
+//
+// Sizes missing from opts.Stock are unlimited and a stock of zero removes
+// the size; ErrInsufficientStock is returned when the stock cannot cover
+// the order. Sizes over the parcel weight cap are removed too, and
+// ErrPackTooHeavy is returned when none is left. The objective decides
+// which fulfilling total wins, given the lowest summed pack cost of
+// reaching each one. Any total at or beyond orderQuantity+max(packSizes)
+// can drop a pack and still fulfil the order at no higher cost, so only
+// the totals below are considered.
+//
+// A tolerance widens the totals to the allowed shortfall below the order
+// and narrows them to the allowed overage above it. The objective then
+// ranks a shortfall like an overage of as many items, preferring the total
+// that fulfils the order between two it cannot otherwise tell apart, and
+// ErrToleranceNotMet is returned when no total is within the tolerance.
+//
+// Pack rules drop the sizes they do not allow for the order and lower the
+// stock to the maximum counts. The packs of the minimum counts are set
+// aside and the rest of the order is solved around them, ranking each
+// total with those packs added. ErrPackRulesNotMet names the rule that
+// leaves no distribution.
+//
+// Once the order is far beyond the largest size and that size is
+// unlimited, every extra pack of it just shifts the solve, so under an
+// objective with unit pack costs and without a tolerance whole largest
+// packs are set aside and only the remainder is solved; see
+// periodicCutoff. The work then no longer grows with the order quantity.
+//
+// Every reachable total is a multiple of the GCD of the sizes, so the
+// layers are built for the sizes divided by it and only hold the
+// multiples; the work shrinks by the GCD with the same optimum.
+func (pc *PackCalculator) Solve(
+	ctx context.Context,
+	packSizes []int,
+	orderQuantity int,
+	opts SolveOptions,
+) (model.PackDistribution, error) {
+	if _, err := validateInput(packSizes, []int{orderQuantity}); err != nil {
+		return nil, err
+	}
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	objective := opts.Objective
+	if objective == nil {
+		objective = Lexicographic()
+	}
+	if !opts.Tolerance.IsZero() {
+		objective = underFillObjective{objective}
+	}
+	ruled, err := applyRules(packSizes, orderQuantity, opts)
+	if err != nil {
+		return nil, err
+	}
+	forcedItems := ruled.forced.TotalItems()
+	minTotal := max(orderQuantity-opts.Tolerance.Shortfall(orderQuantity)-forcedItems, 0)
 
-	var dfs func(int) state
-	dfs = func(remaining int) state {
-		if val, ok := memo[remaining]; ok {
-			return val
+	var unlimited, limited []int
+	light := false
+	for _, size := range ruled.sizes {
+		if opts.tooHeavy(size) {
+			continue
+		}
+		light = true
+		available, ok := ruled.stock[size]
+		switch {
+		case !ok:
+			unlimited = append(unlimited, size)
+		case available > 0:
+			limited = append(limited, size)
 		}
+	}
+	if !light {
+		return nil, model.ErrPackTooHeavy
+	}
+	if len(unlimited) == 0 && len(limited) == 0 && len(ruled.forced) == 0 {
+		return nil, model.ErrInsufficientStock
+	}
 
-		if remaining <= 0 {
-			return state{
-				overage:      -remaining,
-				packCount:    0,
-				distribution: make(model.PackDistribution),
+	if err := ctx.Err(); err != nil {
+		return nil, contextError(err)
+	}
+
+	largest := 0
+	if len(unlimited) > 0 {
+		largest = unlimited[0]
+	}
+	if len(limited) > 0 && limited[0] > largest {
+		largest = limited[0]
+	}
+	if len(unlimited) == 0 {
+		if capacity := stockCapacity(limited, ruled.stock, minTotal); capacity < minTotal {
+			if ruled.narrowed {
+				return nil, fmt.Errorf("%w: the allowed packs ship at most %d items",
+					model.ErrPackRulesNotMet, capacity+forcedItems)
 			}
+			return nil, model.ErrInsufficientStock
 		}
+	}
+
+	// Far beyond the largest pack the solve repeats itself with one more
+	// largest pack, so whole largest packs are set aside and only the
+	// remainder is solved; see periodicCutoff
+	shift := 0
+	if len(unlimited) > 0 && unlimited[0] == largest &&
+		periodicObjective(objective, opts, unlimited, limited) {
+		sizes := normalizePackSizes(append(append([]int(nil), unlimited...), limited...))
+		shift = periodicShift(sizes, minTotal)
+		orderQuantity -= shift * largest
+		minTotal -= shift * largest
+	}
+
+	// The table covers the totals left after the forced packs
+	limit := max(orderQuantity+largest-forcedItems, 1)
+	capped := false
+	// With every size used up by the forced packs, only they ship, so the
+	// cap applies whatever the largest size
+	if maxOverage := opts.Tolerance.MaxOverage; maxOverage > 0 &&
+		(maxOverage < largest || largest == 0) {
+		limit = orderQuantity + maxOverage + 1 - forcedItems
+		capped = true
+		if limit <= 0 {
+			return nil, fmt.Errorf("%w: the required packs ship %d items, beyond the overage cap",
+				model.ErrPackRulesNotMet, forcedItems)
+		}
+	}
 
-		best := state{
-			overage:   math.MaxInt32,
-			packCount: math.MaxInt32,
+	// Every total is a multiple of the GCD of the sizes, so the layers
+	// only hold the multiples
+	scale := packSizesGCD(unlimited, limited)
+	stock := make(model.PackStock, len(limited))
+	for _, size := range limited {
+		stock[size/scale] = ruled.stock[size]
+	}
+	problem := layeredProblem{
+		scale:         scale,
+		unlimited:     scaleDown(unlimited, scale),
+		limited:       scaleDown(limited, scale),
+		stock:         stock,
+		objective:     objective,
+		orderQuantity: orderQuantity,
+		forced:        ruled.forced,
+		forcedItems:   forcedItems,
+		forcedCost:    ruled.forcedCost(objective),
+		minTotal:      ceilDiv(minTotal, scale),
+		limit:         ceilDiv(limit, scale),
+		capped:        capped,
+	}
+	var distribution model.PackDistribution
+	if hasUnitPackCost(objective, unlimited, limited) {
+		distribution, err = solveLayered[int32](ctx, problem)
+	} else {
+		distribution, err = solveLayered[int64](ctx, problem)
+	}
+	if errors.Is(err, model.ErrInsufficientStock) && ruled.narrowed {
+		return nil, fmt.Errorf("%w: the allowed packs cannot cover the order",
+			model.ErrPackRulesNotMet)
+	}
+	if err == nil && shift > 0 {
+		distribution[largest] += shift
+	}
+	return distribution, err
+}
+
+// validateInput checks the pack sizes and order quantities of a solve and
+// returns the largest quantity
+func validateInput(packSizes []int, orderQuantities []int) (int, error) {
+	if len(packSizes) == 0 {
+		return 0, model.ErrEmptyPackSizes
+	}
+	if len(orderQuantities) == 0 {
+		return 0, model.ErrInvalidOrderQuantity
+	}
+	maxQuantity := 0
+	for _, quantity := range orderQuantities {
+		if quantity <= 0 {
+			return 0, model.ErrInvalidOrderQuantity
+		}
+		if quantity > maxQuantity {
+			maxQuantity = quantity
 		}
+	}
+	largest := 0
+	for _, size := range packSizes {
+		if size <= 0 {
+			return 0, model.ErrInvalidPackSize
+		}
+		largest = max(largest, size)
+	}
+	// Every total up to a largest pack beyond the order must fit an int
+	if limit := math.MaxInt - largest; maxQuantity > limit {
+		return 0, fmt.Errorf("%w: %d > %d", model.ErrOrderTooLarge, maxQuantity, limit)
+	}
+	return maxQuantity, nil
+}
 
-		for _, size := range packSizes {
-			sub := dfs(remaining - size)
+// tableCost is the value a table minimises for every exact total: a pack
+// count, kept in int32 to halve the table, or a summed pack cost
+type tableCost interface {
+	int32 | int64
+}
+
+// unreachableCost marks totals that cannot be composed from the pack sizes
+func unreachableCost[C tableCost]() C {
+	var c C
+	if _, ok := any(c).(int32); ok {
+		v := int64(unreachable)
+		return C(v)
+	}
+	v := int64(math.MaxInt64)
+	return C(v)
+}
+
+// packTable holds the lowest cost of reaching every exact total below
+// limit, together with the back-pointers to rebuild it
+type packTable[C tableCost] struct {
+	sizes []int
+	// cost[t] is the lowest summed pack cost of exactly t, last[t] the size
+	// of the final pack on that path
+	cost []C
+	last []int32
+}
+
+// unitCosts returns a cost of one per pack for each size
+func unitCosts(sizes []int) []int32 {
+	costs := make([]int32, len(sizes))
+	for i := range costs {
+		costs[i] = 1
+	}
+	return costs
+}
 
-			newDist := make(model.PackDistribution)
-			for k, v := range sub.distribution {
-				newDist[k] = v
+// buildPackTable fills the table for every total below limit; costs[i] is
+// the cost of one pack of sizes[i]. The table answers any order quantity
+// up to limit-sizes[0]. Sizes must already be normalised.
+func buildPackTable[C tableCost](
+	ctx context.Context,
+	sizes []int,
+	costs []C,
+	limit int,
+) (*packTable[C], error) {
+	none := unreachableCost[C]()
+	table := &packTable[C]{
+		sizes: sizes,
+		cost:  make([]C, limit),
+		last:  make([]int32, limit),
+	}
+
+	done := ctx.Done()
+	for t := 1; t < limit; t++ {
+		if t&(cancelCheckInterval-1) == 0 {
+			select {
+			case <-done:
+				return nil, contextError(ctx.Err())
+			default:
 			}
-			newDist[size]++
-
-			newPackCount := sub.packCount + 1
-			newOverage := sub.overage
-
-			if newOverage < best.overage ||
-				(newOverage == best.overage && newPackCount < best.packCount) {
-				best = state{
-					overage:      newOverage,
-					packCount:    newPackCount,
-					distribution: newDist,
-				}
+		}
+
+		best := none
+		bestSize := int32(0)
+		// Sizes are descending, so ties favour the larger pack
+		for i, size := range sizes {
+			if size > t {
+				continue
+			}
+			prev := table.cost[t-size]
+			if prev == none {
+				continue
+			}
+			if prev+costs[i] < best {
+				best = prev + costs[i]
+				bestSize = int32(size)
 			}
 		}
+		table.cost[t] = best
+		table.last[t] = bestSize
+	}
+
+	return table, nil
+}
+
+// distribution rebuilds the distribution with the fewest items, then the
+// lowest cost, for orderQuantity
+func (t *packTable[C]) distribution(orderQuantity int) (model.PackDistribution, error) {
+	none := unreachableCost[C]()
+	// Rule 2: the smallest reachable total that covers the order
+	for total := orderQuantity; total < orderQuantity+t.sizes[0]; total++ {
+		if t.cost[total] != none {
+			return t.rebuild(total), nil
+		}
+	}
+	return nil, model.ErrCalculationFailed
+}
 
-		memo[remaining] = best
-		return best
+// rebuild follows the back-pointers from total to the distribution
+func (t *packTable[C]) rebuild(total int) model.PackDistribution {
+	distribution := make(model.PackDistribution)
+	for remaining := total; remaining > 0; remaining -= int(t.last[remaining]) {
+		distribution[int(t.last[remaining])]++
 	}
+	return distribution
+}
 
-	result := dfs(orderQuantity)
+// normalizePackSizes returns a deduplicated copy of sizes sorted descending,
+// leaving the caller's slice untouched
+func normalizePackSizes(packSizes []int) []int {
+	sizes := make([]int, 0, len(packSizes))
+	seen := make(map[int]bool, len(packSizes))
+	for _, size := range packSizes {
+		if !seen[size] {
+			seen[size] = true
+			sizes = append(sizes, size)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+	return sizes
+}
 
-	if !result.distribution.CanFulfill(orderQuantity) {
-		return nil, fmt.Errorf("unable to fulfill order")
+// packSizesGCD returns the GCD of every size in sizeSets, or 1 when there
+// are none
+func packSizesGCD(sizeSets ...[]int) int {
+	divisor := 0
+	for _, sizes := range sizeSets {
+		for _, size := range sizes {
+			divisor = gcd(divisor, size)
+		}
 	}
+	return max(divisor, 1)
+}
 
-	return result.distribution, nil
+// scaleDown returns a copy of sizes divided by divisor
+func scaleDown(sizes []int, divisor int) []int {
+	scaled := make([]int, len(sizes))
+	for i, size := range sizes {
+		scaled[i] = size / divisor
+	}
+	return scaled
+}
+
+// ceilDiv returns n/d rounded up for non-negative n and positive d
+func ceilDiv(n, d int) int {
+	return n/d + min(n%d, 1)
+}
+
+// contextError translates a context error into the matching domain error
+func contextError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", model.ErrCalculationTimeout, err)
+	}
+	return fmt.Errorf("%w: %w", model.ErrCalculationCanceled, err)
 }
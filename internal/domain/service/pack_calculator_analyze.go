@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+
+	"pack-calculator/internal/domain/model"
+)
+
+// MaxOverageCurvePoints bounds the order quantities an analysis solves
+const MaxOverageCurvePoints = 10000
+
+// AnalyzeOptions picks the order quantities of an overage curve: From to
+// To in steps of Step, both ends included when the step lands on To
+type AnalyzeOptions struct {
+	// From is the first quantity; zero is 1
+	From int
+	// To is the last quantity; zero is From plus twice the largest size,
+	// less one
+	To int
+	// Step is the gap between quantities; zero is the smallest step that
+	// keeps the curve within MaxOverageCurvePoints
+	Step int
+}
+
+// last returns the last quantity of the curve for sizes whose largest is
+// largest
+func (o AnalyzeOptions) last(largest int) int {
+	if o.To > 0 {
+		return o.To
+	}
+	return addCapped(max(o.From, 1), mulCapped(largest, 2)-1)
+}
+
+// quantities returns the order quantities of the curve for sizes whose
+// largest is largest
+func (o AnalyzeOptions) quantities(largest int) ([]int, error) {
+	if o.From < 0 || o.To < 0 || o.Step < 0 {
+		return nil, model.ErrInvalidQuantityRange
+	}
+	from, to := max(o.From, 1), o.last(largest)
+	if to < from {
+		return nil, fmt.Errorf("%w: %d > %d", model.ErrInvalidQuantityRange, from, to)
+	}
+
+	span := to - from
+	step := o.Step
+	if step == 0 {
+		step = span/MaxOverageCurvePoints + 1
+	}
+	if points := span/step + 1; points > MaxOverageCurvePoints {
+		return nil, fmt.Errorf("%w: %d > %d",
+			model.ErrTooManyCurvePoints, points, MaxOverageCurvePoints)
+	}
+
+	quantities := make([]int, 0, span/step+1)
+	for q := from; ; q += step {
+		quantities = append(quantities, q)
+		if q > to-step {
+			break
+		}
+	}
+	return quantities, nil
+}
+
+// Analyze reports the GCD of packSizes, the largest multiple of it the
+// sizes cannot reach exactly, the sizes the smaller sizes add up to, and
+// the overage of the optimal distribution across the quantities of opts.
+//
+// The sizes are divided by their GCD first. The smallest reachable total
+// in every residue modulo the smallest size then follows from the
+// round-robin algorithm of Böcker and Lipták, adding one size at a time in
+// ascending order, and the Frobenius number is the largest of them less
+// the smallest size. Before a size is added, it is redundant exactly when
+// the smallest reachable total in its residue does not exceed it. The work
+// grows with the smallest size times the number of sizes, not with the
+// totals; the curve is solved like CalculateMany.
+func (pc *PackCalculator) Analyze(
+	ctx context.Context,
+	packSizes []int,
+	opts AnalyzeOptions,
+) (*model.PackSetAnalysis, error) {
+	if _, err := validateInput(packSizes, []int{1}); err != nil {
+		return nil, err
+	}
+	sizes := normalizePackSizes(packSizes)
+	quantities, err := opts.quantities(sizes[0])
+	if err != nil {
+		return nil, err
+	}
+
+	scale := packSizesGCD(sizes)
+	frobenius, redundant, err := residueAnalysis(ctx, scaleDown(sizes, scale))
+	if err != nil {
+		return nil, err
+	}
+	distributions, err := pc.CalculateMany(ctx, sizes, quantities)
+	if err != nil {
+		return nil, err
+	}
+
+	analysis := &model.PackSetAnalysis{
+		PackSizes:       sizes,
+		GCD:             scale,
+		FrobeniusNumber: max(frobenius, 0) * scale,
+		RedundantSizes:  make([]int, len(redundant)),
+		OverageCurve:    make([]model.OveragePoint, len(quantities)),
+	}
+	for i, size := range redundant {
+		analysis.RedundantSizes[i] = size * scale
+	}
+	for i, quantity := range quantities {
+		items := distributions[i].TotalItems()
+		analysis.OverageCurve[i] = model.OveragePoint{
+			OrderQuantity: quantity,
+			TotalItems:    items,
+			TotalPacks:    distributions[i].TotalPacks(),
+			Overage:       items - quantity,
+		}
+	}
+	return analysis, nil
+}
+
+// residueAnalysis returns the Frobenius number of the normalised sizes,
+// whose GCD must be 1, and the sizes the smaller sizes add up to, largest
+// first. The Frobenius number is -1 when the smallest size is 1.
+func residueAnalysis(ctx context.Context, sizes []int) (int, []int, error) {
+	smallest := sizes[len(sizes)-1]
+	// residues[r] is the smallest reachable total congruent to r
+	residues := make([]int, smallest)
+	for r := 1; r < smallest; r++ {
+		residues[r] = math.MaxInt
+	}
+
+	done := ctx.Done()
+	step := 0
+	var redundant []int
+	for i := len(sizes) - 2; i >= 0; i-- {
+		size := sizes[i]
+		if residues[size%smallest] <= size {
+			redundant = append(redundant, size)
+			continue
+		}
+
+		// Adding size links the residues into cycles of r, r+size, ...
+		// modulo the smallest size. Each cycle is walked once from its
+		// smallest total, which adding size cannot lower.
+		cycles := gcd(smallest, size)
+		offset := size % smallest
+		length := smallest / cycles
+		for start := 0; start < cycles; start++ {
+			current := math.MaxInt
+			r := start
+			for q := start; q < smallest; q += cycles {
+				if residues[q] < current {
+					current, r = residues[q], q
+				}
+			}
+			if current == math.MaxInt {
+				continue
+			}
+
+			for j := 1; j < length; j++ {
+				if step++; step&(cancelCheckInterval-1) == 0 {
+					select {
+					case <-done:
+						return 0, nil, contextError(ctx.Err())
+					default:
+					}
+				}
+
+				r = (r + offset) % smallest
+				current = min(addCapped(current, size), residues[r])
+				residues[r] = current
+			}
+		}
+	}
+
+	// Redundant sizes were found smallest first
+	for i, j := 0, len(redundant)-1; i < j; i, j = i+1, j-1 {
+		redundant[i], redundant[j] = redundant[j], redundant[i]
+	}
+	return slices.Max(residues) - smallest, redundant, nil
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"pack-calculator/internal/domain/model"
+)
+
+func TestPackCalculator_Analyze(t *testing.T) {
+	calculator := NewPackCalculator()
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		packSizes []int
+		gcd       int
+		frobenius int
+		redundant []int
+	}{
+		{"Chicken nuggets", []int{6, 9, 20}, 1, 43, []int{}},
+		{"Coprime pair", []int{7, 11}, 1, 59, []int{}},
+		{"Common divisor", []int{1000, 250, 500}, 250, 0, []int{1000, 500}},
+		{"Divisor with a gap", []int{10, 15}, 5, 5, []int{}},
+		{"Sum of smaller sizes", []int{4, 6, 10, 12}, 2, 2, []int{12, 10}},
+		{"Unit size", []int{1, 7}, 1, 0, []int{7}},
+		{"Single size", []int{250}, 250, 0, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis, err := calculator.Analyze(ctx, tt.packSizes, AnalyzeOptions{})
+			if err != nil {
+				t.Fatalf("Analyze: %v", err)
+			}
+			if analysis.GCD != tt.gcd {
+				t.Errorf("Expected GCD %d, got %d", tt.gcd, analysis.GCD)
+			}
+			if analysis.FrobeniusNumber != tt.frobenius {
+				t.Errorf("Expected Frobenius number %d, got %d", tt.frobenius, analysis.FrobeniusNumber)
+			}
+			if len(analysis.RedundantSizes) != len(tt.redundant) ||
+				len(tt.redundant) > 0 && !reflect.DeepEqual(analysis.RedundantSizes, tt.redundant) {
+				t.Errorf("Expected redundant sizes %v, got %v", tt.redundant, analysis.RedundantSizes)
+			}
+
+			// The default curve runs to twice the largest size
+			largest := analysis.PackSizes[0]
+			if len(analysis.OverageCurve) != 2*largest {
+				t.Fatalf("Expected %d points, got %d", 2*largest, len(analysis.OverageCurve))
+			}
+			for _, point := range analysis.OverageCurve {
+				expected, err := calculator.Solve(ctx, tt.packSizes, point.OrderQuantity, SolveOptions{})
+				if err != nil {
+					t.Fatalf("Solve(%d): %v", point.OrderQuantity, err)
+				}
+				if point.TotalItems != expected.TotalItems() ||
+					point.TotalPacks != expected.TotalPacks() ||
+					point.Overage != point.TotalItems-point.OrderQuantity {
+					t.Fatalf("Expected %v for %d, got %+v", expected, point.OrderQuantity, point)
+				}
+			}
+		})
+	}
+
+	t.Run("Matches reachability", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(25))
+		for i := 0; i < 300; i++ {
+			packSizes := make([]int, 1+rng.Intn(4))
+			for j := range packSizes {
+				packSizes[j] = 1 + rng.Intn(30)
+			}
+			analysis, err := calculator.Analyze(ctx, packSizes, AnalyzeOptions{To: 1})
+			if err != nil {
+				t.Fatalf("Analyze(%v): %v", packSizes, err)
+			}
+
+			sizes := normalizePackSizes(packSizes)
+			frobenius, redundant := reachability(sizes, analysis.GCD)
+			if analysis.FrobeniusNumber != frobenius {
+				t.Errorf("%v: expected Frobenius number %d, got %d",
+					packSizes, frobenius, analysis.FrobeniusNumber)
+			}
+			if len(analysis.RedundantSizes) != len(redundant) ||
+				len(redundant) > 0 && !reflect.DeepEqual(analysis.RedundantSizes, redundant) {
+				t.Errorf("%v: expected redundant sizes %v, got %v",
+					packSizes, redundant, analysis.RedundantSizes)
+			}
+		}
+	})
+
+	t.Run("Quantity range", func(t *testing.T) {
+		packSizes := []int{23, 31, 53}
+		tests := []struct {
+			name       string
+			opts       AnalyzeOptions
+			quantities []int
+			err        error
+		}{
+			{"Stepped", AnalyzeOptions{From: 10, To: 20, Step: 5}, []int{10, 15, 20}, nil},
+			{"Step past the end", AnalyzeOptions{From: 10, To: 21, Step: 5}, []int{10, 15, 20}, nil},
+			{"Single quantity", AnalyzeOptions{From: 263, To: 263}, []int{263}, nil},
+			{"Default step", AnalyzeOptions{To: 2 * MaxOverageCurvePoints},
+				[]int{1, 3, 2*MaxOverageCurvePoints - 1}, nil},
+			{"Backwards", AnalyzeOptions{From: 20, To: 10}, nil, model.ErrInvalidQuantityRange},
+			{"Negative step", AnalyzeOptions{Step: -1}, nil, model.ErrInvalidQuantityRange},
+			{"Too many points", AnalyzeOptions{To: MaxOverageCurvePoints + 1, Step: 1}, nil,
+				model.ErrTooManyCurvePoints},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				analysis, err := calculator.Analyze(ctx, packSizes, tt.opts)
+				if tt.err != nil {
+					if !errors.Is(err, tt.err) {
+						t.Errorf("Expected %v, got %v", tt.err, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Analyze: %v", err)
+				}
+
+				// Check the first, second and last quantities
+				curve := analysis.OverageCurve
+				got := []int{curve[0].OrderQuantity}
+				if len(curve) > 1 {
+					got = append(got, curve[1].OrderQuantity)
+				}
+				if len(curve) > 2 {
+					got = append(got, curve[len(curve)-1].OrderQuantity)
+				}
+				if !reflect.DeepEqual(got, tt.quantities) {
+					t.Errorf("Expected quantities %v, got %v", tt.quantities, got)
+				}
+			})
+		}
+	})
+
+	t.Run("Invalid pack sizes", func(t *testing.T) {
+		_, err := calculator.Analyze(ctx, nil, AnalyzeOptions{})
+		if !errors.Is(err, model.ErrEmptyPackSizes) {
+			t.Errorf("Expected ErrEmptyPackSizes, got %v", err)
+		}
+	})
+
+	t.Run("Cancellation", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := calculator.Analyze(canceled, []int{99991, 99989}, AnalyzeOptions{To: 1})
+		if !errors.Is(err, model.ErrCalculationCanceled) {
+			t.Errorf("Expected ErrCalculationCanceled, got %v", err)
+		}
+	})
+}
+
+func TestPackCalculator_SolveCommonDivisor(t *testing.T) {
+	calculator := NewPackCalculator()
+	rng := rand.New(rand.NewSource(250))
+
+	// The layers count in multiples of the GCD, which must not change the
+	// optimum for any option
+	for i := 0; i < 300; i++ {
+		packSizes, stock, q := randomStockInstance(rng)
+		scale := 2 + rng.Intn(4)
+		scaledStock := make(model.PackStock, len(stock))
+		handling := make(map[int]int64, len(packSizes))
+		for j := range packSizes {
+			if count, ok := stock[packSizes[j]]; ok {
+				scaledStock[packSizes[j]*scale] = count
+			}
+			packSizes[j] *= scale
+			handling[packSizes[j]] = int64(1 + rng.Intn(5))
+		}
+		q = q*scale - rng.Intn(scale)
+
+		for _, opts := range []SolveOptions{
+			{Stock: scaledStock},
+			{Stock: scaledStock, Objective: MinPackCount()},
+			{Objective: MinTotalCost(model.CostModel{ItemCost: 2, HandlingCost: handling})},
+		} {
+			checkAgainstBruteForce(t, calculator, packSizes, q, opts)
+		}
+	}
+
+	ctx := context.Background()
+	quantities := []int{1, 249, 251, 263, 12001}
+	distributions, err := calculator.CalculateMany(ctx, []int{500, 250, 1000}, quantities)
+	if err != nil {
+		t.Fatalf("CalculateMany: %v", err)
+	}
+	for i, q := range quantities {
+		expected, err := calculator.Solve(ctx, []int{250, 500, 1000}, q, SolveOptions{})
+		if err != nil {
+			t.Fatalf("Solve(%d): %v", q, err)
+		}
+		if !reflect.DeepEqual(distributions[i], expected) {
+			t.Errorf("Expected %v for %d, got %v", expected, q, distributions[i])
+		}
+	}
+}
+
+// reachability returns the Frobenius number of the normalised sizes with
+// GCD divisor, and the sizes the smaller sizes add up to, by marking every
+// reachable total
+func reachability(sizes []int, divisor int) (int, []int) {
+	// Schur's bound puts the Frobenius number below the smallest size
+	// times the largest
+	limit := sizes[0]*sizes[len(sizes)-1] + 1
+
+	redundant := []int{}
+	reachable := make([]bool, limit)
+	reachable[0] = true
+	for i := len(sizes) - 1; i >= 0; i-- {
+		size := sizes[i]
+		if reachable[size] {
+			redundant = append([]int{size}, redundant...)
+			continue
+		}
+		for t := size; t < limit; t++ {
+			reachable[t] = reachable[t] || reachable[t-size]
+		}
+	}
+
+	frobenius := 0
+	for t := divisor; t < limit; t += divisor {
+		if !reachable[t] {
+			frobenius = t
+		}
+	}
+	return frobenius, redundant
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"pack-calculator/internal/domain/model"
+)
+
+// explainCandidates is how many ranked distributions, the optimum
+// included, an explanation compares
+const explainCandidates = 6
+
+// Explain solves orderQuantity like Calculate and returns the evidence
+// that the result is optimal. The runner-ups come from Rank, and the pack
+// count table its search fills shows which totals are reachable and the
+// fewest packs for each.
+func (pc *PackCalculator) Explain(
+	ctx context.Context,
+	packSizes []int,
+	orderQuantity int,
+) (*model.Explanation, error) {
+	if _, err := validateInput(packSizes, []int{orderQuantity}); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, contextError(err)
+	}
+
+	ranked, search, err := pc.rank(ctx, packSizes, orderQuantity, SolveOptions{
+		Alternatives: explainCandidates,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if search == nil {
+		return nil, model.ErrCalculationFailed
+	}
+	// Every pack costs one under the default objective, so the table
+	// counts packs
+	table, none := search.table, unreachableCost[int64]()
+
+	e := &model.Explanation{
+		OrderQuantity: orderQuantity,
+		Distribution:  ranked[0],
+		MinimalTotal:  ranked[0].TotalItems(),
+		MinimalPacks:  ranked[0].TotalPacks(),
+		GCD:           packSizesGCD(search.sizes),
+	}
+	for total := orderQuantity - 1; total > 0; total-- {
+		if table.cost[total] != none {
+			e.Shortfall = table.rebuild(total)
+			break
+		}
+	}
+
+	nextTotal := false
+	for i, distribution := range ranked {
+		alternative := model.NewAlternative(distribution, orderQuantity)
+		if alternative.TotalItems == e.MinimalTotal {
+			e.EqualOverage = append(e.EqualOverage, alternative)
+		} else {
+			nextTotal = true
+		}
+		if i > 0 {
+			e.RunnerUps = append(e.RunnerUps, reject(e, alternative))
+		}
+	}
+
+	// Rank may fill up with distributions of the minimal total, so also
+	// reject the best one of the next total that no pack can be dropped from
+	for total := e.MinimalTotal + 1; !nextTotal && total < len(table.cost); total++ {
+		if table.cost[total] == none {
+			continue
+		}
+		distribution := table.rebuild(total)
+		if total-smallestSize(distribution) < orderQuantity {
+			alternative := model.NewAlternative(distribution, orderQuantity)
+			e.RunnerUps = append(e.RunnerUps, reject(e, alternative))
+			nextTotal = true
+		}
+	}
+
+	e.Steps = explanationSteps(e, search.sizes)
+	return e, nil
+}
+
+// reject states why the optimum of e beats a runner-up
+func reject(e *model.Explanation, alternative model.Alternative) model.Rejection {
+	if extra := alternative.TotalItems - e.MinimalTotal; extra > 0 {
+		return model.Rejection{
+			Alternative: alternative,
+			Rule:        2,
+			Reason:      fmt.Sprintf("ships %s more", plural(extra, "item")),
+		}
+	}
+	if extra := alternative.TotalPacks - e.MinimalPacks; extra > 0 {
+		return model.Rejection{
+			Alternative: alternative,
+			Rule:        3,
+			Reason:      fmt.Sprintf("ships as many items in %s more", plural(extra, "pack")),
+		}
+	}
+	return model.Rejection{
+		Alternative: alternative,
+		Rule:        3,
+		Reason:      "ships as many items in as many packs; larger packs are preferred",
+	}
+}
+
+// explanationSteps writes the argument for the optimum of e as sentences
+func explanationSteps(e *model.Explanation, sizes []int) []string {
+	var steps []string
+	if e.GCD > 1 {
+		steps = append(steps, fmt.Sprintf(
+			"Every pack size is a multiple of %d, so only multiples of %d items can be shipped",
+			e.GCD, e.GCD))
+	}
+	if e.MinimalTotal > e.OrderQuantity {
+		steps = append(steps, fmt.Sprintf(
+			"No combination of the pack sizes %v totals between %d and %d items",
+			sizes, e.OrderQuantity, e.MinimalTotal-1))
+	}
+	if e.Shortfall != nil {
+		total := e.Shortfall.TotalItems()
+		steps = append(steps, fmt.Sprintf(
+			"The largest total below the order is %d items, %s short",
+			total, plural(e.OrderQuantity-total, "item")))
+	}
+	steps = append(steps,
+		fmt.Sprintf("%d items is the smallest total that covers the order, an overage of %d (rule 2)",
+			e.MinimalTotal, e.MinimalTotal-e.OrderQuantity),
+		fmt.Sprintf("No combination of %d items uses fewer than %s (rule 3)",
+			e.MinimalTotal, plural(e.MinimalPacks, "pack")),
+	)
+	return steps
+}
+
+// smallestSize returns the smallest pack size used by distribution
+func smallestSize(distribution model.PackDistribution) int {
+	smallest := 0
+	for size, count := range distribution {
+		if count > 0 && (smallest == 0 || size < smallest) {
+			smallest = size
+		}
+	}
+	return smallest
+}
+
+// plural formats a count of noun, adding an s unless count is one
+func plural(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// gcd returns the greatest common divisor of a and b; gcd(0, b) is b
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
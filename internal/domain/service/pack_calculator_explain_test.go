@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"pack-calculator/internal/domain/model"
+)
+
+func TestPackCalculator_Explain(t *testing.T) {
+	e, err := NewPackCalculator().Explain(
+		context.Background(), []int{250, 500, 1000, 2000, 5000}, 12001,
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := model.PackDistribution{5000: 2, 2000: 1, 250: 1}
+	if !reflect.DeepEqual(e.Distribution, expected) {
+		t.Errorf("Expected distribution %v, got %v", expected, e.Distribution)
+	}
+	if e.MinimalTotal != 12250 || e.MinimalPacks != 4 || e.GCD != 250 {
+		t.Errorf("Expected 12250 items in 4 packs with GCD 250, got %+v", e)
+	}
+	if e.Shortfall.TotalItems() != 12000 {
+		t.Errorf("Expected a shortfall of 12000 items, got %v", e.Shortfall)
+	}
+
+	if len(e.EqualOverage) < 2 || !reflect.DeepEqual(e.EqualOverage[0].Distribution, e.Distribution) {
+		t.Fatalf("Expected the optimum to lead the equal overage candidates, got %+v", e.EqualOverage)
+	}
+	for _, candidate := range e.EqualOverage[1:] {
+		if candidate.TotalItems != 12250 || candidate.TotalPacks <= 4 {
+			t.Errorf("Expected 12250 items in more than 4 packs, got %+v", candidate)
+		}
+	}
+
+	last := e.RunnerUps[len(e.RunnerUps)-1]
+	if last.Rule != 2 || last.TotalItems != 12500 {
+		t.Errorf("Expected a rule 2 runner-up of 12500 items, got %+v", last)
+	}
+	for _, runnerUp := range e.RunnerUps[:len(e.RunnerUps)-1] {
+		if runnerUp.Rule != 3 || runnerUp.Reason == "" {
+			t.Errorf("Expected a rule 3 runner-up with a reason, got %+v", runnerUp)
+		}
+	}
+
+	if len(e.Steps) != 5 {
+		t.Errorf("Expected 5 steps, got %q", e.Steps)
+	}
+}
+
+// TestPackCalculator_Explain_BruteForce checks the minimal total and pack
+// count against exhaustive search
+func TestPackCalculator_Explain_BruteForce(t *testing.T) {
+	calculator := NewPackCalculator()
+	rng := rand.New(rand.NewSource(4))
+
+	for i := 0; i < 300; i++ {
+		packSizes, _, orderQuantity := randomStockInstance(rng)
+		expected, _ := bruteForce(packSizes, orderQuantity, nil, Lexicographic())
+
+		e, err := calculator.Explain(context.Background(), packSizes, orderQuantity)
+		if err != nil {
+			t.Fatalf("%v qty %d: unexpected error: %v", packSizes, orderQuantity, err)
+		}
+		if e.MinimalTotal != expected.TotalItems || int64(e.MinimalPacks) != expected.PackCost {
+			t.Fatalf("%v qty %d: expected %+v, got %d items in %d packs",
+				packSizes, orderQuantity, expected, e.MinimalTotal, e.MinimalPacks)
+		}
+		if e.Shortfall != nil && e.Shortfall.TotalItems() >= orderQuantity {
+			t.Fatalf("%v qty %d: shortfall %v covers the order", packSizes, orderQuantity, e.Shortfall)
+		}
+		for _, runnerUp := range e.RunnerUps {
+			if runnerUp.TotalItems < e.MinimalTotal ||
+				(runnerUp.TotalItems == e.MinimalTotal && runnerUp.TotalPacks < e.MinimalPacks) {
+				t.Fatalf("%v qty %d: runner-up %+v beats the optimum",
+					packSizes, orderQuantity, runnerUp)
+			}
+		}
+	}
+}
+
+func TestPackCalculator_Explain_InvalidInput(t *testing.T) {
+	_, err := NewPackCalculator().Explain(context.Background(), nil, 10)
+	if !errors.Is(err, model.ErrEmptyPackSizes) {
+		t.Errorf("Expected ErrEmptyPackSizes, got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import "math"
+
+// periodicCutoff returns the total from which the solve is periodic
+// in the largest of the normalised sizes, L: beyond it the fewest packs
+// reaching a total t satisfy packs(t+L) = packs(t)+1, and t+L is reachable
+// exactly when t is. A solve whose totals all start beyond the cutoff
+// ranks them like the solve of the order less L, with one more pack of
+// size L in each, so any number of largest packs can be set aside before
+// solving the remainder. math.MaxInt is returned when the cutoff does not
+// fit an int.
+//
+// The cutoff follows from a pigeonhole argument. With g the GCD of the
+// sizes, the prefix sums of any L/g packs smaller than L fall in at most
+// L/g residues modulo L, so some non-empty run of them sums to m*L with m
+// less than the run's length, and swapping the run for m packs of size L
+// uses fewer packs. A distribution with the fewest packs thus holds at
+// most L/g-1 smaller packs, and likewise fewer than L/gcd(L, s) packs of
+// each smaller size s. Either bound caps the items in smaller packs, and
+// every fewest-pack distribution of a total beyond the cap holds a pack of
+// size L. The swap only needs L unlimited, so the smaller sizes may be
+// limited by stock.
+func periodicCutoff(sizes []int) int {
+	if len(sizes) == 1 {
+		return 0
+	}
+	largest := sizes[0]
+	divisor := packSizesGCD(sizes)
+
+	byCount := mulCapped(largest/divisor-1, sizes[1])
+	bySize := 0
+	for _, size := range sizes[1:] {
+		bySize = addCapped(bySize, mulCapped(largest/gcd(largest, size)-1, size))
+	}
+	return min(byCount, bySize)
+}
+
+// periodicShift returns how many packs of the largest of the normalised
+// sizes can be set aside from a solve whose totals start at minTotal,
+// leaving totals that start between the cutoff and the cutoff plus the
+// largest size
+func periodicShift(sizes []int, minTotal int) int {
+	largest := sizes[0]
+	cutoff := periodicCutoff(sizes)
+	if cutoff > math.MaxInt-largest || minTotal < cutoff+largest {
+		return 0
+	}
+	return (minTotal - cutoff) / largest
+}
+
+// periodicObjective reports whether a solve under objective and opts can
+// set largest packs aside. The cutoff counts packs, so every pack must
+// cost one; the objective must rank two totals the same way after adding
+// the same items and pack cost to both and to the order, as every built-in
+// objective does; and a tolerance would scale with the order.
+func periodicObjective(objective Objective, opts SolveOptions, sizeSets ...[]int) bool {
+	return opts.Tolerance.IsZero() && hasUnitPackCost(objective, sizeSets...)
+}
+
+// SolvedQuantity returns the order quantity left for Solve, or Rank with a
+// single alternative, to walk once the periodic regime has set the largest
+// packs aside; it is orderQuantity itself when opts rule the regime out.
+// The rules may move the remainder Solve walks by up to two largest packs.
+func (pc *PackCalculator) SolvedQuantity(
+	packSizes []int,
+	orderQuantity int,
+	opts SolveOptions,
+) int {
+	if _, err := validateInput(packSizes, []int{orderQuantity}); err != nil {
+		return orderQuantity
+	}
+	objective := opts.Objective
+	if objective == nil {
+		objective = Lexicographic()
+	}
+	sizes := normalizePackSizes(packSizes)
+	// Ranking more alternatives, or around the shipment caps, walks every
+	// total
+	if opts.Alternatives > 1 || !opts.Caps.IsZero() ||
+		!periodicObjective(objective, opts, sizes) {
+		return orderQuantity
+	}
+
+	// Solve only sets packs aside while the largest size is unlimited
+	largest := sizes[0]
+	rule := opts.Rules[largest]
+	if _, ok := opts.Stock[largest]; ok || rule.MaxCount != nil || !rule.Allows(orderQuantity) {
+		return orderQuantity
+	}
+	return orderQuantity - periodicShift(sizes, orderQuantity)*largest
+}
+
+// mulCapped returns a*b for non-negative a and b, or math.MaxInt when the
+// product overflows
+func mulCapped(a, b int) int {
+	if a != 0 && b > math.MaxInt/a {
+		return math.MaxInt
+	}
+	return a * b
+}
+
+// addCapped returns a+b for non-negative a and b, or math.MaxInt when the
+// sum overflows
+func addCapped(a, b int) int {
+	if b > math.MaxInt-a {
+		return math.MaxInt
+	}
+	return a + b
+}
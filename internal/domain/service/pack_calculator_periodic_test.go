@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"pack-calculator/internal/domain/model"
+)
+
+func TestPeriodicCutoff(t *testing.T) {
+	tests := []struct {
+		name      string
+		packSizes []int
+		expected  int
+	}{
+		{"Single size", []int{7}, 0},
+		{"Coprime sizes", []int{53, 31, 23}, 52 * 31},
+		// 500 and 250 divide 1000, so fewer than 2 and 4 packs of them
+		{"Common divisor", []int{1000, 500, 250}, 1*500 + 3*250},
+		{"Chicken nuggets", []int{20, 9, 6}, 19 * 9},
+		{"Overflow", []int{math.MaxInt / 2, math.MaxInt/2 - 1}, math.MaxInt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := periodicCutoff(tt.packSizes); got != tt.expected {
+				t.Errorf("Expected cutoff %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestPackCalculator_SolvePeriodic(t *testing.T) {
+	calculator := NewPackCalculator()
+	ctx := context.Background()
+
+	t.Run("Optimal beyond the cutoff", func(t *testing.T) {
+		// The cutoff of 20, 9 and 6 is 171, so orders from 191 set 20s aside
+		packSizes := []int{20, 9, 6}
+		for _, opts := range []SolveOptions{
+			{},
+			{Objective: MinPackCount()},
+			{Stock: model.PackStock{9: 3, 6: 1}},
+			{Stock: model.PackStock{9: 0}},
+		} {
+			for q := 150; q <= 320; q++ {
+				checkAgainstBruteForce(t, calculator, packSizes, q, opts)
+			}
+		}
+
+		rng := rand.New(rand.NewSource(24))
+		for i := 0; i < 300; i++ {
+			packSizes, stock, _ := randomStockInstance(rng)
+			delete(stock, normalizePackSizes(packSizes)[0])
+			q := 1 + rng.Intn(300)
+			checkAgainstBruteForce(t, calculator, packSizes, q, SolveOptions{Stock: stock})
+		}
+	})
+
+	t.Run("Huge quantities", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			packSizes []int
+			opts      SolveOptions
+		}{
+			{"Lexicographic", []int{23, 31, 53}, SolveOptions{}},
+			{"Min packs", []int{250, 500, 1000, 2000, 5000}, SolveOptions{Objective: MinPackCount()}},
+			{"Limited smaller sizes", []int{23, 31, 53}, SolveOptions{Stock: model.PackStock{23: 1}}},
+			{"Required size", []int{23, 31, 53}, SolveOptions{
+				Rules: model.PackRules{31: {MinCount: 3}},
+			}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				largest := normalizePackSizes(tt.packSizes)[0]
+				quantities := []int{math.MaxInt - largest, math.MaxInt / 3, 1e15 + 7}
+				for _, q := range quantities {
+					start := time.Now()
+					result, err := calculator.Solve(ctx, tt.packSizes, q, tt.opts)
+					if err != nil {
+						t.Fatalf("Solve(%d): %v", q, err)
+					}
+					if elapsed := time.Since(start); elapsed > time.Second {
+						t.Errorf("Solve(%d) took %v", q, elapsed)
+					}
+
+					// The same solve with a million fewer largest packs
+					// ships the same remainder
+					smaller, err := calculator.Solve(ctx, tt.packSizes, q-1e6*largest, tt.opts)
+					if err != nil {
+						t.Fatalf("Solve(%d): %v", q-1e6*largest, err)
+					}
+					smaller[largest] += 1e6
+					if !reflect.DeepEqual(result, smaller) {
+						t.Errorf("Expected %v for %d, got %v", smaller, q, result)
+					}
+					if items := result.TotalItems(); items < q || items-q >= largest {
+						t.Errorf("Expected %d to %d items, got %d", q, q+largest-1, items)
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("Calculate many", func(t *testing.T) {
+		packSizes := []int{23, 31, 53}
+		quantities := []int{500000, math.MaxInt - 53, 263}
+		distributions, err := calculator.CalculateMany(ctx, packSizes, quantities)
+		if err != nil {
+			t.Fatalf("CalculateMany: %v", err)
+		}
+		for i, q := range quantities {
+			expected, err := calculator.Solve(ctx, packSizes, q, SolveOptions{})
+			if err != nil {
+				t.Fatalf("Solve(%d): %v", q, err)
+			}
+			if !reflect.DeepEqual(distributions[i], expected) {
+				t.Errorf("Expected %v for %d, got %v", expected, q, distributions[i])
+			}
+		}
+	})
+
+	t.Run("Totals beyond int", func(t *testing.T) {
+		_, err := calculator.Solve(ctx, []int{23, 31, 53}, math.MaxInt-52, SolveOptions{})
+		if !errors.Is(err, model.ErrOrderTooLarge) {
+			t.Errorf("Expected ErrOrderTooLarge, got %v", err)
+		}
+	})
+}
+
+func TestPackCalculator_SolvedQuantity(t *testing.T) {
+	calculator := NewPackCalculator()
+	packSizes := []int{250, 500, 1000}
+	minCost := MinTotalCost(model.CostModel{
+		ItemCost:     1,
+		HandlingCost: map[int]int64{250: 5, 500: 7, 1000: 9},
+	})
+
+	tests := []struct {
+		name          string
+		orderQuantity int
+		opts          SolveOptions
+		expected      int
+	}{
+		{"Below the cutoff", 2000, SolveOptions{}, 2000},
+		// The cutoff is 1250, so 1000s are set aside down to 1250-2249
+		{"Beyond the cutoff", 1000000, SolveOptions{}, 2000},
+		{"Limited smaller size", 1000000, SolveOptions{Stock: model.PackStock{250: 2}}, 2000},
+		{"Limited largest size", 1000000, SolveOptions{Stock: model.PackStock{1000: 2}}, 1000000},
+		{"Capped largest size", 1000000, SolveOptions{
+			Rules: model.PackRules{1000: {MaxCount: new(int)}},
+		}, 1000000},
+		{"Ranked alternatives", 1000000, SolveOptions{Alternatives: 2}, 1000000},
+		{"Tolerance", 1000000, SolveOptions{Tolerance: model.Tolerance{MaxShortfall: 10}}, 1000000},
+		{"Pack costs", 1000000, SolveOptions{Objective: minCost}, 1000000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calculator.SolvedQuantity(packSizes, tt.orderQuantity, tt.opts)
+			if got != tt.expected {
+				t.Errorf("Expected %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,411 @@
+package service
+
+import (
+	"container/heap"
+	"context"
+	"maps"
+
+	"pack-calculator/internal/domain/model"
+)
+
+// maxRankNodes bounds the partial distributions a ranking keeps, so pack
+// sets with very many near-equal distributions return fewer alternatives
+// instead of exhausting memory or the solve time
+const maxRankNodes = 1 << 20
+
+// Rank returns up to opts.Alternatives distinct distributions for
+// orderQuantity, best first under the objective; the first is the one
+// Solve returns. Only distributions from which no pack can be dropped
+// while still fulfilling the order are listed, since dropping the pack
+// would beat them under every objective. Fewer are returned when fewer
+// exist or the search budget runs out.
+//
+// With shipment caps only distributions that fit a single shipment are
+// listed, and ErrShipmentCapsExceeded is returned when none is found. With
+// a tolerance only the solved distribution is listed. Every distribution
+// keeps to the pack rules.
+//
+// Distributions are enumerated best-first, adding packs in descending size
+// order so that each one is reached exactly once. A partial distribution
+// is ranked by its best possible completion, read from an unbounded table
+// over every available size that ignores stock, which never overstates the
+// result. This relies on the objective ranking candidates the same way
+// after adding the same items and pack cost to both, as every built-in
+// objective does.
+func (pc *PackCalculator) Rank(
+	ctx context.Context,
+	packSizes []int,
+	orderQuantity int,
+	opts SolveOptions,
+) ([]model.PackDistribution, error) {
+	ranked, _, err := pc.rank(ctx, packSizes, orderQuantity, opts)
+	return ranked, err
+}
+
+// rank is Rank that also returns the search it ran, nil when the solved
+// distribution was enough
+func (pc *PackCalculator) rank(
+	ctx context.Context,
+	packSizes []int,
+	orderQuantity int,
+	opts SolveOptions,
+) ([]model.PackDistribution, *rankSearch, error) {
+	best, err := pc.Solve(ctx, packSizes, orderQuantity, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	want := max(opts.Alternatives, 1)
+	var ranked []model.PackDistribution
+	if opts.Caps.Fits(best, opts.Measurements) {
+		ranked = append(ranked, best)
+	}
+	if len(ranked) >= want {
+		return ranked, nil, nil
+	}
+	if !opts.Tolerance.IsZero() {
+		// The search below only reaches distributions that fulfil the order
+		return nil, nil, model.ErrShipmentCapsExceeded
+	}
+
+	objective := opts.Objective
+	if objective == nil {
+		objective = Lexicographic()
+	}
+
+	// Solve already accepted the rules
+	ruled, err := applyRules(packSizes, orderQuantity, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	var sizes []int
+	for _, size := range ruled.sizes {
+		if available, ok := ruled.stock[size]; (!ok || available > 0) && !opts.tooHeavy(size) {
+			sizes = append(sizes, size)
+		}
+	}
+	if len(sizes) == 0 {
+		// The forced packs are the only distribution
+		if len(ranked) == 0 {
+			return nil, nil, model.ErrShipmentCapsExceeded
+		}
+		return ranked, nil, nil
+	}
+
+	search, err := newRankSearch(ctx, sizes, orderQuantity, objective, opts, ruled)
+	if err != nil {
+		return nil, nil, err
+	}
+	for len(ranked) < want {
+		distribution, err := search.next(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		if distribution == nil {
+			break
+		}
+		if !maps.Equal(distribution, best) {
+			ranked = append(ranked, distribution)
+		}
+	}
+	if len(ranked) == 0 {
+		return nil, nil, model.ErrShipmentCapsExceeded
+	}
+	return ranked, search, nil
+}
+
+// rankNode is a partial distribution whose packs were added in descending
+// size order, ending with run packs of sizes[size]
+type rankNode struct {
+	parent int32
+	size   int32
+	run    int32
+	packs  int32
+	items  int
+	cost   int64
+	weight int64
+	volume int64
+	// bound is the best candidate any completion can reach; for a complete
+	// distribution it is the distribution itself
+	bound    Candidate
+	complete bool
+}
+
+// rankSearch enumerates complete distributions in objective order. Every
+// distribution starts from the packs the rules force, held by the root.
+type rankSearch struct {
+	sizes         []int
+	costs         []int64
+	stock         model.PackStock
+	forced        model.PackDistribution
+	objective     Objective
+	orderQuantity int
+	caps          model.ShipmentCaps
+	weights       []int64
+	volumes       []int64
+	table         *packTable[int64]
+	// completion[r] is the total, below r+sizes[0], whose candidate best
+	// covers r remaining items; -1 when none can
+	completion []int32
+	nodes      []rankNode
+	open       rankQueue
+}
+
+func newRankSearch(
+	ctx context.Context,
+	sizes []int,
+	orderQuantity int,
+	objective Objective,
+	opts SolveOptions,
+	ruled ruledSizes,
+) (*rankSearch, error) {
+	costs := make([]int64, len(sizes))
+	weights := make([]int64, len(sizes))
+	volumes := make([]int64, len(sizes))
+	for i, size := range sizes {
+		costs[i] = objective.PackCost(size)
+		weights[i] = opts.Measurements[size].WeightGrams
+		volumes[i] = opts.Measurements[size].VolumeMm3()
+	}
+	table, err := buildPackTable(ctx, sizes, costs, orderQuantity+sizes[0])
+	if err != nil {
+		return nil, err
+	}
+
+	s := &rankSearch{
+		sizes:         sizes,
+		costs:         costs,
+		stock:         ruled.stock,
+		forced:        ruled.forced,
+		objective:     objective,
+		orderQuantity: orderQuantity,
+		caps:          opts.Caps,
+		weights:       weights,
+		volumes:       volumes,
+		table:         table,
+		completion:    make([]int32, orderQuantity+1),
+	}
+	s.open.search = s
+	if err := s.fillCompletion(ctx); err != nil {
+		return nil, err
+	}
+
+	// The root holds the forced packs, or none
+	root := rankNode{
+		parent: -1,
+		size:   0,
+		packs:  int32(ruled.forced.TotalPacks()),
+		items:  ruled.forced.TotalItems(),
+		cost:   ruled.forcedCost(objective),
+		weight: ruled.forced.WeightGrams(opts.Measurements),
+		volume: ruled.forced.VolumeMm3(opts.Measurements),
+	}
+	if !s.withinCaps(root) {
+		return s, nil
+	}
+	if root.items >= orderQuantity {
+		root.complete = true
+		root.bound = Candidate{TotalItems: root.items, PackCost: root.cost}
+		s.push(root)
+	} else if total := s.completion[orderQuantity-root.items]; total >= 0 {
+		root.bound = Candidate{
+			TotalItems: root.items + int(total),
+			PackCost:   root.cost + table.cost[total],
+		}
+		s.push(root)
+	}
+	return s, nil
+}
+
+// fillCompletion finds the best completion for every remaining quantity
+// with a sliding window over the totals in [r, r+sizes[0])
+func (s *rankSearch) fillCompletion(ctx context.Context) error {
+	none := unreachableCost[int64]()
+	window := s.sizes[0]
+	candidate := func(total int) Candidate {
+		return Candidate{TotalItems: total, PackCost: s.table.cost[total]}
+	}
+
+	done := ctx.Done()
+	queue := make([]int, 0, window)
+	head := 0
+	for r := len(s.table.cost) - 1; r >= 1; r-- {
+		if r&(cancelCheckInterval-1) == 0 {
+			select {
+			case <-done:
+				return contextError(ctx.Err())
+			default:
+			}
+		}
+
+		if s.table.cost[r] != none {
+			for len(queue) > head &&
+				!s.objective.Less(s.orderQuantity, candidate(queue[len(queue)-1]), candidate(r)) {
+				queue = queue[:len(queue)-1]
+			}
+			queue = append(queue, r)
+		}
+		for head < len(queue) && queue[head] >= r+window {
+			head++
+		}
+		// Compact once the popped prefix dominates the backing array
+		if head > window {
+			queue = append(queue[:0], queue[head:]...)
+			head = 0
+		}
+
+		if r < len(s.completion) {
+			s.completion[r] = -1
+			if head < len(queue) {
+				s.completion[r] = int32(queue[head])
+			}
+		}
+	}
+	return nil
+}
+
+// next returns the next complete distribution in objective order, or nil
+// when none is left or the node budget is spent
+func (s *rankSearch) next(ctx context.Context) (model.PackDistribution, error) {
+	for s.open.Len() > 0 {
+		if len(s.nodes) >= maxRankNodes {
+			return nil, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, contextError(err)
+		}
+
+		index := heap.Pop(&s.open).(int)
+		node := s.nodes[index]
+		if node.complete {
+			return s.distribution(index), nil
+		}
+		s.expand(index)
+	}
+	return nil, nil
+}
+
+// expand pushes every child of a partial distribution: one more pack of
+// its last size, if stock allows, or a first pack of a smaller size
+func (s *rankSearch) expand(index int) {
+	node := s.nodes[index]
+	first := node.size
+	if node.parent < 0 {
+		first = 0
+	}
+
+	for i := first; i < int32(len(s.sizes)); i++ {
+		size := s.sizes[i]
+		run := int32(1)
+		if node.parent >= 0 && i == node.size {
+			run = node.run + 1
+		}
+		if available, ok := s.stock[size]; ok && int(run) > available {
+			continue
+		}
+
+		child := rankNode{
+			parent: int32(index),
+			size:   i,
+			run:    run,
+			packs:  node.packs + 1,
+			items:  node.items + size,
+			cost:   node.cost + s.costs[i],
+			weight: node.weight + s.weights[i],
+			volume: node.volume + s.volumes[i],
+		}
+		if !s.withinCaps(child) {
+			continue
+		}
+		if child.items >= s.orderQuantity {
+			child.complete = true
+			child.bound = Candidate{TotalItems: child.items, PackCost: child.cost}
+		} else {
+			total := s.completion[s.orderQuantity-child.items]
+			if total < 0 {
+				continue
+			}
+			child.bound = Candidate{
+				TotalItems: child.items + int(total),
+				PackCost:   child.cost + s.table.cost[total],
+			}
+		}
+		s.push(child)
+	}
+}
+
+// withinCaps reports whether a node can still complete within the
+// shipment caps. Packs only add weight and volume, and the packs still
+// needed are no larger than the last one added.
+func (s *rankSearch) withinCaps(node rankNode) bool {
+	if limit := s.caps.MaxWeightGrams; limit > 0 && node.weight > limit {
+		return false
+	}
+	if limit := s.caps.MaxVolumeMm3; limit > 0 && node.volume > limit {
+		return false
+	}
+	if limit := s.caps.MaxPacks; limit > 0 {
+		packs := int(node.packs)
+		if remaining := s.orderQuantity - node.items; remaining > 0 {
+			size := s.sizes[node.size]
+			packs += (remaining + size - 1) / size
+		}
+		return packs <= limit
+	}
+	return true
+}
+
+func (s *rankSearch) push(node rankNode) {
+	s.nodes = append(s.nodes, node)
+	heap.Push(&s.open, len(s.nodes)-1)
+}
+
+// distribution rebuilds the forced packs and those on the path to a node
+func (s *rankSearch) distribution(index int) model.PackDistribution {
+	distribution := make(model.PackDistribution, len(s.forced))
+	for size, count := range s.forced {
+		distribution[size] = count
+	}
+	for ; s.nodes[index].parent >= 0; index = int(s.nodes[index].parent) {
+		distribution[s.sizes[s.nodes[index].size]]++
+	}
+	return distribution
+}
+
+// rankQueue orders node indices by bound under the objective. Among equal
+// bounds complete distributions come first, then the partial ones with the
+// most items so ties are followed depth-first, then earlier nodes, so the
+// order is deterministic.
+type rankQueue struct {
+	search  *rankSearch
+	indices []int
+}
+
+func (q *rankQueue) Len() int { return len(q.indices) }
+
+func (q *rankQueue) Less(i, j int) bool {
+	a, b := q.search.nodes[q.indices[i]], q.search.nodes[q.indices[j]]
+	objective, orderQuantity := q.search.objective, q.search.orderQuantity
+	if objective.Less(orderQuantity, a.bound, b.bound) {
+		return true
+	}
+	if objective.Less(orderQuantity, b.bound, a.bound) {
+		return false
+	}
+	if a.complete != b.complete {
+		return a.complete
+	}
+	if a.items != b.items {
+		return a.items > b.items
+	}
+	return q.indices[i] < q.indices[j]
+}
+
+func (q *rankQueue) Swap(i, j int) { q.indices[i], q.indices[j] = q.indices[j], q.indices[i] }
+
+func (q *rankQueue) Push(x any) { q.indices = append(q.indices, x.(int)) }
+
+func (q *rankQueue) Pop() any {
+	last := q.indices[len(q.indices)-1]
+	q.indices = q.indices[:len(q.indices)-1]
+	return last
+}
@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"math/rand"
+	"reflect"
+	"slices"
+	"testing"
+
+	"pack-calculator/internal/domain/model"
+)
+
+func TestPackCalculator_Rank(t *testing.T) {
+	calculator := NewPackCalculator()
+	sizes := []int{250, 500, 1000, 2000, 5000}
+	twoPacks := 2
+
+	tests := []struct {
+		name          string
+		packSizes     []int
+		orderQuantity int
+		opts          SolveOptions
+		expected      []model.PackDistribution
+	}{
+		{
+			name:          "Single result by default",
+			packSizes:     sizes,
+			orderQuantity: 263,
+			expected:      []model.PackDistribution{{500: 1}},
+		},
+		{
+			name:          "Next totals in order",
+			packSizes:     sizes,
+			orderQuantity: 263,
+			opts:          SolveOptions{Alternatives: 4},
+			expected: []model.PackDistribution{
+				{500: 1},
+				{250: 2},
+				{1000: 1},
+				{2000: 1},
+			},
+		},
+		{
+			name:          "Stock removes alternatives",
+			packSizes:     sizes,
+			orderQuantity: 263,
+			opts:          SolveOptions{Alternatives: 3, Stock: model.PackStock{250: 1}},
+			expected: []model.PackDistribution{
+				{500: 1},
+				{1000: 1},
+				{2000: 1},
+			},
+		},
+		{
+			name:          "Fewer distributions than requested",
+			packSizes:     []int{5},
+			orderQuantity: 12,
+			opts:          SolveOptions{Alternatives: 5},
+			expected:      []model.PackDistribution{{5: 3}},
+		},
+		{
+			name:          "Fewest packs first",
+			packSizes:     []int{3, 5},
+			orderQuantity: 9,
+			opts:          SolveOptions{Alternatives: 3, Objective: MinPackCount()},
+			expected: []model.PackDistribution{
+				{5: 2},
+				{3: 3},
+				{5: 1, 3: 2},
+			},
+		},
+		{
+			name:          "Rules apply to every alternative",
+			packSizes:     []int{250, 500, 1000},
+			orderQuantity: 500,
+			opts: SolveOptions{
+				Alternatives: 3,
+				Rules:        model.PackRules{250: {Required: true}},
+			},
+			expected: []model.PackDistribution{
+				{250: 2},
+				{250: 1, 500: 1},
+				{250: 1, 1000: 1},
+			},
+		},
+		{
+			name:          "Only the forced packs",
+			packSizes:     []int{250},
+			orderQuantity: 500,
+			opts: SolveOptions{
+				Alternatives: 3,
+				Rules:        model.PackRules{250: {MinCount: 2, MaxCount: &twoPacks}},
+			},
+			expected: []model.PackDistribution{{250: 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranked, err := calculator.Rank(
+				context.Background(), tt.packSizes, tt.orderQuantity, tt.opts,
+			)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(ranked, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, ranked)
+			}
+		})
+	}
+}
+
+func TestPackCalculator_Rank_ShipmentCaps(t *testing.T) {
+	calculator := NewPackCalculator()
+	sizes := []int{250, 500, 1000, 2000}
+	measurements := map[int]model.PackMeasurements{
+		250:  {WeightGrams: 2600},
+		500:  {WeightGrams: 5000},
+		1000: {WeightGrams: 9000},
+		2000: {WeightGrams: 17000},
+	}
+
+	tests := []struct {
+		name          string
+		packSizes     []int
+		orderQuantity int
+		opts          SolveOptions
+		expected      []model.PackDistribution
+		expectedErr   error
+	}{
+		{
+			name:          "Heavy sizes are removed",
+			packSizes:     sizes,
+			orderQuantity: 1001,
+			opts: SolveOptions{
+				Measurements: measurements,
+				Caps:         model.ShipmentCaps{MaxPackWeightGrams: 10000},
+			},
+			expected: []model.PackDistribution{{1000: 1, 250: 1}},
+		},
+		{
+			name:          "Pack count cap skips the optimum",
+			packSizes:     sizes,
+			orderQuantity: 1001,
+			opts: SolveOptions{
+				Alternatives: 2,
+				Caps:         model.ShipmentCaps{MaxPacks: 1},
+			},
+			expected: []model.PackDistribution{{2000: 1}},
+		},
+		{
+			name:          "Volume cap prefers the smaller packs",
+			packSizes:     []int{3, 5},
+			orderQuantity: 9,
+			opts: SolveOptions{
+				Measurements: map[int]model.PackMeasurements{
+					3: {LengthMm: 1, WidthMm: 1, HeightMm: 10},
+					5: {LengthMm: 1, WidthMm: 1, HeightMm: 5},
+				},
+				Caps: model.ShipmentCaps{MaxVolumeMm3: 20},
+			},
+			expected: []model.PackDistribution{{5: 2}},
+		},
+		{
+			name:          "Every size too heavy",
+			packSizes:     sizes,
+			orderQuantity: 1001,
+			opts: SolveOptions{
+				Measurements: measurements,
+				Caps:         model.ShipmentCaps{MaxPackWeightGrams: 1000},
+			},
+			expectedErr: model.ErrPackTooHeavy,
+		},
+		{
+			name:          "Nothing fits one shipment",
+			packSizes:     sizes,
+			orderQuantity: 5000,
+			opts: SolveOptions{
+				Measurements: measurements,
+				Caps:         model.ShipmentCaps{MaxWeightGrams: 20000},
+			},
+			expectedErr: model.ErrShipmentCapsExceeded,
+		},
+		{
+			name:          "Negative cap",
+			packSizes:     sizes,
+			orderQuantity: 1001,
+			opts:          SolveOptions{Caps: model.ShipmentCaps{MaxVolumeMm3: -1}},
+			expectedErr:   model.ErrInvalidShipmentCaps,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranked, err := calculator.Rank(
+				context.Background(), tt.packSizes, tt.orderQuantity, tt.opts,
+			)
+
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Expected %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(ranked, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, ranked)
+			}
+		})
+	}
+}
+
+// TestPackCalculator_Rank_BruteForce compares the ranking with every
+// distribution that cannot drop a pack, sorted under the objective
+func TestPackCalculator_Rank_BruteForce(t *testing.T) {
+	calculator := NewPackCalculator()
+	rng := rand.New(rand.NewSource(3))
+	objectives := []Objective{
+		Lexicographic(),
+		MinPackCount(),
+		MinTotalCost(model.CostModel{ItemCost: 2, HandlingCost: map[int]int64{1: 3, 2: 1, 7: 5}}),
+	}
+
+	for i := 0; i < 300; i++ {
+		packSizes, stock, orderQuantity := randomStockInstance(rng)
+		objective := objectives[i%len(objectives)]
+		opts := SolveOptions{Stock: stock, Objective: objective, Alternatives: 1 + rng.Intn(8)}
+
+		ranked, err := calculator.Rank(context.Background(), packSizes, orderQuantity, opts)
+		if errors.Is(err, model.ErrInsufficientStock) {
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%v stock %v qty %d: unexpected error: %v",
+				packSizes, stock, orderQuantity, err)
+		}
+
+		all := minimalDistributions(packSizes, orderQuantity, stock)
+		candidate := func(d model.PackDistribution) Candidate {
+			c := Candidate{TotalItems: d.TotalItems()}
+			for size, count := range d {
+				c.PackCost += int64(count) * objective.PackCost(size)
+			}
+			return c
+		}
+		slices.SortStableFunc(all, func(a, b model.PackDistribution) int {
+			switch {
+			case objective.Less(orderQuantity, candidate(a), candidate(b)):
+				return -1
+			case objective.Less(orderQuantity, candidate(b), candidate(a)):
+				return 1
+			}
+			return 0
+		})
+
+		if len(ranked) != min(opts.Alternatives, len(all)) {
+			t.Fatalf("%v stock %v qty %d %s: expected %d distributions, got %v",
+				packSizes, stock, orderQuantity, objective.Name(),
+				min(opts.Alternatives, len(all)), ranked)
+		}
+		for j, distribution := range ranked {
+			if candidate(distribution) != candidate(all[j]) {
+				t.Fatalf("%v stock %v qty %d %s: rank %d expected %v, got %v",
+					packSizes, stock, orderQuantity, objective.Name(), j, all[j], ranked)
+			}
+			if !slices.ContainsFunc(all, func(d model.PackDistribution) bool {
+				return maps.Equal(d, distribution)
+			}) {
+				t.Fatalf("%v stock %v qty %d: %v is not a valid distribution",
+					packSizes, stock, orderQuantity, distribution)
+			}
+			for _, earlier := range ranked[:j] {
+				if maps.Equal(earlier, distribution) {
+					t.Fatalf("%v stock %v qty %d: %v listed twice",
+						packSizes, stock, orderQuantity, distribution)
+				}
+			}
+		}
+	}
+}
+
+// minimalDistributions lists every distribution within the stock that
+// covers orderQuantity but would not once any pack is dropped
+func minimalDistributions(
+	packSizes []int,
+	orderQuantity int,
+	stock model.PackStock,
+) []model.PackDistribution {
+	sizes := normalizePackSizes(packSizes)
+	var all []model.PackDistribution
+
+	var search func(i, items, smallest int, current model.PackDistribution)
+	search = func(i, items, smallest int, current model.PackDistribution) {
+		if items >= orderQuantity {
+			if items-smallest < orderQuantity {
+				all = append(all, maps.Clone(current))
+			}
+			return
+		}
+		if i == len(sizes) {
+			return
+		}
+		maxCount := (orderQuantity-items)/sizes[i] + 1
+		if available, ok := stock[sizes[i]]; ok && available < maxCount {
+			maxCount = available
+		}
+		search(i+1, items, smallest, current)
+		for count := 1; count <= maxCount; count++ {
+			current[sizes[i]] = count
+			search(i+1, items+count*sizes[i], sizes[i], current)
+		}
+		delete(current, sizes[i])
+	}
+	search(0, 0, 0, make(model.PackDistribution))
+
+	return all
+}
+
+func TestPackCalculator_Rank_Cancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewPackCalculator().Rank(
+		ctx, []int{23, 31, 53}, 1000000, SolveOptions{Alternatives: 5},
+	)
+	if !errors.Is(err, model.ErrCalculationCanceled) {
+		t.Errorf("Expected ErrCalculationCanceled, got %v", err)
+	}
+}
+
+func BenchmarkPackCalculator_Rank(b *testing.B) {
+	calculator := NewPackCalculator()
+	packSizes := []int{250, 500, 1000, 2000, 5000}
+	opts := SolveOptions{Alternatives: 10}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := calculator.Rank(context.Background(), packSizes, 5000000, opts)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
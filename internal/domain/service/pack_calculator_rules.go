@@ -0,0 +1,103 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"pack-calculator/internal/domain/model"
+)
+
+// ruledSizes is what is left of a solve once the pack rules are applied:
+// the sizes still allowed, normalised, the stock left for them, and the
+// packs the minimum counts force into every distribution
+type ruledSizes struct {
+	sizes  []int
+	stock  model.PackStock
+	forced model.PackDistribution
+	// narrowed is set when a rule removed a size or lowered its stock
+	narrowed bool
+}
+
+// applyRules removes the sizes the rules do not allow for orderQuantity,
+// lowers the stock to the maximum counts and sets the minimum counts
+// aside. ErrPackRulesNotMet names the rule when a required size cannot be
+// used at all.
+func applyRules(packSizes []int, orderQuantity int, opts SolveOptions) (ruledSizes, error) {
+	sizes := normalizePackSizes(packSizes)
+	if opts.Rules.IsZero() {
+		return ruledSizes{sizes: sizes, stock: opts.Stock}, nil
+	}
+
+	ruled := ruledSizes{
+		stock:  make(model.PackStock, len(opts.Stock)),
+		forced: make(model.PackDistribution),
+	}
+	for size, available := range opts.Stock {
+		ruled.stock[size] = available
+	}
+
+	offered := make(map[int]bool, len(sizes))
+	for _, size := range sizes {
+		offered[size] = true
+		rule := opts.Rules[size]
+		if !rule.Allows(orderQuantity) {
+			if rule.MinPacks() > 0 {
+				return ruledSizes{}, fmt.Errorf("%w: size %d is required but only allowed from "+
+					"order quantity %d", model.ErrPackRulesNotMet, size, rule.MinOrderQuantity)
+			}
+			ruled.narrowed = true
+			continue
+		}
+		ruled.sizes = append(ruled.sizes, size)
+
+		if rule.MaxCount != nil {
+			if available, ok := ruled.stock[size]; !ok || *rule.MaxCount < available {
+				ruled.stock[size] = *rule.MaxCount
+				ruled.narrowed = true
+			}
+		}
+
+		count := rule.MinPacks()
+		if count == 0 {
+			continue
+		}
+		if opts.tooHeavy(size) {
+			return ruledSizes{}, fmt.Errorf("%w: size %d is required but over the parcel weight cap",
+				model.ErrPackRulesNotMet, size)
+		}
+		if available, ok := ruled.stock[size]; ok {
+			if available < count {
+				return ruledSizes{}, fmt.Errorf("%w: size %d needs %d packs, only %d in stock",
+					model.ErrPackRulesNotMet, size, count, available)
+			}
+			ruled.stock[size] = available - count
+		}
+		ruled.forced[size] = count
+	}
+
+	required := make([]int, 0, len(opts.Rules))
+	for size, rule := range opts.Rules {
+		if rule.MinPacks() > 0 && !offered[size] {
+			required = append(required, size)
+		}
+	}
+	if len(required) > 0 {
+		sort.Ints(required)
+		return ruledSizes{}, fmt.Errorf("%w: size %d is required but not a pack size",
+			model.ErrPackRulesNotMet, required[0])
+	}
+	if len(ruled.sizes) == 0 {
+		return ruledSizes{}, fmt.Errorf("%w: no pack size is allowed for order quantity %d",
+			model.ErrPackRulesNotMet, orderQuantity)
+	}
+	return ruled, nil
+}
+
+// forcedCost returns the summed pack cost of the forced packs
+func (r ruledSizes) forcedCost(objective Objective) int64 {
+	cost := int64(0)
+	for size, count := range r.forced {
+		cost += objective.PackCost(size) * int64(count)
+	}
+	return cost
+}
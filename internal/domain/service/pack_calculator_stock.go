@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+
+	"pack-calculator/internal/domain/model"
+)
+
+// layeredProblem is a solve split into unlimited and stock-limited sizes,
+// both normalised
+type layeredProblem struct {
+	// unlimited, limited, stock and the totals are counted in units of
+	// scale, the GCD of the sizes, which divides every reachable total
+	scale         int
+	unlimited     []int
+	limited       []int
+	stock         model.PackStock
+	objective     Objective
+	orderQuantity int
+	// forced are the packs the rules set aside; the layers hold the totals
+	// shipped besides them
+	forced      model.PackDistribution
+	forcedItems int
+	forcedCost  int64
+	// minTotal and limit bound the totals considered; capped is set when
+	// the tolerance lowered limit
+	minTotal int
+	limit    int
+	capped   bool
+}
+
+// solveLayered solves the unlimited sizes with the unbounded table first.
+// Each limited size then adds a layer holding the lowest cost per exact
+// total when up to its stock may be used, computed with a sliding-window
+// minimum over the totals that differ by multiples of the size. Only the
+// layers are kept, one per limited size, and the distribution is rebuilt
+// by finding how many packs of each limited size explain the step between
+// layers.
+func solveLayered[C tableCost](
+	ctx context.Context,
+	p layeredProblem,
+) (model.PackDistribution, error) {
+	costOf := func(size int) C {
+		return C(p.objective.PackCost(size * p.scale))
+	}
+
+	base, err := unlimitedLayer(ctx, p.unlimited, costOf, p.limit)
+	if err != nil {
+		return nil, err
+	}
+
+	layers := make([][]C, 0, len(p.limited)+1)
+	layers = append(layers, base.cost)
+	for _, size := range p.limited {
+		prev := layers[len(layers)-1]
+		layer, err := addLimitedSize(ctx, prev, size, p.stock[size], costOf(size))
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, layer)
+	}
+
+	none := unreachableCost[C]()
+	final := layers[len(layers)-1]
+	best := -1
+	for total := p.minTotal; total < p.limit; total++ {
+		if final[total] == none {
+			continue
+		}
+		candidate := Candidate{
+			TotalItems: total*p.scale + p.forcedItems,
+			PackCost:   int64(final[total]) + p.forcedCost,
+		}
+		if best < 0 || p.objective.Less(p.orderQuantity, candidate, Candidate{
+			TotalItems: best*p.scale + p.forcedItems,
+			PackCost:   int64(final[best]) + p.forcedCost,
+		}) {
+			best = total
+		}
+	}
+	if best < 0 {
+		if p.capped {
+			return nil, model.ErrToleranceNotMet
+		}
+		if len(p.limited) > 0 {
+			return nil, model.ErrInsufficientStock
+		}
+		return nil, model.ErrCalculationFailed
+	}
+
+	distribution := make(model.PackDistribution)
+	remaining := best
+	for i := len(p.limited) - 1; i >= 0; i-- {
+		size := p.limited[i]
+		prev, current := layers[i], layers[i+1]
+		cost := costOf(size)
+
+		// Prefer the most packs of the larger limited sizes
+		count := min(p.stock[size], remaining/size)
+		for ; count >= 0; count-- {
+			rest := remaining - count*size
+			if prev[rest] != none && prev[rest]+C(count)*cost == current[remaining] {
+				break
+			}
+		}
+		if count > 0 {
+			distribution[size*p.scale] = count
+			remaining -= count * size
+		}
+	}
+	for size, count := range base.rebuild(remaining) {
+		distribution[size*p.scale] = count
+	}
+	for size, count := range p.forced {
+		distribution[size] += count
+	}
+
+	return distribution, nil
+}
+
+// unlimitedLayer returns the table for the unlimited sizes, or a table in
+// which only zero is reachable when every size is limited
+func unlimitedLayer[C tableCost](
+	ctx context.Context,
+	sizes []int,
+	costOf func(int) C,
+	limit int,
+) (*packTable[C], error) {
+	if len(sizes) > 0 {
+		costs := make([]C, len(sizes))
+		for i, size := range sizes {
+			costs[i] = costOf(size)
+		}
+		return buildPackTable(ctx, sizes, costs, limit)
+	}
+
+	none := unreachableCost[C]()
+	table := &packTable[C]{cost: make([]C, limit), last: make([]int32, limit)}
+	for t := 1; t < limit; t++ {
+		table.cost[t] = none
+	}
+	return table, nil
+}
+
+// addLimitedSize returns the layer that extends prev with up to count
+// packs of size, each costing cost:
+//
+//	next[t] = min over k in [0, count] of prev[t-k*size] + k*cost
+//
+// Totals are walked per residue modulo size, keeping a monotonic queue of
+// the best prev[t-k*size] - index*cost within the last count+1 positions.
+func addLimitedSize[C tableCost](
+	ctx context.Context,
+	prev []C,
+	size int,
+	count int,
+	cost C,
+) ([]C, error) {
+	none := unreachableCost[C]()
+	n := len(prev)
+	next := make([]C, n)
+	done := ctx.Done()
+	step := 0
+
+	// Stock beyond the table is effectively unlimited
+	if count >= (n-1)/size {
+		for t := 0; t < n; t++ {
+			if step++; step&(cancelCheckInterval-1) == 0 {
+				select {
+				case <-done:
+					return nil, contextError(ctx.Err())
+				default:
+				}
+			}
+
+			next[t] = prev[t]
+			if t >= size && next[t-size] != none && next[t-size]+cost < next[t] {
+				next[t] = next[t-size] + cost
+			}
+		}
+		return next, nil
+	}
+
+	queue := make([]int, 0, n/size+1)
+	for r := 0; r < size && r < n; r++ {
+		queue = queue[:0]
+		head := 0
+		value := func(j int) C {
+			return prev[r+j*size] - C(j)*cost
+		}
+
+		for j := 0; r+j*size < n; j++ {
+			if step++; step&(cancelCheckInterval-1) == 0 {
+				select {
+				case <-done:
+					return nil, contextError(ctx.Err())
+				default:
+				}
+			}
+
+			t := r + j*size
+			if prev[t] != none {
+				for len(queue) > head && value(queue[len(queue)-1]) >= value(j) {
+					queue = queue[:len(queue)-1]
+				}
+				queue = append(queue, j)
+			}
+			for head < len(queue) && queue[head] < j-count {
+				head++
+			}
+
+			if head < len(queue) {
+				next[t] = value(queue[head]) + C(j)*cost
+			} else {
+				next[t] = none
+			}
+		}
+	}
+
+	return next, nil
+}
+
+// stockCapacity returns the items the limited sizes can ship, stopping
+// once orderQuantity is covered; it is math.MaxInt when they can ship more
+// than an int holds
+func stockCapacity(sizes []int, stock model.PackStock, orderQuantity int) int {
+	capacity := 0
+	for _, size := range sizes {
+		capacity = addCapped(capacity, mulCapped(size, stock[size]))
+		if capacity >= orderQuantity {
+			break
+		}
+	}
+	return capacity
+}
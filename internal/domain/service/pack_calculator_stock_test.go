@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"pack-calculator/internal/domain/model"
+)
+
+func TestPackCalculator_SolveWithStock(t *testing.T) {
+	calculator := NewPackCalculator()
+	sizes := []int{250, 500, 1000, 2000, 5000}
+
+	tests := []struct {
+		name          string
+		packSizes     []int
+		orderQuantity int
+		stock         model.PackStock
+		expected      model.PackDistribution
+		expectedErr   error
+	}{
+		{
+			name:          "No limits",
+			packSizes:     sizes,
+			orderQuantity: 12001,
+			expected:      model.PackDistribution{5000: 2, 2000: 1, 250: 1},
+		},
+		{
+			name:          "Limit not reached",
+			packSizes:     sizes,
+			orderQuantity: 12001,
+			stock:         model.PackStock{5000: 10},
+			expected:      model.PackDistribution{5000: 2, 2000: 1, 250: 1},
+		},
+		{
+			name:          "Only three large packs left",
+			packSizes:     sizes,
+			orderQuantity: 20000,
+			stock:         model.PackStock{5000: 3},
+			expected:      model.PackDistribution{5000: 3, 2000: 2, 1000: 1},
+		},
+		{
+			name:          "Out of stock size is skipped",
+			packSizes:     sizes,
+			orderQuantity: 251,
+			stock:         model.PackStock{500: 0},
+			expected:      model.PackDistribution{250: 2},
+		},
+		{
+			name:          "Stock cannot cover the order",
+			packSizes:     []int{3, 5},
+			orderQuantity: 11,
+			stock:         model.PackStock{3: 1, 5: 1},
+			expectedErr:   model.ErrInsufficientStock,
+		},
+		{
+			name:          "All sizes limited",
+			packSizes:     []int{3, 5},
+			orderQuantity: 7,
+			stock:         model.PackStock{3: 1, 5: 2},
+			expected:      model.PackDistribution{3: 1, 5: 1},
+		},
+		{
+			name:          "Stock beyond int",
+			packSizes:     []int{250, 500},
+			orderQuantity: 1000,
+			stock:         model.PackStock{250: 1 << 62, 500: 1 << 62},
+			expected:      model.PackDistribution{500: 2},
+		},
+		{
+			name:          "Every size out of stock",
+			packSizes:     []int{250, 500},
+			orderQuantity: 1,
+			stock:         model.PackStock{250: 0, 500: 0},
+			expectedErr:   model.ErrInsufficientStock,
+		},
+		{
+			name:          "Negative stock",
+			packSizes:     []int{250, 500},
+			orderQuantity: 1,
+			stock:         model.PackStock{250: -1},
+			expectedErr:   model.ErrInvalidPackStock,
+		},
+		{
+			name:          "Invalid order quantity",
+			packSizes:     []int{250, 500},
+			orderQuantity: 0,
+			stock:         model.PackStock{250: 1},
+			expectedErr:   model.ErrInvalidOrderQuantity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calculator.Solve(
+				context.Background(), tt.packSizes, tt.orderQuantity, SolveOptions{Stock: tt.stock},
+			)
+
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Expected %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+// TestPackCalculator_SolveWithStock_BruteForce compares the solver with
+// exhaustive search on small random instances
+func TestPackCalculator_SolveWithStock_BruteForce(t *testing.T) {
+	calculator := NewPackCalculator()
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 500; i++ {
+		packSizes, stock, orderQuantity := randomStockInstance(rng)
+		opts := SolveOptions{Stock: stock}
+		checkAgainstBruteForce(t, calculator, packSizes, orderQuantity, opts)
+	}
+}
+
+// randomStockInstance returns a small random problem in which some sizes
+// have limited stock
+func randomStockInstance(rng *rand.Rand) ([]int, model.PackStock, int) {
+	packSizes := make([]int, 1+rng.Intn(3))
+	stock := make(model.PackStock)
+	for j := range packSizes {
+		packSizes[j] = 1 + rng.Intn(12)
+		if rng.Intn(3) > 0 {
+			stock[packSizes[j]] = rng.Intn(4)
+		}
+	}
+	return packSizes, stock, 1 + rng.Intn(40)
+}
+
+// checkAgainstBruteForce fails the test unless Solve finds a distribution
+// within the stock that is as good as the exhaustive search's
+func checkAgainstBruteForce(
+	t *testing.T,
+	calculator *PackCalculator,
+	packSizes []int,
+	orderQuantity int,
+	opts SolveOptions,
+) {
+	t.Helper()
+
+	objective := opts.Objective
+	if objective == nil {
+		objective = Lexicographic()
+	}
+
+	expected, found := bruteForce(packSizes, orderQuantity, opts.Stock, objective)
+	result, err := calculator.Solve(context.Background(), packSizes, orderQuantity, opts)
+
+	if !found {
+		if !errors.Is(err, model.ErrInsufficientStock) {
+			t.Fatalf("%v stock %v qty %d: expected ErrInsufficientStock, got %v, %v",
+				packSizes, opts.Stock, orderQuantity, result, err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("%v stock %v qty %d: unexpected error: %v",
+			packSizes, opts.Stock, orderQuantity, err)
+	}
+
+	got := Candidate{TotalItems: result.TotalItems()}
+	for size, count := range result {
+		got.PackCost += int64(count) * objective.PackCost(size)
+		if available, ok := opts.Stock[size]; ok && count > available {
+			t.Fatalf("%v stock %v qty %d: %v exceeds stock",
+				packSizes, opts.Stock, orderQuantity, result)
+		}
+	}
+	if got.TotalItems < orderQuantity || objective.Less(orderQuantity, expected, got) {
+		t.Fatalf("%v stock %v qty %d %s: expected %+v, got %v (%+v)",
+			packSizes, opts.Stock, orderQuantity, objective.Name(), expected, result, got)
+	}
+}
+
+// bruteForce returns the best candidate that covers orderQuantity within
+// the stock, or false when the stock cannot cover it
+func bruteForce(
+	packSizes []int,
+	orderQuantity int,
+	stock model.PackStock,
+	objective Objective,
+) (Candidate, bool) {
+	sizes := normalizePackSizes(packSizes)
+	var best Candidate
+	found := false
+
+	var search func(i, items int, cost int64)
+	search = func(i, items int, cost int64) {
+		if items >= orderQuantity {
+			candidate := Candidate{TotalItems: items, PackCost: cost}
+			if !found || objective.Less(orderQuantity, candidate, best) {
+				best, found = candidate, true
+			}
+			return
+		}
+		if i == len(sizes) {
+			return
+		}
+		maxCount := (orderQuantity-items)/sizes[i] + 1
+		if available, ok := stock[sizes[i]]; ok && available < maxCount {
+			maxCount = available
+		}
+		for count := 0; count <= maxCount; count++ {
+			search(i+1, items+count*sizes[i], cost+int64(count)*objective.PackCost(sizes[i]))
+		}
+	}
+	search(0, 0, 0)
+
+	return best, found
+}
+
+func TestPackCalculator_SolveWithStock_Cancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewPackCalculator().Solve(
+		ctx, []int{23, 31, 53}, 1000000, SolveOptions{Stock: model.PackStock{53: 100}},
+	)
+	if !errors.Is(err, model.ErrCalculationCanceled) {
+		t.Errorf("Expected ErrCalculationCanceled, got %v", err)
+	}
+}
+
+func BenchmarkPackCalculator_SolveWithStock(b *testing.B) {
+	calculator := NewPackCalculator()
+	packSizes := []int{250, 500, 1000, 2000, 5000}
+	opts := SolveOptions{Stock: model.PackStock{5000: 100, 2000: 50}}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := calculator.Solve(context.Background(), packSizes, 5000000, opts)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
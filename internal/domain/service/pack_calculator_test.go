@@ -1,7 +1,11 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"reflect"
 	"testing"
+	"time"
 
 	"pack-calculator/internal/domain/model"
 )
@@ -75,7 +79,7 @@ func TestPackCalculator_Calculate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := calculator.Calculate(tt.packSizes, tt.orderQuantity)
+			result, err := calculator.Calculate(context.Background(), tt.packSizes, tt.orderQuantity)
 
 			if tt.expectError {
 				if err == nil {
@@ -116,6 +120,407 @@ func TestPackCalculator_Calculate(t *testing.T) {
 	}
 }
 
+func TestPackCalculator_LargeOrder(t *testing.T) {
+	calculator := NewPackCalculator()
+	packSizes := []int{23, 31, 53}
+	orderQuantity := 5000000
+
+	result, err := calculator.Calculate(context.Background(), packSizes, orderQuantity)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if result.TotalItems() != orderQuantity {
+		t.Errorf("Expected exact fulfillment of %d, got %d", orderQuantity, result.TotalItems())
+	}
+
+	// 5000000 = 53*94339 + 33 and 33 cannot be reached, so at least one
+	// 53 must be traded for smaller packs
+	if result.TotalPacks() < orderQuantity/53+1 {
+		t.Errorf("Pack count %d is below the theoretical minimum", result.TotalPacks())
+	}
+}
+
+func TestPackCalculator_DoesNotMutateInput(t *testing.T) {
+	calculator := NewPackCalculator()
+	packSizes := []int{250, 1000, 500, 500}
+
+	if _, err := calculator.Calculate(context.Background(), packSizes, 751); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []int{250, 1000, 500, 500}
+	for i := range expected {
+		if packSizes[i] != expected[i] {
+			t.Fatalf("Input pack sizes were modified: %v", packSizes)
+		}
+	}
+}
+
+func TestPackCalculator_Cancellation(t *testing.T) {
+	calculator := NewPackCalculator()
+	packSizes := []int{23, 31, 53}
+
+	t.Run("Canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := calculator.Calculate(ctx, packSizes, 5000000)
+		if !errors.Is(err, model.ErrCalculationCanceled) {
+			t.Errorf("Expected ErrCalculationCanceled, got %v", err)
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected error to wrap context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("Deadline exceeded mid-solve", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		// Far below the periodic cutoff, so every total is walked
+		_, err := calculator.Calculate(ctx, []int{99991, 99989}, 10000000)
+		if !errors.Is(err, model.ErrCalculationTimeout) {
+			t.Errorf("Expected ErrCalculationTimeout, got %v", err)
+		}
+	})
+}
+
+func TestPackCalculator_SolveWithTolerance(t *testing.T) {
+	calculator := NewPackCalculator()
+
+	tests := []struct {
+		name          string
+		packSizes     []int
+		orderQuantity int
+		opts          SolveOptions
+		expected      model.PackDistribution
+		expectedErr   error
+	}{
+		{
+			name:          "Fulfils by default",
+			packSizes:     []int{500},
+			orderQuantity: 501,
+			expected:      model.PackDistribution{500: 2},
+		},
+		{
+			name:          "Ships short within the shortfall",
+			packSizes:     []int{500},
+			orderQuantity: 501,
+			opts:          SolveOptions{Tolerance: model.Tolerance{MaxShortfall: 1}},
+			expected:      model.PackDistribution{500: 1},
+		},
+		{
+			name:          "Shortfall too small",
+			packSizes:     []int{500},
+			orderQuantity: 502,
+			opts:          SolveOptions{Tolerance: model.Tolerance{MaxShortfall: 1}},
+			expected:      model.PackDistribution{500: 2},
+		},
+		{
+			name:          "Shortfall percentage",
+			packSizes:     []int{250, 500, 1000},
+			orderQuantity: 1010,
+			opts:          SolveOptions{Tolerance: model.Tolerance{MaxShortfallPercent: 1}},
+			expected:      model.PackDistribution{1000: 1},
+		},
+		{
+			name:          "Equal distance fulfils",
+			packSizes:     []int{4, 6},
+			orderQuantity: 5,
+			opts:          SolveOptions{Tolerance: model.Tolerance{MaxShortfall: 1}},
+			expected:      model.PackDistribution{6: 1},
+		},
+		{
+			name:          "Overage cap overrides the objective",
+			packSizes:     []int{300, 1000},
+			orderQuantity: 900,
+			opts: SolveOptions{
+				Objective: MinPackCount(),
+				Tolerance: model.Tolerance{MaxOverage: 50},
+			},
+			expected: model.PackDistribution{300: 3},
+		},
+		{
+			name:          "Nothing within the overage cap",
+			packSizes:     []int{300, 500},
+			orderQuantity: 501,
+			opts:          SolveOptions{Tolerance: model.Tolerance{MaxOverage: 50}},
+			expectedErr:   model.ErrToleranceNotMet,
+		},
+		{
+			name:          "Short rather than over the cap",
+			packSizes:     []int{300, 500},
+			orderQuantity: 501,
+			opts: SolveOptions{
+				Tolerance: model.Tolerance{MaxShortfall: 1, MaxOverage: 50},
+			},
+			expected: model.PackDistribution{500: 1},
+		},
+		{
+			name:          "Shortfall priced like overage",
+			packSizes:     []int{250, 1000},
+			orderQuantity: 1020,
+			opts: SolveOptions{
+				Objective: MinTotalCost(model.CostModel{
+					ItemCost:     1,
+					HandlingCost: map[int]int64{250: 100, 1000: 100},
+				}),
+				Tolerance: model.Tolerance{MaxShortfallPercent: 5},
+			},
+			expected: model.PackDistribution{1000: 1},
+		},
+		{
+			name:          "Ships short of the stock",
+			packSizes:     []int{500},
+			orderQuantity: 600,
+			opts: SolveOptions{
+				Stock:     model.PackStock{500: 1},
+				Tolerance: model.Tolerance{MaxShortfall: 100},
+			},
+			expected: model.PackDistribution{500: 1},
+		},
+		{
+			name:          "Stock short beyond the tolerance",
+			packSizes:     []int{500},
+			orderQuantity: 601,
+			opts: SolveOptions{
+				Stock:     model.PackStock{500: 1},
+				Tolerance: model.Tolerance{MaxShortfall: 100},
+			},
+			expectedErr: model.ErrInsufficientStock,
+		},
+		{
+			name:          "Mixed shortfall units",
+			packSizes:     []int{500},
+			orderQuantity: 501,
+			opts: SolveOptions{
+				Tolerance: model.Tolerance{MaxShortfall: 1, MaxShortfallPercent: 1},
+			},
+			expectedErr: model.ErrInvalidTolerance,
+		},
+		{
+			name:          "Whole order short",
+			packSizes:     []int{500},
+			orderQuantity: 501,
+			opts:          SolveOptions{Tolerance: model.Tolerance{MaxShortfallPercent: 100}},
+			expectedErr:   model.ErrInvalidTolerance,
+		},
+		{
+			name:          "Alternatives with a tolerance",
+			packSizes:     []int{500},
+			orderQuantity: 501,
+			opts: SolveOptions{
+				Alternatives: 2,
+				Tolerance:    model.Tolerance{MaxShortfall: 1},
+			},
+			expectedErr: model.ErrInvalidTolerance,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calculator.Solve(
+				context.Background(), tt.packSizes, tt.orderQuantity, tt.opts,
+			)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Expected %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPackCalculator_SolveWithRules(t *testing.T) {
+	calculator := NewPackCalculator()
+	one, two := 1, 2
+
+	tests := []struct {
+		name          string
+		packSizes     []int
+		orderQuantity int
+		opts          SolveOptions
+		expected      model.PackDistribution
+		expectedErr   error
+	}{
+		{
+			name:          "Required size",
+			packSizes:     []int{250, 500, 1000},
+			orderQuantity: 1000,
+			opts:          SolveOptions{Rules: model.PackRules{250: {Required: true}}},
+			expected:      model.PackDistribution{250: 2, 500: 1},
+		},
+		{
+			name:          "Forbidden size",
+			packSizes:     []int{250, 500, 1000},
+			orderQuantity: 251,
+			opts:          SolveOptions{Rules: model.PackRules{250: {Forbidden: true}}},
+			expected:      model.PackDistribution{500: 1},
+		},
+		{
+			name:          "Size below its order threshold",
+			packSizes:     []int{250, 500, 1000},
+			orderQuantity: 251,
+			opts:          SolveOptions{Rules: model.PackRules{250: {MinOrderQuantity: 1000}}},
+			expected:      model.PackDistribution{500: 1},
+		},
+		{
+			name:          "Size above its order threshold",
+			packSizes:     []int{250, 500, 1000},
+			orderQuantity: 1001,
+			opts:          SolveOptions{Rules: model.PackRules{250: {MinOrderQuantity: 1000}}},
+			expected:      model.PackDistribution{250: 1, 1000: 1},
+		},
+		{
+			name:          "Max count",
+			packSizes:     []int{250, 500},
+			orderQuantity: 1500,
+			opts:          SolveOptions{Rules: model.PackRules{500: {MaxCount: &one}}},
+			expected:      model.PackDistribution{250: 4, 500: 1},
+		},
+		{
+			name:          "Min count",
+			packSizes:     []int{250, 500},
+			orderQuantity: 500,
+			opts:          SolveOptions{Rules: model.PackRules{250: {MinCount: 2}}},
+			expected:      model.PackDistribution{250: 2},
+		},
+		{
+			name:          "Required packs within the overage cap",
+			packSizes:     []int{13},
+			orderQuantity: 12,
+			opts: SolveOptions{
+				Rules:     model.PackRules{13: {Required: true, MaxCount: &one}},
+				Tolerance: model.Tolerance{MaxOverage: 2},
+			},
+			expected: model.PackDistribution{13: 1},
+		},
+		{
+			name:          "Required packs beyond the overage cap",
+			packSizes:     []int{13},
+			orderQuantity: 9,
+			opts: SolveOptions{
+				Rules:     model.PackRules{13: {Required: true, MaxCount: &one}},
+				Tolerance: model.Tolerance{MaxOverage: 2},
+			},
+			expectedErr: model.ErrPackRulesNotMet,
+		},
+		{
+			name:          "Min count beyond the order",
+			packSizes:     []int{250, 500, 1000},
+			orderQuantity: 500,
+			opts:          SolveOptions{Rules: model.PackRules{1000: {MinCount: 2}}},
+			expected:      model.PackDistribution{1000: 2},
+		},
+		{
+			name:          "Min count from stock",
+			packSizes:     []int{250, 500},
+			orderQuantity: 1000,
+			opts: SolveOptions{
+				Stock: model.PackStock{250: 2},
+				Rules: model.PackRules{250: {MinCount: 2}},
+			},
+			expected: model.PackDistribution{250: 2, 500: 1},
+		},
+		{
+			name:          "Required size is not a pack size",
+			packSizes:     []int{250, 500},
+			orderQuantity: 500,
+			opts:          SolveOptions{Rules: model.PackRules{1000: {Required: true}}},
+			expectedErr:   model.ErrPackRulesNotMet,
+		},
+		{
+			name:          "Required size below its order threshold",
+			packSizes:     []int{250, 500},
+			orderQuantity: 500,
+			opts: SolveOptions{
+				Rules: model.PackRules{250: {Required: true, MinOrderQuantity: 1000}},
+			},
+			expectedErr: model.ErrPackRulesNotMet,
+		},
+		{
+			name:          "Required size out of stock",
+			packSizes:     []int{250, 500},
+			orderQuantity: 500,
+			opts: SolveOptions{
+				Stock: model.PackStock{250: 0},
+				Rules: model.PackRules{250: {Required: true}},
+			},
+			expectedErr: model.ErrPackRulesNotMet,
+		},
+		{
+			name:          "Max counts cannot cover the order",
+			packSizes:     []int{500},
+			orderQuantity: 501,
+			opts:          SolveOptions{Rules: model.PackRules{500: {MaxCount: &one}}},
+			expectedErr:   model.ErrPackRulesNotMet,
+		},
+		{
+			name:          "Every size forbidden",
+			packSizes:     []int{250, 500},
+			orderQuantity: 500,
+			opts: SolveOptions{
+				Rules: model.PackRules{250: {Forbidden: true}, 500: {Forbidden: true}},
+			},
+			expectedErr: model.ErrPackRulesNotMet,
+		},
+		{
+			name:          "Required packs beyond the overage cap",
+			packSizes:     []int{1000},
+			orderQuantity: 100,
+			opts: SolveOptions{
+				Tolerance: model.Tolerance{MaxOverage: 50},
+				Rules:     model.PackRules{1000: {Required: true}},
+			},
+			expectedErr: model.ErrPackRulesNotMet,
+		},
+		{
+			name:          "Min count above max count",
+			packSizes:     []int{250, 500},
+			orderQuantity: 500,
+			opts:          SolveOptions{Rules: model.PackRules{250: {MinCount: 3, MaxCount: &two}}},
+			expectedErr:   model.ErrInvalidPackRule,
+		},
+		{
+			name:          "Required and forbidden",
+			packSizes:     []int{250, 500},
+			orderQuantity: 500,
+			opts: SolveOptions{
+				Rules: model.PackRules{250: {Required: true, Forbidden: true}},
+			},
+			expectedErr: model.ErrInvalidPackRule,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calculator.Solve(
+				context.Background(), tt.packSizes, tt.orderQuantity, tt.opts,
+			)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Expected %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
 func BenchmarkPackCalculator_EdgeCase(b *testing.B) {
 	calculator := NewPackCalculator()
 	packSizes := []int{23, 31, 53}
@@ -123,7 +528,35 @@ func BenchmarkPackCalculator_EdgeCase(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := calculator.Calculate(packSizes, orderQuantity)
+		_, err := calculator.Calculate(context.Background(), packSizes, orderQuantity)
+		if err != nil {
+			b.Fatalf("Calculate failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkPackCalculator_LargeOrder(b *testing.B) {
+	calculator := NewPackCalculator()
+	packSizes := []int{23, 31, 53}
+	orderQuantity := 5000000
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := calculator.Calculate(context.Background(), packSizes, orderQuantity)
+		if err != nil {
+			b.Fatalf("Calculate failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkPackCalculator_ManySizes(b *testing.B) {
+	calculator := NewPackCalculator()
+	packSizes := []int{250, 500, 1000, 2000, 5000}
+	orderQuantity := 10000001
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := calculator.Calculate(context.Background(), packSizes, orderQuantity)
 		if err != nil {
 			b.Fatalf("Calculate failed: %v", err)
 		}
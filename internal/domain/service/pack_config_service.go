@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/domain/repository"
+	"pack-calculator/internal/infrastructure/idgen"
+	"pack-calculator/internal/infrastructure/logger"
+)
+
+// PackConfigService manages the stored pack configurations
+type PackConfigService struct {
+	repo     repository.PackRepository
+	ids      IDGenerator
+	onChange func(skus ...string)
+}
+
+// PackConfigServiceOption configures optional PackConfigService behaviour
+type PackConfigServiceOption func(*PackConfigService)
+
+// WithPackIDGenerator replaces the default ULID generator for pack IDs
+func WithPackIDGenerator(ids IDGenerator) PackConfigServiceOption {
+	return func(s *PackConfigService) {
+		s.ids = ids
+	}
+}
+
+// WithPackChangeHook calls fn with the SKUs whose catalogs changed after
+// every pack is created, updated or deleted, so that state derived from
+// the pack store can be refreshed. fn runs on the request path and should
+// not block.
+func WithPackChangeHook(fn func(skus ...string)) PackConfigServiceOption {
+	return func(s *PackConfigService) {
+		s.onChange = fn
+	}
+}
+
+// NewPackConfigService creates a pack configuration service
+func NewPackConfigService(
+	repo repository.PackRepository,
+	opts ...PackConfigServiceOption,
+) *PackConfigService {
+	s := &PackConfigService{
+		repo: repo,
+		ids:  idgen.NewULID(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// PackInput holds the user-supplied fields of a pack
+type PackInput struct {
+	Size int
+	Name string
+	// SKU is the product whose catalog the pack joins; empty is the
+	// default catalog
+	SKU string
+	// Stock is how many packs are available; nil is unlimited
+	Stock *int
+	// HandlingCost is the cost of shipping one pack in minor currency units
+	HandlingCost int64
+	// Measurements are the pack's weight and dimensions
+	Measurements model.PackMeasurements
+	// Packaging lists the outer units the packs nest into, innermost first
+	Packaging model.Packaging
+	// Rule constrains how calculations use the pack
+	Rule model.PackRule
+}
+
+// apply copies the input onto pack
+func (in PackInput) apply(pack *model.Pack) {
+	pack.Update(in.Size, strings.TrimSpace(in.Name))
+	pack.SKU = strings.TrimSpace(in.SKU)
+	pack.Stock = in.Stock
+	pack.HandlingCost = in.HandlingCost
+	pack.PackMeasurements = in.Measurements
+	pack.Packaging = in.Packaging
+	pack.Rule = in.Rule
+}
+
+// Create adds a new active pack
+func (s *PackConfigService) Create(ctx context.Context, in PackInput) (*model.Pack, error) {
+	if err := validatePack(in); err != nil {
+		return nil, err
+	}
+
+	pack := model.NewPack(in.Size, strings.TrimSpace(in.Name))
+	pack.ID = s.ids.NewID()
+	in.apply(pack)
+
+	if err := s.repo.Create(ctx, pack); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Pack created", map[string]interface{}{
+		"pack_id": pack.ID,
+		"size":    pack.Size,
+		"name":    pack.Name,
+		"sku":     pack.SKU,
+	})
+	s.changed(pack.SKU)
+
+	return pack, nil
+}
+
+// Get returns a single pack
+func (s *PackConfigService) Get(ctx context.Context, id string) (*model.Pack, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// List returns the packs that match filter
+func (s *PackConfigService) List(
+	ctx context.Context,
+	filter repository.PackFilter,
+) ([]*model.Pack, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// Update replaces the user-supplied fields of an existing pack
+func (s *PackConfigService) Update(
+	ctx context.Context,
+	id string,
+	in PackInput,
+) (*model.Pack, error) {
+	if err := validatePack(in); err != nil {
+		return nil, err
+	}
+
+	pack, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	oldSKU := pack.SKU
+	in.apply(pack)
+	if err := s.repo.Update(ctx, pack); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Pack updated", map[string]interface{}{
+		"pack_id": pack.ID,
+		"size":    pack.Size,
+		"name":    pack.Name,
+	})
+	// Moving a pack to another SKU changes both catalogs
+	if pack.SKU != oldSKU {
+		s.changed(oldSKU, pack.SKU)
+	} else {
+		s.changed(pack.SKU)
+	}
+
+	return pack, nil
+}
+
+// Delete soft-deletes a pack by deactivating it
+func (s *PackConfigService) Delete(ctx context.Context, id string) (*model.Pack, error) {
+	pack, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	pack.Deactivate()
+	if err := s.repo.Update(ctx, pack); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Pack deactivated", map[string]interface{}{
+		"pack_id": pack.ID,
+		"size":    pack.Size,
+	})
+	s.changed(pack.SKU)
+
+	return pack, nil
+}
+
+// changed calls the change hook, if any, with the SKUs of the changed
+// catalogs
+func (s *PackConfigService) changed(skus ...string) {
+	if s.onChange != nil {
+		s.onChange(skus...)
+	}
+}
+
+// validatePack checks the user-supplied pack fields
+func validatePack(in PackInput) error {
+	if in.Size <= 0 {
+		return model.ErrInvalidPackSize
+	}
+	if strings.TrimSpace(in.Name) == "" {
+		return model.ErrInvalidPackName
+	}
+	if in.Stock != nil && *in.Stock < 0 {
+		return model.ErrInvalidPackStock
+	}
+	if in.HandlingCost < 0 {
+		return model.ErrInvalidCost
+	}
+	if err := in.Measurements.Validate(); err != nil {
+		return err
+	}
+	if err := in.Packaging.Validate(); err != nil {
+		return err
+	}
+	return in.Rule.Validate()
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/domain/repository"
+	"pack-calculator/internal/infrastructure/persistence"
+)
+
+func TestPackConfigService(t *testing.T) {
+	service := NewPackConfigService(
+		persistence.NewMemoryPackRepository(),
+		WithPackIDGenerator(&sequenceIDs{prefix: "pack"}),
+	)
+	ctx := context.Background()
+
+	small, err := service.Create(ctx, PackInput{Size: 250, Name: "Small"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if small.ID != "pack-1" || !small.Active {
+		t.Errorf("Pack not created correctly: %+v", small)
+	}
+
+	_, err = service.Create(ctx, PackInput{Size: 250, Name: "Another small"})
+	if !errors.Is(err, model.ErrPackAlreadyExists) {
+		t.Errorf("Expected ErrPackAlreadyExists, got %v", err)
+	}
+
+	_, err = service.Create(ctx, PackInput{Size: 0, Name: "Empty"})
+	if !errors.Is(err, model.ErrInvalidPackSize) {
+		t.Errorf("Expected ErrInvalidPackSize, got %v", err)
+	}
+
+	_, err = service.Create(ctx, PackInput{Size: 100, Name: "  "})
+	if !errors.Is(err, model.ErrInvalidPackName) {
+		t.Errorf("Expected ErrInvalidPackName, got %v", err)
+	}
+
+	negative := -1
+	_, err = service.Create(ctx, PackInput{Size: 100, Name: "Negative", Stock: &negative})
+	if !errors.Is(err, model.ErrInvalidPackStock) {
+		t.Errorf("Expected ErrInvalidPackStock, got %v", err)
+	}
+
+	updated, err := service.Update(ctx, small.ID, PackInput{Size: 300, Name: "Medium"})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if updated.Size != 300 || updated.Name != "Medium" {
+		t.Errorf("Pack not updated correctly: %+v", updated)
+	}
+
+	deleted, err := service.Delete(ctx, small.ID)
+	if err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if deleted.Active {
+		t.Errorf("Deleted pack should be inactive")
+	}
+
+	active, _ := service.List(ctx, repository.PackFilter{ActiveOnly: true})
+	if len(active) != 0 {
+		t.Errorf("Expected no active packs, got %d", len(active))
+	}
+
+	all, _ := service.List(ctx, repository.PackFilter{})
+	if len(all) != 1 {
+		t.Errorf("Expected soft-deleted pack to remain listed, got %d", len(all))
+	}
+
+	if _, err := service.Get(ctx, "missing"); !errors.Is(err, model.ErrPackNotFound) {
+		t.Errorf("Expected ErrPackNotFound, got %v", err)
+	}
+}
+
+func TestPackConfigService_ChangeHook(t *testing.T) {
+	var changes [][]string
+	service := NewPackConfigService(
+		persistence.NewMemoryPackRepository(),
+		WithPackChangeHook(func(skus ...string) { changes = append(changes, skus) }),
+	)
+	ctx := context.Background()
+
+	pack, err := service.Create(ctx, PackInput{Size: 250, Name: "Small"})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if _, err := service.Update(ctx, pack.ID, PackInput{Size: 300, Name: "Small"}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	update := PackInput{Size: 300, Name: "Small", SKU: "bolts"}
+	if _, err := service.Update(ctx, pack.ID, update); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if _, err := service.Delete(ctx, pack.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	// Moving the pack to another SKU changes both catalogs
+	expected := [][]string{{""}, {""}, {"", "bolts"}, {"bolts"}}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected changes %v, got %v", expected, changes)
+	}
+
+	// Rejected changes leave the store as it was
+	service.Create(ctx, PackInput{Size: 0, Name: "Empty"})
+	service.Get(ctx, pack.ID)
+	if len(changes) != len(expected) {
+		t.Errorf("Expected reads and rejected writes not to count, got %v", changes)
+	}
+}
@@ -2,63 +2,298 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"runtime"
 	"time"
 
 	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/domain/repository"
+	"pack-calculator/internal/infrastructure/idgen"
 	"pack-calculator/internal/infrastructure/logger"
 )
 
+// IDGenerator issues identifiers for calculations and packs.
+// Implementations must be safe for concurrent use.
+type IDGenerator interface {
+	NewID() string
+}
+
 type PackService struct {
-	calculator *PackCalculator
+	calculator   *PackCalculator
+	maxSolveTime time.Duration
+	limits       Limits
+	batchWorkers int
+	history      repository.CalculationRepository
+	ids          IDGenerator
+	tables       *SolutionTables
+}
+
+// Limits caps the size of a calculation request; zero disables a limit
+type Limits struct {
+	// MaxOrderQuantity bounds the quantity a solve walks: the order
+	// quantity itself, or only the remainder once the periodic regime has
+	// set the largest packs aside, so larger orders can pass
+	MaxOrderQuantity     int
+	MaxPackSizes         int
+	MaxPackSize          int
+	MaxBatchSize         int
+	MaxAlternatives      int
+	MaxCandidatePackSets int
+}
+
+// PackServiceOption configures optional PackService behaviour
+type PackServiceOption func(*PackService)
+
+// WithMaxSolveTime bounds how long a single calculation may run.
+// A zero or negative duration disables the limit.
+func WithMaxSolveTime(d time.Duration) PackServiceOption {
+	return func(ps *PackService) {
+		ps.maxSolveTime = d
+	}
+}
+
+// WithLimits rejects requests that exceed the given input limits
+func WithLimits(limits Limits) PackServiceOption {
+	return func(ps *PackService) {
+		ps.limits = limits
+	}
+}
+
+// WithBatchWorkers sets how many pack sets a batch solves concurrently
+func WithBatchWorkers(n int) PackServiceOption {
+	return func(ps *PackService) {
+		if n > 0 {
+			ps.batchWorkers = n
+		}
+	}
 }
 
-func NewPackService() *PackService {
-	return &PackService{
-		calculator: NewPackCalculator(),
+// WithCalculationRepository records every completed calculation
+func WithCalculationRepository(repo repository.CalculationRepository) PackServiceOption {
+	return func(ps *PackService) {
+		ps.history = repo
 	}
 }
 
+// WithIDGenerator replaces the default ULID generator for calculation IDs
+func WithIDGenerator(ids IDGenerator) PackServiceOption {
+	return func(ps *PackService) {
+		ps.ids = ids
+	}
+}
+
+// WithSolutionTables answers plain calculations of hot pack sets from
+// precomputed solution tables
+func WithSolutionTables(tables *SolutionTables) PackServiceOption {
+	return func(ps *PackService) {
+		ps.tables = tables
+	}
+}
+
+// userIDKey is the context key for the requesting user's ID
+type userIDKey struct{}
+
+// ContextWithUserID attaches the requesting user's ID to ctx so that
+// calculations made with it are attributed to that user
+func ContextWithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// userIDFromContext returns the user ID attached by ContextWithUserID
+func userIDFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(userIDKey{}).(string)
+	return userID
+}
+
+func NewPackService(opts ...PackServiceOption) *PackService {
+	ps := &PackService{
+		calculator:   NewPackCalculator(),
+		batchWorkers: runtime.NumCPU(),
+		ids:          idgen.NewULID(),
+	}
+	for _, opt := range opts {
+		opt(ps)
+	}
+	return ps
+}
+
 func (ps *PackService) CalculateOptimal(
 	ctx context.Context,
 	packSizes []int,
 	orderQuantity int,
+) (*model.Calculation, error) {
+	return ps.CalculateWithOptions(ctx, packSizes, orderQuantity, SolveOptions{})
+}
+
+// CalculateWithOptions is CalculateOptimal with limited stock, another
+// objective, ranked alternatives or shipment caps; see PackCalculator.Solve
+// and PackCalculator.Rank. Alternatives are only listed when more than one
+// is requested. When no distribution fits a single shipment the best one
+// is returned with SplitRequired set. With packaging the result is also
+// rolled up into outer units. Plain calculations of a pack set with a
+// solution table are read from the table.
+func (ps *PackService) CalculateWithOptions(
+	ctx context.Context,
+	packSizes []int,
+	orderQuantity int,
+	opts SolveOptions,
 ) (*model.Calculation, error) {
 	startTime := time.Now()
 
 	logger.Debug("Starting pack calculation", map[string]interface{}{
 		"pack_sizes":     packSizes,
 		"order_quantity": orderQuantity,
+		"stock":          opts.Stock,
+		"objective":      objectiveName(opts.Objective),
+		"alternatives":   opts.Alternatives,
+		"caps":           opts.Caps,
+		"tolerance":      opts.Tolerance,
 	})
 
-	// Perform calculation
-	distribution, err := ps.calculator.Calculate(packSizes, orderQuantity)
+	// Only the remainder left by the periodic regime is walked
+	solved := ps.calculator.SolvedQuantity(packSizes, orderQuantity, opts)
+	err := ps.checkLimits(packSizes, orderQuantity, solved)
+	if err == nil {
+		err = ps.checkAlternatives(opts.Alternatives)
+	}
+	if err != nil {
+		logger.Warn("Pack calculation rejected", map[string]interface{}{
+			"pack_sizes":     packSizes,
+			"order_quantity": orderQuantity,
+			"error":          err.Error(),
+		})
+		return nil, err
+	}
+
+	ctx, cancel := ps.solveContext(ctx)
+	defer cancel()
+
+	// Perform calculation; the first ranked distribution is the optimum
+	ranked, found := ps.lookupSolution(packSizes, orderQuantity, opts)
+	if !found {
+		ranked, err = ps.calculator.Rank(ctx, packSizes, orderQuantity, opts)
+	}
+	splitRequired := false
+	if errors.Is(err, model.ErrShipmentCapsExceeded) {
+		// Nothing fits a single shipment, so rank without the shipment caps
+		// and report that the order must be split
+		uncapped := opts
+		uncapped.Caps = model.ShipmentCaps{MaxPackWeightGrams: opts.Caps.MaxPackWeightGrams}
+		ranked, err = ps.calculator.Rank(ctx, packSizes, orderQuantity, uncapped)
+		splitRequired = true
+	}
 	if err != nil {
 		logger.Error("Pack calculation failed", map[string]interface{}{
 			"pack_sizes":     packSizes,
 			"order_quantity": orderQuantity,
+			"stock":          opts.Stock,
 			"error":          err.Error(),
 		})
 		return nil, err
 	}
 
+	distribution := ranked[0]
 	calculationTime := time.Since(startTime)
 
 	// Create result
 	result := model.NewCalculation(packSizes, orderQuantity, distribution, calculationTime)
-	result.ID = ps.generateID()
+	result.ID = ps.ids.NewID()
+	result.UserID = userIDFromContext(ctx)
+	result.Objective = objectiveName(opts.Objective)
+	result.TotalCost = totalCost(opts.Objective, distribution, orderQuantity)
+	result.TotalWeightGrams = distribution.WeightGrams(opts.Measurements)
+	result.TotalVolumeMm3 = distribution.VolumeMm3(opts.Measurements)
+	if !opts.Caps.IsZero() {
+		result.SplitRequired = splitRequired
+		result.MinShipments = opts.Caps.MinShipments(distribution, opts.Measurements)
+	}
+	if len(opts.Packaging) > 0 {
+		result.Packaging = distribution.RollUp(opts.Packaging)
+	}
+	if opts.Alternatives > 1 {
+		result.Alternatives = make([]model.Alternative, len(ranked))
+		for i, alternative := range ranked {
+			result.Alternatives[i] = model.NewAlternative(alternative, orderQuantity)
+			result.Alternatives[i].TotalCost = totalCost(opts.Objective, alternative, orderQuantity)
+		}
+	}
+	ps.record(ctx, result)
 
 	logger.Debug("Pack calculation completed", map[string]interface{}{
 		"calculation_id": result.ID,
 		"total_items":    result.TotalItems,
 		"total_packs":    result.TotalPacks,
 		"items_overage":  result.ItemsOverage,
+		"split_required": result.SplitRequired,
 		"duration_ms":    calculationTime.Milliseconds(),
 	})
 
 	return result, nil
 }
 
-func (ps *PackService) generateID() string {
-	return fmt.Sprintf("%d", time.Now().UnixNano())
+// record saves a completed calculation to the history. A failed save is
+// logged rather than returned so that history outages never fail orders.
+func (ps *PackService) record(ctx context.Context, calculation *model.Calculation) {
+	if ps.history == nil {
+		return
+	}
+
+	// The solve context may already be past its deadline
+	if err := ps.history.Save(context.WithoutCancel(ctx), calculation); err != nil {
+		logger.Error("Failed to record calculation", map[string]interface{}{
+			"calculation_id": calculation.ID,
+			"error":          err.Error(),
+		})
+	}
+}
+
+// solveContext applies the configured maximum solve time to ctx
+func (ps *PackService) solveContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ps.maxSolveTime > 0 {
+		return context.WithTimeout(ctx, ps.maxSolveTime)
+	}
+	return context.WithCancel(ctx)
+}
+
+// checkLimits enforces the configured input limits. The order quantity
+// limit bounds the totals a solve walks, so it applies to solved, the
+// quantity left once the periodic regime has set the largest packs aside;
+// errors still report the client's orderQuantity.
+func (ps *PackService) checkLimits(packSizes []int, orderQuantity, solved int) error {
+	if limit := ps.limits.MaxOrderQuantity; limit > 0 && solved > limit {
+		if solved == orderQuantity {
+			return fmt.Errorf("%w: %d > %d", model.ErrOrderTooLarge, orderQuantity, limit)
+		}
+		return fmt.Errorf("%w: %d leaves %d to solve > %d",
+			model.ErrOrderTooLarge, orderQuantity, solved, limit)
+	}
+	if limit := ps.limits.MaxPackSizes; limit > 0 && len(packSizes) > limit {
+		return fmt.Errorf("%w: %d > %d", model.ErrTooManyPackSizes, len(packSizes), limit)
+	}
+	if limit := ps.limits.MaxPackSize; limit > 0 {
+		for _, size := range packSizes {
+			if size > limit {
+				return fmt.Errorf("%w: %d > %d", model.ErrPackSizeTooLarge, size, limit)
+			}
+		}
+	}
+	return nil
+}
+
+// checkAlternatives enforces the configured limit on ranked alternatives
+func (ps *PackService) checkAlternatives(alternatives int) error {
+	if limit := ps.limits.MaxAlternatives; limit > 0 && alternatives > limit {
+		return fmt.Errorf("%w: %d > %d", model.ErrTooManyAlternatives, alternatives, limit)
+	}
+	return nil
+}
+
+// checkBatchSize enforces the configured limit on batch items and order
+// lines
+func (ps *PackService) checkBatchSize(size int) error {
+	if limit := ps.limits.MaxBatchSize; limit > 0 && size > limit {
+		return fmt.Errorf("%w: %d > %d", model.ErrBatchTooLarge, size, limit)
+	}
+	return nil
 }
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/infrastructure/logger"
+)
+
+// AnalyzePackSet analyses packSizes and their overage curve; see
+// PackCalculator.Analyze. The last quantity of the curve is checked
+// against the order quantity limit like a calculation.
+func (ps *PackService) AnalyzePackSet(
+	ctx context.Context,
+	packSizes []int,
+	opts AnalyzeOptions,
+) (*model.PackSetAnalysis, error) {
+	startTime := time.Now()
+
+	last := opts.To
+	if sizes := normalizePackSizes(packSizes); len(sizes) > 0 {
+		last = opts.last(sizes[0])
+	}
+	solved := ps.calculator.SolvedQuantity(packSizes, last, SolveOptions{})
+	if err := ps.checkLimits(packSizes, last, solved); err != nil {
+		logger.Warn("Pack set analysis rejected", map[string]interface{}{
+			"pack_sizes": packSizes,
+			"to":         last,
+			"error":      err.Error(),
+		})
+		return nil, err
+	}
+
+	ctx, cancel := ps.solveContext(ctx)
+	defer cancel()
+
+	analysis, err := ps.calculator.Analyze(ctx, packSizes, opts)
+	if err != nil {
+		logger.Error("Pack set analysis failed", map[string]interface{}{
+			"pack_sizes": packSizes,
+			"error":      err.Error(),
+		})
+		return nil, err
+	}
+
+	logger.Debug("Pack set analysed", map[string]interface{}{
+		"pack_sizes":  analysis.PackSizes,
+		"gcd":         analysis.GCD,
+		"frobenius":   analysis.FrobeniusNumber,
+		"points":      len(analysis.OverageCurve),
+		"duration_ms": time.Since(startTime).Milliseconds(),
+	})
+	return analysis, nil
+}
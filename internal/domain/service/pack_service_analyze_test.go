@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pack-calculator/internal/domain/model"
+)
+
+func TestPackService_AnalyzePackSet(t *testing.T) {
+	ctx := context.Background()
+	packService := NewPackService(WithLimits(Limits{
+		MaxOrderQuantity: 5000,
+		MaxPackSize:      2500,
+	}))
+
+	tests := []struct {
+		name      string
+		packSizes []int
+		opts      AnalyzeOptions
+		err       error
+	}{
+		{"Default range", []int{250, 500, 1000}, AnalyzeOptions{}, nil},
+		// Past the periodic cutoff only the remainder counts
+		{"Order beyond the periodic cutoff", []int{23, 31, 53}, AnalyzeOptions{
+			From: 1000000, To: 1000100,
+		}, nil},
+		{"Walked order too large", []int{2500, 2499}, AnalyzeOptions{To: 6000},
+			model.ErrOrderTooLarge},
+		{"Default range too large", []int{2500, 2499}, AnalyzeOptions{From: 100},
+			model.ErrOrderTooLarge},
+		{"Pack size too large", []int{3000}, AnalyzeOptions{To: 10}, model.ErrPackSizeTooLarge},
+		{"Backwards range", []int{250}, AnalyzeOptions{From: 10, To: 5},
+			model.ErrInvalidQuantityRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis, err := packService.AnalyzePackSet(ctx, tt.packSizes, tt.opts)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Expected %v, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AnalyzePackSet: %v", err)
+			}
+			if len(analysis.OverageCurve) == 0 {
+				t.Error("Expected an overage curve")
+			}
+		})
+	}
+}
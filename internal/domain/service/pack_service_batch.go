@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/infrastructure/logger"
+)
+
+// BatchItem is a single order line in a batch calculation
+type BatchItem struct {
+	Reference     string
+	PackSizes     []int
+	OrderQuantity int
+}
+
+// BatchResult is the outcome of one batch item; exactly one of
+// Calculation and Err is set
+type BatchResult struct {
+	Reference   string
+	Calculation *model.Calculation
+	Err         error
+}
+
+// CalculateBatch solves every item and returns results in input order.
+// Items are read from their pack set's solution table when there is one;
+// the rest sharing a pack set are answered from a single solve, and distinct
+// pack sets are solved concurrently by a bounded pool of workers. A failing
+// item never fails the batch; only a batch over the size limit does.
+func (ps *PackService) CalculateBatch(
+	ctx context.Context,
+	items []BatchItem,
+) ([]BatchResult, error) {
+	if err := ps.checkBatchSize(len(items)); err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(items))
+	groups := make(map[string][]int)
+	var order []string
+
+	for i, item := range items {
+		results[i].Reference = item.Reference
+
+		if err := ps.checkItem(item); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		key := packSetKey(item.PackSizes)
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+
+	logger.Debug("Starting batch calculation", map[string]interface{}{
+		"items":     len(items),
+		"pack_sets": len(groups),
+	})
+
+	jobs := make(chan []int)
+	var wg sync.WaitGroup
+
+	workers := ps.batchWorkers
+	if workers > len(groups) {
+		workers = len(groups)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for indices := range jobs {
+				ps.solveGroup(ctx, items, indices, results)
+			}
+		}()
+	}
+
+	for _, key := range order {
+		jobs <- groups[key]
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, nil
+}
+
+// checkItem validates a batch item on its own so bad lines are reported
+// individually instead of failing the shared solve
+func (ps *PackService) checkItem(item BatchItem) error {
+	if _, err := validateInput(item.PackSizes, []int{item.OrderQuantity}); err != nil {
+		return err
+	}
+	solved := ps.calculator.SolvedQuantity(item.PackSizes, item.OrderQuantity, SolveOptions{})
+	return ps.checkLimits(item.PackSizes, item.OrderQuantity, solved)
+}
+
+// solveGroup answers all items at indices, which share one pack set
+func (ps *PackService) solveGroup(
+	ctx context.Context,
+	items []BatchItem,
+	indices []int,
+	results []BatchResult,
+) {
+	startTime := time.Now()
+
+	packSizes := items[indices[0]].PackSizes
+	distributions := make([]model.PackDistribution, len(indices))
+	var missed, quantities []int
+	for i, idx := range indices {
+		quantity := items[idx].OrderQuantity
+		if ranked, ok := ps.lookupSolution(packSizes, quantity, SolveOptions{}); ok {
+			distributions[i] = ranked[0]
+			continue
+		}
+		missed = append(missed, i)
+		quantities = append(quantities, quantity)
+	}
+
+	ctx, cancel := ps.solveContext(ctx)
+	defer cancel()
+
+	if len(missed) > 0 {
+		solved, err := ps.calculator.CalculateMany(ctx, packSizes, quantities)
+		if err != nil {
+			logger.Error("Batch pack set calculation failed", map[string]interface{}{
+				"pack_sizes": packSizes,
+				"items":      len(missed),
+				"error":      err.Error(),
+			})
+			for _, i := range missed {
+				results[indices[i]].Err = err
+			}
+			return
+		}
+		for j, i := range missed {
+			distributions[i] = solved[j]
+		}
+	}
+
+	calculationTime := time.Since(startTime)
+	for i, idx := range indices {
+		item := items[idx]
+		result := model.NewCalculation(
+			item.PackSizes,
+			item.OrderQuantity,
+			distributions[i],
+			calculationTime,
+		)
+		result.ID = ps.ids.NewID()
+		result.UserID = userIDFromContext(ctx)
+		result.Objective = ObjectiveLexicographic
+		ps.record(ctx, result)
+		results[idx].Calculation = result
+	}
+}
+
+// packSetKey identifies a pack set independent of order and duplicates
+func packSetKey(packSizes []int) string {
+	return fmt.Sprint(normalizePackSizes(packSizes))
+}
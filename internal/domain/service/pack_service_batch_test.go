@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pack-calculator/internal/domain/model"
+)
+
+func TestPackService_CalculateBatch(t *testing.T) {
+	service := NewPackService(WithBatchWorkers(2))
+	ctx := context.Background()
+
+	items := []BatchItem{
+		{Reference: "a", PackSizes: []int{250, 500, 1000}, OrderQuantity: 263},
+		{Reference: "b", PackSizes: []int{23, 31, 53}, OrderQuantity: 500000},
+		{Reference: "c", PackSizes: []int{}, OrderQuantity: 100},
+		{Reference: "d", PackSizes: []int{1000, 500, 250}, OrderQuantity: 12001},
+		{Reference: "e", PackSizes: []int{250, 500}, OrderQuantity: 0},
+		{Reference: "f", PackSizes: []int{250, 500, 1000}, OrderQuantity: 501},
+	}
+
+	results, err := service.CalculateBatch(ctx, items)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(results) != len(items) {
+		t.Fatalf("Expected %d results, got %d", len(items), len(results))
+	}
+
+	expectedErrs := map[string]error{
+		"c": model.ErrEmptyPackSizes,
+		"e": model.ErrInvalidOrderQuantity,
+	}
+
+	calculator := NewPackCalculator()
+	for i, result := range results {
+		item := items[i]
+		if result.Reference != item.Reference {
+			t.Errorf("Result %d: expected reference %s, got %s", i, item.Reference, result.Reference)
+		}
+
+		if expectedErr, ok := expectedErrs[item.Reference]; ok {
+			if !errors.Is(result.Err, expectedErr) {
+				t.Errorf("Item %s: expected %v, got %v", item.Reference, expectedErr, result.Err)
+			}
+			continue
+		}
+
+		if result.Err != nil {
+			t.Errorf("Item %s: unexpected error %v", item.Reference, result.Err)
+			continue
+		}
+
+		// A shared solve must give the same answer as a standalone one
+		expected, err := calculator.Calculate(ctx, item.PackSizes, item.OrderQuantity)
+		if err != nil {
+			t.Fatalf("Calculate failed: %v", err)
+		}
+
+		actual := result.Calculation.GetDistribution()
+		if len(actual) != len(expected) {
+			t.Errorf("Item %s: expected %v, got %v", item.Reference, expected, actual)
+		}
+		for size, count := range expected {
+			if actual[size] != count {
+				t.Errorf("Item %s: expected %v, got %v", item.Reference, expected, actual)
+			}
+		}
+	}
+}
+
+func TestPackService_CalculateBatch_TooLarge(t *testing.T) {
+	service := NewPackService(WithLimits(Limits{MaxBatchSize: 1}))
+
+	items := []BatchItem{
+		{PackSizes: []int{250}, OrderQuantity: 1},
+		{PackSizes: []int{250}, OrderQuantity: 2},
+	}
+
+	_, err := service.CalculateBatch(context.Background(), items)
+	if !errors.Is(err, model.ErrBatchTooLarge) {
+		t.Errorf("Expected ErrBatchTooLarge, got %v", err)
+	}
+}
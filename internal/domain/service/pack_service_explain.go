@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/infrastructure/logger"
+)
+
+// ExplainedCalculation is a calculation together with the evidence that
+// its distribution is optimal
+type ExplainedCalculation struct {
+	Calculation *model.Calculation
+	Explanation *model.Explanation
+}
+
+// Explain calculates like CalculateOptimal and explains the result; see
+// PackCalculator.Explain. The calculation is recorded like any other.
+func (ps *PackService) Explain(
+	ctx context.Context,
+	packSizes []int,
+	orderQuantity int,
+) (*ExplainedCalculation, error) {
+	startTime := time.Now()
+
+	// Explaining walks every total up to the order quantity
+	if err := ps.checkLimits(packSizes, orderQuantity, orderQuantity); err != nil {
+		logger.Warn("Pack explanation rejected", map[string]interface{}{
+			"pack_sizes":     packSizes,
+			"order_quantity": orderQuantity,
+			"error":          err.Error(),
+		})
+		return nil, err
+	}
+
+	ctx, cancel := ps.solveContext(ctx)
+	defer cancel()
+
+	explanation, err := ps.calculator.Explain(ctx, packSizes, orderQuantity)
+	if err != nil {
+		logger.Error("Pack explanation failed", map[string]interface{}{
+			"pack_sizes":     packSizes,
+			"order_quantity": orderQuantity,
+			"error":          err.Error(),
+		})
+		return nil, err
+	}
+
+	calculationTime := time.Since(startTime)
+	result := model.NewCalculation(
+		packSizes, orderQuantity, explanation.Distribution, calculationTime,
+	)
+	result.ID = ps.ids.NewID()
+	result.UserID = userIDFromContext(ctx)
+	result.Objective = ObjectiveLexicographic
+	ps.record(ctx, result)
+
+	logger.Debug("Pack explanation completed", map[string]interface{}{
+		"calculation_id": result.ID,
+		"minimal_total":  explanation.MinimalTotal,
+		"runner_ups":     len(explanation.RunnerUps),
+		"duration_ms":    calculationTime.Milliseconds(),
+	})
+
+	return &ExplainedCalculation{
+		Calculation: result,
+		Explanation: explanation,
+	}, nil
+}
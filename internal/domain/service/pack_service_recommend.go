@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/infrastructure/logger"
+)
+
+const (
+	// DefaultRecommendationCandidates is how many of the most ordered
+	// quantities are tried as pack sizes when no candidates are given
+	DefaultRecommendationCandidates = 12
+	// DefaultRecommendations is how many pack sets are returned when no
+	// count is given
+	DefaultRecommendations = 10
+)
+
+// RecommendOptions narrows the search for the pack sizes to stock
+type RecommendOptions struct {
+	// MaxPackSizes is the budget: the most sizes a pack set may have
+	MaxPackSizes int
+	// Candidates are the sizes pack sets are drawn from; when empty the
+	// DefaultRecommendationCandidates most ordered quantities are used
+	Candidates []int
+	// Top is how many pack sets are returned; zero is
+	// DefaultRecommendations
+	Top int
+}
+
+// RecommendPackSizes scores every set of up to opts.MaxPackSizes candidate
+// sizes against demand and returns the best, least overage per order
+// first, then fewest packs per order, then fewest sizes.
+//
+// Each set is evaluated by solving every demanded quantity from a single
+// PackCalculator table, and sets are evaluated concurrently by the batch
+// workers. The search as a whole is bounded by the maximum solve time and
+// ErrTooManyCandidatePackSets is returned when it would try more sets than
+// the configured limit.
+func (ps *PackService) RecommendPackSizes(
+	ctx context.Context,
+	demand model.DemandHistogram,
+	opts RecommendOptions,
+) ([]model.PackSetScore, error) {
+	startTime := time.Now()
+
+	if err := demand.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.MaxPackSizes <= 0 {
+		return nil, model.ErrInvalidPackSizeBudget
+	}
+	demand = demand.Merge()
+
+	candidates := normalizePackSizes(opts.Candidates)
+	if len(candidates) == 0 {
+		candidates = commonQuantities(demand, DefaultRecommendationCandidates)
+	}
+	for _, size := range candidates {
+		if size <= 0 {
+			return nil, model.ErrInvalidPackSize
+		}
+	}
+	sort.Ints(candidates)
+
+	if err := ps.checkLimits(candidates, 0, 0); err != nil {
+		return nil, err
+	}
+	budget := min(opts.MaxPackSizes, len(candidates))
+	count := countPackSets(len(candidates), budget)
+	if limit := ps.limits.MaxCandidatePackSets; limit > 0 && count > limit {
+		return nil, fmt.Errorf("%w: %d > %d", model.ErrTooManyCandidatePackSets, count, limit)
+	}
+
+	// Each pack set walks only the remainder its own periodic regime leaves
+	// of the largest demanded quantity
+	sets := packSets(candidates, budget)
+	largest, solved := demand[len(demand)-1].Quantity, 0
+	for _, set := range sets {
+		solved = max(solved, ps.calculator.SolvedQuantity(set, largest, SolveOptions{}))
+	}
+	if err := ps.checkLimits(candidates, largest, solved); err != nil {
+		return nil, err
+	}
+
+	logger.Debug("Starting pack size recommendation", map[string]interface{}{
+		"demand_buckets": len(demand),
+		"candidates":     candidates,
+		"max_pack_sizes": budget,
+		"pack_sets":      count,
+	})
+
+	ctx, cancel := ps.solveContext(ctx)
+	defer cancel()
+
+	results, err := ps.evaluatePackSets(ctx, sets, demand)
+	if err != nil {
+		logger.Error("Pack size recommendation failed", map[string]interface{}{
+			"candidates": candidates,
+			"error":      err.Error(),
+		})
+		return nil, err
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].less(results[j])
+	})
+
+	top := opts.Top
+	if top <= 0 {
+		top = DefaultRecommendations
+	}
+	results = results[:min(top, len(results))]
+
+	orders := float64(demand.TotalOrders())
+	items := float64(demand.TotalItems())
+	scores := make([]model.PackSetScore, len(results))
+	for i, result := range results {
+		scores[i] = model.PackSetScore{
+			PackSizes:       result.sizes,
+			ExpectedOverage: float64(result.overage) / orders,
+			ExpectedPacks:   float64(result.packs) / orders,
+			OverageRate:     float64(result.overage) / items,
+		}
+	}
+
+	logger.Debug("Pack size recommendation completed", map[string]interface{}{
+		"pack_sets":   count,
+		"best":        scores[0].PackSizes,
+		"duration_ms": time.Since(startTime).Milliseconds(),
+	})
+
+	return scores, nil
+}
+
+// packSetResult is the overage and pack count a pack set needs to serve
+// every order of the demand
+type packSetResult struct {
+	sizes   []int
+	overage int
+	packs   int
+}
+
+// less ranks results by overage, then packs, then fewer and smaller sizes
+func (r packSetResult) less(other packSetResult) bool {
+	if r.overage != other.overage {
+		return r.overage < other.overage
+	}
+	if r.packs != other.packs {
+		return r.packs < other.packs
+	}
+	if len(r.sizes) != len(other.sizes) {
+		return len(r.sizes) < len(other.sizes)
+	}
+	for i := range r.sizes {
+		if r.sizes[i] != other.sizes[i] {
+			return r.sizes[i] < other.sizes[i]
+		}
+	}
+	return false
+}
+
+// evaluatePackSets solves demand with every set on a bounded pool of
+// workers and returns the results in the order of sets
+func (ps *PackService) evaluatePackSets(
+	ctx context.Context,
+	sets [][]int,
+	demand model.DemandHistogram,
+) ([]packSetResult, error) {
+	quantities := make([]int, len(demand))
+	for i, bucket := range demand {
+		quantities[i] = bucket.Quantity
+	}
+
+	results := make([]packSetResult, len(sets))
+	errs := make([]error, len(sets))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	workers := min(ps.batchWorkers, len(sets))
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				distributions, err := ps.calculator.CalculateMany(ctx, sets[i], quantities)
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+				results[i].sizes = sets[i]
+				for j, distribution := range distributions {
+					orders := demand[j].Orders
+					results[i].overage += (distribution.TotalItems() - demand[j].Quantity) * orders
+					results[i].packs += distribution.TotalPacks() * orders
+				}
+			}
+		}()
+	}
+
+	for i := range sets {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// commonQuantities returns up to n of the most ordered quantities
+func commonQuantities(demand model.DemandHistogram, n int) []int {
+	buckets := make(model.DemandHistogram, len(demand))
+	copy(buckets, demand)
+	sort.SliceStable(buckets, func(i, j int) bool {
+		return buckets[i].Orders > buckets[j].Orders
+	})
+
+	quantities := make([]int, 0, n)
+	for _, bucket := range buckets[:min(n, len(buckets))] {
+		quantities = append(quantities, bucket.Quantity)
+	}
+	return quantities
+}
+
+// packSets returns every set of 1 to k sizes drawn from candidates, each
+// in the order of candidates
+func packSets(candidates []int, k int) [][]int {
+	var sets [][]int
+	var pick func(start int, set []int)
+	pick = func(start int, set []int) {
+		for i := start; i < len(candidates); i++ {
+			next := append(set[:len(set):len(set)], candidates[i])
+			sets = append(sets, next)
+			if len(next) < k {
+				pick(i+1, next)
+			}
+		}
+	}
+	pick(0, nil)
+	return sets
+}
+
+// countPackSets returns how many sets packSets yields for n candidates,
+// saturating at math.MaxInt
+func countPackSets(n, k int) int {
+	total, choose := 0, 1
+	for i := 1; i <= k; i++ {
+		// choose(n, i) = choose(n, i-1) * (n-i+1) / i
+		if choose > math.MaxInt/(n-i+1) {
+			return math.MaxInt
+		}
+		choose = choose * (n - i + 1) / i
+		if total > math.MaxInt-choose {
+			return math.MaxInt
+		}
+		total += choose
+	}
+	return total
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"pack-calculator/internal/domain/model"
+)
+
+func TestPackService_RecommendPackSizes(t *testing.T) {
+	service := NewPackService(WithBatchWorkers(2))
+	ctx := context.Background()
+
+	demand := model.DemandHistogram{
+		{Quantity: 500, Orders: 5},
+		{Quantity: 250, Orders: 10},
+		{Quantity: 750, Orders: 1},
+	}
+
+	tests := []struct {
+		name     string
+		demand   model.DemandHistogram
+		opts     RecommendOptions
+		expected []model.PackSetScore
+	}{
+		{
+			name:   "One size",
+			demand: demand,
+			opts:   RecommendOptions{MaxPackSizes: 1, Candidates: []int{1000, 250, 500}, Top: 2},
+			expected: []model.PackSetScore{
+				{PackSizes: []int{250}, ExpectedPacks: 23.0 / 16},
+				{
+					PackSizes:       []int{500},
+					ExpectedOverage: 2750.0 / 16,
+					ExpectedPacks:   17.0 / 16,
+					OverageRate:     2750.0 / 5750,
+				},
+			},
+		},
+		{
+			name:   "Fewer sizes win ties",
+			demand: demand,
+			opts:   RecommendOptions{MaxPackSizes: 2, Candidates: []int{250, 500, 750, 1000}, Top: 4},
+			expected: []model.PackSetScore{
+				{PackSizes: []int{250, 500}, ExpectedPacks: 17.0 / 16},
+				{PackSizes: []int{250, 750}, ExpectedPacks: 21.0 / 16},
+				{PackSizes: []int{250}, ExpectedPacks: 23.0 / 16},
+				{PackSizes: []int{250, 1000}, ExpectedPacks: 23.0 / 16},
+			},
+		},
+		{
+			name:   "Most ordered quantities are the default candidates",
+			demand: model.DemandHistogram{{Quantity: 250, Orders: 2}, {Quantity: 500, Orders: 1}},
+			opts:   RecommendOptions{MaxPackSizes: 3},
+			expected: []model.PackSetScore{
+				{PackSizes: []int{250, 500}, ExpectedPacks: 1},
+				{PackSizes: []int{250}, ExpectedPacks: 4.0 / 3},
+				{
+					PackSizes:       []int{500},
+					ExpectedOverage: 500.0 / 3,
+					ExpectedPacks:   1,
+					OverageRate:     0.5,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scores, err := service.RecommendPackSizes(ctx, tt.demand, tt.opts)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(scores, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, scores)
+			}
+		})
+	}
+}
+
+func TestPackService_RecommendPackSizesErrors(t *testing.T) {
+	service := NewPackService(WithLimits(Limits{MaxOrderQuantity: 1000, MaxCandidatePackSets: 3}))
+	ctx := context.Background()
+	demand := model.DemandHistogram{{Quantity: 263, Orders: 1}}
+
+	tests := []struct {
+		name        string
+		demand      model.DemandHistogram
+		opts        RecommendOptions
+		expectedErr error
+	}{
+		{
+			name:        "No demand",
+			opts:        RecommendOptions{MaxPackSizes: 1},
+			expectedErr: model.ErrEmptyDemand,
+		},
+		{
+			name:        "No orders",
+			demand:      model.DemandHistogram{{Quantity: 263}},
+			opts:        RecommendOptions{MaxPackSizes: 1},
+			expectedErr: model.ErrInvalidDemand,
+		},
+		{
+			name:        "No budget",
+			demand:      demand,
+			expectedErr: model.ErrInvalidPackSizeBudget,
+		},
+		{
+			name:        "Invalid candidate",
+			demand:      demand,
+			opts:        RecommendOptions{MaxPackSizes: 1, Candidates: []int{250, -1}},
+			expectedErr: model.ErrInvalidPackSize,
+		},
+		{
+			// A single size is periodic from the start, but 999 and 1000
+			// walk every total up to their cutoff
+			name:        "Order over the limit",
+			demand:      model.DemandHistogram{{Quantity: 1001, Orders: 1}},
+			opts:        RecommendOptions{MaxPackSizes: 2, Candidates: []int{999, 1000}},
+			expectedErr: model.ErrOrderTooLarge,
+		},
+		{
+			name:   "Order beyond the periodic cutoff",
+			demand: model.DemandHistogram{{Quantity: 1000001, Orders: 1}},
+			opts:   RecommendOptions{MaxPackSizes: 1, Candidates: []int{250, 500}},
+		},
+		{
+			name:        "Too many pack sets",
+			demand:      demand,
+			opts:        RecommendOptions{MaxPackSizes: 2, Candidates: []int{250, 500, 1000}},
+			expectedErr: model.ErrTooManyCandidatePackSets,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.RecommendPackSizes(ctx, tt.demand, tt.opts)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Expected %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestCountPackSets(t *testing.T) {
+	tests := []struct {
+		n, k     int
+		expected int
+	}{
+		{n: 4, k: 1, expected: 4},
+		{n: 4, k: 2, expected: 10},
+		{n: 4, k: 4, expected: 15},
+		{n: 20, k: 3, expected: 1350},
+		{n: 200, k: 100, expected: math.MaxInt},
+	}
+
+	for _, tt := range tests {
+		if got := countPackSets(tt.n, tt.k); got != tt.expected {
+			t.Errorf("countPackSets(%d, %d) = %d, expected %d", tt.n, tt.k, got, tt.expected)
+		}
+		if tt.expected < math.MaxInt {
+			if got := len(packSets(make([]int, tt.n), tt.k)); got != tt.expected {
+				t.Errorf("packSets(%d, %d) yielded %d sets, expected %d", tt.n, tt.k, got, tt.expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/infrastructure/logger"
+)
+
+// SolutionTable returns the solution table of packSizes for export, from
+// the cache when one is configured; a table built for export shares any
+// build in flight but is not cached. Without a cache the table is built up
+// to DefaultSolutionTableQuantity.
+func (ps *PackService) SolutionTable(
+	ctx context.Context,
+	packSizes []int,
+) (*SolutionTable, error) {
+	startTime := time.Now()
+
+	bound := DefaultSolutionTableQuantity
+	if ps.tables != nil {
+		bound = ps.tables.MaxQuantity()
+	}
+	// The bound is configured by the operator, so only the pack sizes are
+	// checked against the limits
+	if err := ps.checkLimits(packSizes, 0, 0); err != nil {
+		logger.Warn("Solution table rejected", map[string]interface{}{
+			"pack_sizes": packSizes,
+			"error":      err.Error(),
+		})
+		return nil, err
+	}
+
+	ctx, cancel := ps.solveContext(ctx)
+	defer cancel()
+
+	var table *SolutionTable
+	var err error
+	if ps.tables != nil {
+		table, err = ps.tables.Table(ctx, packSizes)
+	} else {
+		table, err = BuildSolutionTable(ctx, packSizes, bound)
+	}
+	if err != nil {
+		logger.Error("Solution table failed", map[string]interface{}{
+			"pack_sizes": packSizes,
+			"error":      err.Error(),
+		})
+		return nil, err
+	}
+
+	logger.Debug("Solution table ready", map[string]interface{}{
+		"pack_sizes":  table.PackSizes(),
+		"bound":       table.Bound(),
+		"duration_ms": time.Since(startTime).Milliseconds(),
+	})
+	return table, nil
+}
+
+// lookupSolution answers a calculation from its pack set's solution table.
+// Tables only hold the lexicographic optimum without stock, so any other
+// option makes the calculation go to the solver.
+func (ps *PackService) lookupSolution(
+	packSizes []int,
+	orderQuantity int,
+	opts SolveOptions,
+) ([]model.PackDistribution, bool) {
+	if ps.tables == nil || len(opts.Stock) > 0 || opts.Alternatives > 1 ||
+		!opts.Caps.IsZero() || !opts.Tolerance.IsZero() || !opts.Rules.IsZero() {
+		return nil, false
+	}
+	if _, ok := opts.Objective.(lexicographicObjective); opts.Objective != nil && !ok {
+		return nil, false
+	}
+	if _, err := validateInput(packSizes, []int{orderQuantity}); err != nil {
+		return nil, false
+	}
+	if opts.validate() != nil {
+		return nil, false
+	}
+
+	distribution, ok := ps.tables.Lookup(packSizes, orderQuantity)
+	if !ok {
+		return nil, false
+	}
+	return []model.PackDistribution{distribution}, true
+}
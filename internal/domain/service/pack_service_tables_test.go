@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"pack-calculator/internal/domain/model"
+)
+
+func TestPackService_SolutionTables(t *testing.T) {
+	ctx := context.Background()
+	packSizes := []int{23, 31, 53}
+	tables := NewSolutionTables(WithSolutionTableQuantity(2000), WithSolutionTableHotAfter(0))
+	if err := tables.Warm(ctx, [][]int{packSizes}); err != nil {
+		t.Fatalf("Warm: %v", err)
+	}
+	cached := NewPackService(WithSolutionTables(tables))
+	plain := NewPackService()
+
+	for _, opts := range []SolveOptions{
+		{},
+		{Objective: Lexicographic()},
+		{Objective: MinPackCount()},
+		{Stock: model.PackStock{53: 2}},
+		{Alternatives: 3},
+		{Rules: model.PackRules{23: {Required: true}}},
+	} {
+		for _, q := range []int{1, 263, 1999, 2000, 2001, 500000} {
+			got, err := cached.CalculateWithOptions(ctx, packSizes, q, opts)
+			if err != nil {
+				t.Fatalf("Cached calculation of %d: %v", q, err)
+			}
+			want, err := plain.CalculateWithOptions(ctx, packSizes, q, opts)
+			if err != nil {
+				t.Fatalf("Plain calculation of %d: %v", q, err)
+			}
+			if string(got.Distribution) != string(want.Distribution) ||
+				!reflect.DeepEqual(got.Alternatives, want.Alternatives) {
+				t.Errorf("%+v at %d: cached %s, plain %s", opts, q, got.Distribution, want.Distribution)
+			}
+		}
+	}
+
+	if tables.hits[packSetKey(packSizes)] == 0 {
+		t.Error("Expected plain calculations to be read from the table")
+	}
+
+	table, err := cached.SolutionTable(ctx, []int{53, 31, 23})
+	if err != nil {
+		t.Fatalf("SolutionTable: %v", err)
+	}
+	if table.Bound() != 2000 {
+		t.Errorf("Expected the cached table, got bound %d", table.Bound())
+	}
+
+	// Batches read the table too and solve only what it does not answer
+	batch := []BatchItem{
+		{PackSizes: packSizes, OrderQuantity: 263},
+		{PackSizes: packSizes, OrderQuantity: 500000},
+	}
+	hits := tables.hits[packSetKey(packSizes)]
+	results, err := cached.CalculateBatch(ctx, batch)
+	if err != nil {
+		t.Fatalf("CalculateBatch: %v", err)
+	}
+	for i, result := range results {
+		want, err := plain.CalculateOptimal(ctx, packSizes, batch[i].OrderQuantity)
+		if err != nil || result.Err != nil {
+			t.Fatalf("Calculation of %d: %v, %v", batch[i].OrderQuantity, err, result.Err)
+		}
+		if string(result.Calculation.Distribution) != string(want.Distribution) {
+			t.Errorf("Batch at %d: got %s, want %s", batch[i].OrderQuantity,
+				result.Calculation.Distribution, want.Distribution)
+		}
+	}
+	if got := tables.hits[packSetKey(packSizes)]; got != hits+1 {
+		t.Errorf("Expected one batch item to be read from the table, got %d", got-hits)
+	}
+
+	limited := NewPackService(WithLimits(Limits{MaxPackSizes: 2}))
+	if _, err := limited.SolutionTable(ctx, packSizes); !errors.Is(err, model.ErrTooManyPackSizes) {
+		t.Errorf("Expected ErrTooManyPackSizes, got %v", err)
+	}
+}
@@ -2,7 +2,14 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"pack-calculator/internal/domain/model"
 )
 
 func TestPackService_CalculateOptimal(t *testing.T) {
@@ -92,3 +99,265 @@ func TestPackService_CalculateOptimal(t *testing.T) {
 		})
 	}
 }
+
+// sequenceIDs issues deterministic IDs for tests
+type sequenceIDs struct {
+	prefix string
+	n      atomic.Int64
+}
+
+func (s *sequenceIDs) NewID() string {
+	return fmt.Sprintf("%s-%d", s.prefix, s.n.Add(1))
+}
+
+func TestPackService_IDGenerator(t *testing.T) {
+	service := NewPackService(WithIDGenerator(&sequenceIDs{prefix: "calc"}))
+	ctx := context.Background()
+
+	result, err := service.CalculateOptimal(ctx, []int{250, 500}, 251)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.ID != "calc-1" {
+		t.Errorf("Expected ID calc-1, got %s", result.ID)
+	}
+
+	results, err := service.CalculateBatch(ctx, []BatchItem{
+		{PackSizes: []int{250, 500}, OrderQuantity: 1},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if results[0].Calculation.ID != "calc-2" {
+		t.Errorf("Expected ID calc-2, got %s", results[0].Calculation.ID)
+	}
+}
+
+func TestPackService_MaxSolveTime(t *testing.T) {
+	service := NewPackService(WithMaxSolveTime(time.Millisecond))
+
+	// Far below the periodic cutoff, so every total is walked
+	_, err := service.CalculateOptimal(context.Background(), []int{99991, 99989}, 10000000)
+	if !errors.Is(err, model.ErrCalculationTimeout) {
+		t.Errorf("Expected ErrCalculationTimeout, got %v", err)
+	}
+}
+
+func TestPackService_Limits(t *testing.T) {
+	service := NewPackService(WithLimits(Limits{
+		MaxOrderQuantity: 1000,
+		MaxPackSizes:     3,
+		MaxPackSize:      500,
+	}))
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		packSizes     []int
+		orderQuantity int
+		opts          SolveOptions
+		expectedErr   error
+		// message is the full error text, when checked
+		message string
+	}{
+		{
+			name:          "Within limits",
+			packSizes:     []int{250, 500},
+			orderQuantity: 1000,
+		},
+		{
+			name:          "Order too large",
+			packSizes:     []int{499, 497},
+			orderQuantity: 1001,
+			expectedErr:   model.ErrOrderTooLarge,
+			message:       "order quantity exceeds maximum limit: 1001 > 1000",
+		},
+		{
+			// The cutoff of 499 and 500 leaves more than the limit to walk
+			name:          "Remainder too large",
+			packSizes:     []int{499, 500},
+			orderQuantity: 1000000000,
+			expectedErr:   model.ErrOrderTooLarge,
+			message:       "order quantity exceeds maximum limit: 1000000000 leaves 249500 to solve > 1000",
+		},
+		{
+			// Only 250 to 750 is left to walk once 500s are set aside
+			name:          "Order beyond the periodic cutoff",
+			packSizes:     []int{250, 500},
+			orderQuantity: 1000000001,
+		},
+		{
+			name:          "Order walked with ranked alternatives",
+			packSizes:     []int{250, 500},
+			orderQuantity: 1001,
+			opts:          SolveOptions{Alternatives: 2},
+			expectedErr:   model.ErrOrderTooLarge,
+		},
+		{
+			name:          "Too many pack sizes",
+			packSizes:     []int{100, 200, 300, 400},
+			orderQuantity: 500,
+			expectedErr:   model.ErrTooManyPackSizes,
+		},
+		{
+			name:          "Pack size too large",
+			packSizes:     []int{250, 501},
+			orderQuantity: 500,
+			expectedErr:   model.ErrPackSizeTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.CalculateWithOptions(ctx, tt.packSizes, tt.orderQuantity, tt.opts)
+
+			if tt.expectedErr == nil {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Expected %v, got %v", tt.expectedErr, err)
+			}
+			if tt.message != "" && err.Error() != tt.message {
+				t.Errorf("Expected %q, got %q", tt.message, err)
+			}
+		})
+	}
+}
+
+func TestPackService_Alternatives(t *testing.T) {
+	service := NewPackService(WithLimits(Limits{MaxAlternatives: 3}))
+	ctx := context.Background()
+	packSizes := []int{250, 500, 1000}
+
+	result, err := service.CalculateWithOptions(ctx, packSizes, 263, SolveOptions{Alternatives: 3})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []model.Alternative{
+		model.NewAlternative(model.PackDistribution{500: 1}, 263),
+		model.NewAlternative(model.PackDistribution{250: 2}, 263),
+		model.NewAlternative(model.PackDistribution{1000: 1}, 263),
+	}
+	if !reflect.DeepEqual(result.Alternatives, expected) {
+		t.Errorf("Expected alternatives %+v, got %+v", expected, result.Alternatives)
+	}
+	if !reflect.DeepEqual(result.GetDistribution(), expected[0].Distribution) {
+		t.Errorf("Expected the optimum first, got %v", result.GetDistribution())
+	}
+
+	result, err = service.CalculateOptimal(ctx, packSizes, 263)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Alternatives != nil {
+		t.Errorf("Expected no alternatives by default, got %+v", result.Alternatives)
+	}
+
+	_, err = service.CalculateWithOptions(ctx, packSizes, 263, SolveOptions{Alternatives: 4})
+	if !errors.Is(err, model.ErrTooManyAlternatives) {
+		t.Errorf("Expected ErrTooManyAlternatives, got %v", err)
+	}
+}
+
+func TestPackService_ShipmentCaps(t *testing.T) {
+	service := NewPackService()
+	ctx := context.Background()
+	packSizes := []int{250, 500, 1000}
+	measurements := map[int]model.PackMeasurements{
+		250:  {WeightGrams: 300, LengthMm: 100, WidthMm: 100, HeightMm: 50},
+		500:  {WeightGrams: 550, LengthMm: 100, WidthMm: 100, HeightMm: 100},
+		1000: {WeightGrams: 1050, LengthMm: 200, WidthMm: 100, HeightMm: 100},
+	}
+
+	result, err := service.CalculateWithOptions(ctx, packSizes, 1001, SolveOptions{
+		Measurements: measurements,
+		Caps:         model.ShipmentCaps{MaxWeightGrams: 2000},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.TotalWeightGrams != 1350 || result.TotalVolumeMm3 != 2500000 {
+		t.Errorf("Expected 1350g and 2500000mm3, got %dg and %dmm3",
+			result.TotalWeightGrams, result.TotalVolumeMm3)
+	}
+	if result.SplitRequired || result.MinShipments != 1 {
+		t.Errorf("Expected a single shipment, got split %v in %d",
+			result.SplitRequired, result.MinShipments)
+	}
+
+	// No distribution of 5000 items weighs under 2kg, so the optimum is
+	// returned with a split
+	result, err = service.CalculateWithOptions(ctx, packSizes, 5000, SolveOptions{
+		Measurements: measurements,
+		Caps:         model.ShipmentCaps{MaxWeightGrams: 2000},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := model.PackDistribution{1000: 5}
+	if !reflect.DeepEqual(result.GetDistribution(), expected) {
+		t.Errorf("Expected %v, got %v", expected, result.GetDistribution())
+	}
+	if !result.SplitRequired || result.MinShipments != 3 {
+		t.Errorf("Expected a split into 3 shipments, got split %v in %d",
+			result.SplitRequired, result.MinShipments)
+	}
+
+	_, err = service.CalculateWithOptions(ctx, packSizes, 1001, SolveOptions{
+		Measurements: measurements,
+		Caps:         model.ShipmentCaps{MaxPackWeightGrams: 200},
+	})
+	if !errors.Is(err, model.ErrPackTooHeavy) {
+		t.Errorf("Expected ErrPackTooHeavy, got %v", err)
+	}
+}
+
+func TestPackService_Tolerance(t *testing.T) {
+	service := NewPackService()
+	ctx := context.Background()
+	packSizes := []int{250, 500, 1000}
+
+	result, err := service.CalculateOptimal(ctx, packSizes, 1001)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.ItemsDelta != 249 || result.ItemsOverage != 249 {
+		t.Errorf("Expected 249 over, got delta %d and overage %d",
+			result.ItemsDelta, result.ItemsOverage)
+	}
+
+	costs := model.CostModel{ItemCost: 2}
+	result, err = service.CalculateWithOptions(ctx, packSizes, 1001, SolveOptions{
+		Objective: MinTotalCost(costs),
+		Tolerance: model.Tolerance{MaxShortfall: 5},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.TotalItems != 1000 || result.ItemsDelta != -1 || result.ItemsOverage != 0 {
+		t.Errorf("Expected 1000 items, 1 short, got %d items, delta %d and overage %d",
+			result.TotalItems, result.ItemsDelta, result.ItemsOverage)
+	}
+	if result.TotalCost != 2 {
+		t.Errorf("Expected the shortfall to cost 2, got %d", result.TotalCost)
+	}
+
+	// The short distribution exceeds the caps, so it is returned with a split
+	result, err = service.CalculateWithOptions(ctx, packSizes, 1001, SolveOptions{
+		Measurements: map[int]model.PackMeasurements{1000: {WeightGrams: 1050}},
+		Caps:         model.ShipmentCaps{MaxWeightGrams: 1000},
+		Tolerance:    model.Tolerance{MaxShortfall: 5},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.ItemsDelta != -1 || !result.SplitRequired {
+		t.Errorf("Expected a short split order, got delta %d and split %v",
+			result.ItemsDelta, result.SplitRequired)
+	}
+}
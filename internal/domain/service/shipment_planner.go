@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"pack-calculator/internal/domain/model"
+)
+
+// ShipmentPlanner splits the pack distribution of a calculation across
+// shipments when it exceeds what one shipment can carry
+type ShipmentPlanner struct {
+	maxShipments int
+}
+
+// ShipmentPlannerOption configures optional ShipmentPlanner behaviour
+type ShipmentPlannerOption func(*ShipmentPlanner)
+
+// WithMaxShipments rejects plans that need more than n shipments; zero
+// disables the limit
+func WithMaxShipments(n int) ShipmentPlannerOption {
+	return func(sp *ShipmentPlanner) {
+		sp.maxShipments = n
+	}
+}
+
+// NewShipmentPlanner creates a shipment planner
+func NewShipmentPlanner(opts ...ShipmentPlannerOption) *ShipmentPlanner {
+	sp := &ShipmentPlanner{}
+	for _, opt := range opts {
+		opt(sp)
+	}
+	return sp
+}
+
+// Plan splits distribution into shipments within capacity, linked to the
+// calculation it came from. Shipments are listed largest first.
+//
+// The plan starts from the fewest shipments the capacity allows and deals
+// the packs out largest first, in bulk: every shipment with room gets an
+// equal share of a size and the rest go one each to the shipments holding
+// the fewest items so far. When a pack fits nowhere one more shipment is
+// tried, so the shipments stay as even as the pack sizes permit.
+// ErrPackExceedsCapacity is returned when a single pack holds more than
+// MaxItems, and ErrTooManyShipments before any dealing when the packs
+// need more shipments than the limit.
+func (sp *ShipmentPlanner) Plan(
+	ctx context.Context,
+	calculationID string,
+	distribution model.PackDistribution,
+	capacity model.ShipmentCapacity,
+) ([]model.Shipment, error) {
+	if err := capacity.Validate(); err != nil {
+		return nil, err
+	}
+
+	var sizes []int
+	for size, count := range distribution {
+		if count > 0 {
+			sizes = append(sizes, size)
+		}
+	}
+	if len(sizes) == 0 {
+		return nil, nil
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+	if capacity.MaxItems > 0 && sizes[0] > capacity.MaxItems {
+		return nil, model.ErrPackExceedsCapacity
+	}
+
+	totalPacks := distribution.TotalPacks()
+	count := 1
+	if capacity.MaxPacks > 0 {
+		count = max(count, ceilDiv(totalPacks, capacity.MaxPacks))
+	}
+	if capacity.MaxItems > 0 {
+		totalItems := distribution.TotalItems()
+		count = max(count, ceilDiv(totalItems, capacity.MaxItems))
+	}
+
+	if sp.maxShipments > 0 && count > sp.maxShipments {
+		return nil, fmt.Errorf("%w: %d > %d", model.ErrTooManyShipments, count, sp.maxShipments)
+	}
+
+	// One pack per shipment always fits, so the search ends by totalPacks
+	for {
+		loads, ok, err := dealPacks(ctx, sizes, distribution, capacity, count)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return shipments(calculationID, loads), nil
+		}
+		if count++; sp.maxShipments > 0 && count > sp.maxShipments {
+			return nil, model.ErrTooManyShipments
+		}
+	}
+}
+
+// dealPacks assigns the packs to count shipments, largest first, and
+// reports whether every pack fitted. Each round hands every shipment with
+// room an equal share of the packs left, as far as its room allows, so a
+// round either deals them all, leaving fewer than one per shipment for the
+// lightest shipments, or fills a shipment; the rounds are bounded by count
+// whatever the number of packs.
+func dealPacks(
+	ctx context.Context,
+	sizes []int,
+	distribution model.PackDistribution,
+	capacity model.ShipmentCapacity,
+	count int,
+) ([]*shipmentLoad, bool, error) {
+	loads := make([]*shipmentLoad, count)
+	for i := range loads {
+		loads[i] = &shipmentLoad{index: i, packs: make(model.PackDistribution)}
+	}
+
+	open := make(lightestFirst, 0, count)
+	for _, size := range sizes {
+		for left := distribution[size]; left > 0; {
+			if err := ctx.Err(); err != nil {
+				return nil, false, contextError(err)
+			}
+
+			open = open[:0]
+			for _, load := range loads {
+				if load.room(size, capacity) > 0 {
+					open = append(open, load)
+				}
+			}
+			if len(open) == 0 {
+				return nil, false, nil
+			}
+			sort.Sort(open)
+
+			share := left / len(open)
+			if share == 0 {
+				for _, load := range open[:left] {
+					load.add(size, 1)
+				}
+				break
+			}
+			for _, load := range open {
+				n := min(share, load.room(size, capacity))
+				load.add(size, n)
+				left -= n
+			}
+		}
+	}
+	return loads, true, nil
+}
+
+// shipments orders the loads largest first and numbers them
+func shipments(calculationID string, loads []*shipmentLoad) []model.Shipment {
+	sort.SliceStable(loads, func(i, j int) bool {
+		if loads[i].items != loads[j].items {
+			return loads[i].items > loads[j].items
+		}
+		return loads[i].count > loads[j].count
+	})
+
+	result := make([]model.Shipment, len(loads))
+	for i, load := range loads {
+		result[i] = model.Shipment{
+			ID:            fmt.Sprintf("%s-%d", calculationID, i+1),
+			CalculationID: calculationID,
+			Sequence:      i + 1,
+			Packs:         load.packs,
+			TotalItems:    load.items,
+			TotalPacks:    load.count,
+		}
+	}
+	return result
+}
+
+// shipmentLoad is a shipment being filled
+type shipmentLoad struct {
+	index int
+	packs model.PackDistribution
+	items int
+	count int
+}
+
+// room returns how many more packs of size fit in the shipment
+func (l *shipmentLoad) room(size int, capacity model.ShipmentCapacity) int {
+	room := math.MaxInt
+	if capacity.MaxPacks > 0 {
+		room = capacity.MaxPacks - l.count
+	}
+	if capacity.MaxItems > 0 {
+		room = min(room, (capacity.MaxItems-l.items)/size)
+	}
+	return room
+}
+
+// add puts n packs of size in the shipment
+func (l *shipmentLoad) add(size, n int) {
+	l.packs[size] += n
+	l.items += n * size
+	l.count += n
+}
+
+// lightestFirst orders shipments by the items they hold, then their packs
+type lightestFirst []*shipmentLoad
+
+func (q lightestFirst) Len() int { return len(q) }
+
+func (q lightestFirst) Less(i, j int) bool {
+	if q[i].items != q[j].items {
+		return q[i].items < q[j].items
+	}
+	if q[i].count != q[j].count {
+		return q[i].count < q[j].count
+	}
+	return q[i].index < q[j].index
+}
+
+func (q lightestFirst) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
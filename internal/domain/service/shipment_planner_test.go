@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"pack-calculator/internal/domain/model"
+)
+
+func TestShipmentPlanner_Plan(t *testing.T) {
+	planner := NewShipmentPlanner(WithMaxShipments(10))
+
+	tests := []struct {
+		name         string
+		distribution model.PackDistribution
+		capacity     model.ShipmentCapacity
+		expected     []model.PackDistribution
+		expectedErr  error
+	}{
+		{
+			name:         "Fits one shipment",
+			distribution: model.PackDistribution{5000: 2, 2000: 1, 250: 1},
+			capacity:     model.ShipmentCapacity{MaxPacks: 4},
+			expected:     []model.PackDistribution{{5000: 2, 2000: 1, 250: 1}},
+		},
+		{
+			name:         "Packs dealt evenly",
+			distribution: model.PackDistribution{250: 10},
+			capacity:     model.ShipmentCapacity{MaxPacks: 4},
+			expected: []model.PackDistribution{
+				{250: 4},
+				{250: 3},
+				{250: 3},
+			},
+		},
+		{
+			name:         "Large packs spread first",
+			distribution: model.PackDistribution{5000: 2, 2000: 1, 250: 1},
+			capacity:     model.ShipmentCapacity{MaxPacks: 2},
+			expected: []model.PackDistribution{
+				{5000: 1, 2000: 1},
+				{5000: 1, 250: 1},
+			},
+		},
+		{
+			name:         "Item capacity",
+			distribution: model.PackDistribution{1000: 1, 250: 4},
+			capacity:     model.ShipmentCapacity{MaxItems: 1000},
+			expected: []model.PackDistribution{
+				{250: 4},
+				{1000: 1},
+			},
+		},
+		{
+			name:         "Uneven sizes need an extra shipment",
+			distribution: model.PackDistribution{600: 3},
+			capacity:     model.ShipmentCapacity{MaxItems: 1000},
+			expected: []model.PackDistribution{
+				{600: 1},
+				{600: 1},
+				{600: 1},
+			},
+		},
+		{
+			name:         "Both limits",
+			distribution: model.PackDistribution{500: 2, 250: 4},
+			capacity:     model.ShipmentCapacity{MaxPacks: 3, MaxItems: 1000},
+			expected: []model.PackDistribution{
+				{500: 1, 250: 2},
+				{500: 1, 250: 2},
+			},
+		},
+		{
+			name:         "Pack larger than a shipment",
+			distribution: model.PackDistribution{5000: 1},
+			capacity:     model.ShipmentCapacity{MaxItems: 1000},
+			expectedErr:  model.ErrPackExceedsCapacity,
+		},
+		{
+			name:         "Too many shipments",
+			distribution: model.PackDistribution{250: 11},
+			capacity:     model.ShipmentCapacity{MaxPacks: 1},
+			expectedErr:  model.ErrTooManyShipments,
+		},
+		{
+			name:         "No capacity",
+			distribution: model.PackDistribution{250: 1},
+			expectedErr:  model.ErrInvalidShipmentCapacity,
+		},
+		{
+			name:         "Negative capacity",
+			distribution: model.PackDistribution{250: 1},
+			capacity:     model.ShipmentCapacity{MaxPacks: 2, MaxItems: -1},
+			expectedErr:  model.ErrInvalidShipmentCapacity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := planner.Plan(context.Background(), "calc", tt.distribution, tt.capacity)
+
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("Expected %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			got := make([]model.PackDistribution, len(plan))
+			for i, shipment := range plan {
+				got[i] = shipment.Packs
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestShipmentPlanner_PlanHugeDistribution(t *testing.T) {
+	planner := NewShipmentPlanner(WithMaxShipments(10))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// Two billion packs, as a periodic solve returns for a huge order, are
+	// dealt in bulk
+	distribution := model.PackDistribution{500: 2e9, 250: 1}
+	start := time.Now()
+	plan, err := planner.Plan(ctx, "calc", distribution, model.ShipmentCapacity{MaxPacks: 1e9 + 1})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Plan took %v", elapsed)
+	}
+	expected := []model.PackDistribution{{500: 1e9, 250: 1}, {500: 1e9}}
+	got := []model.PackDistribution{plan[0].Packs, plan[1].Packs}
+	if len(plan) != 2 || !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	// More packs than the shipment limit carries are rejected up front
+	_, err = planner.Plan(ctx, "calc", distribution, model.ShipmentCapacity{MaxPacks: 1e8})
+	if !errors.Is(err, model.ErrTooManyShipments) {
+		t.Errorf("Expected ErrTooManyShipments, got %v", err)
+	}
+
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	_, err = planner.Plan(canceled, "calc", distribution, model.ShipmentCapacity{MaxPacks: 1e9})
+	if !errors.Is(err, model.ErrCalculationCanceled) {
+		t.Errorf("Expected ErrCalculationCanceled, got %v", err)
+	}
+}
+
+func TestShipmentPlanner_PlanLinksShipments(t *testing.T) {
+	distribution := model.PackDistribution{1000: 3, 500: 1}
+	plan, err := NewShipmentPlanner().Plan(
+		context.Background(), "01JHF3K2Q8", distribution, model.ShipmentCapacity{MaxPacks: 2},
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []model.Shipment{
+		{
+			ID:            "01JHF3K2Q8-1",
+			CalculationID: "01JHF3K2Q8",
+			Sequence:      1,
+			Packs:         model.PackDistribution{1000: 2},
+			TotalItems:    2000,
+			TotalPacks:    2,
+		},
+		{
+			ID:            "01JHF3K2Q8-2",
+			CalculationID: "01JHF3K2Q8",
+			Sequence:      2,
+			Packs:         model.PackDistribution{1000: 1, 500: 1},
+			TotalItems:    1500,
+			TotalPacks:    2,
+		},
+	}
+	if !reflect.DeepEqual(plan, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, plan)
+	}
+
+	// Every pack of the distribution is shipped exactly once
+	shipped := make(model.PackDistribution)
+	for _, shipment := range plan {
+		for size, count := range shipment.Packs {
+			shipped[size] += count
+		}
+	}
+	if !reflect.DeepEqual(shipped, distribution) {
+		t.Errorf("Expected %v shipped, got %v", distribution, shipped)
+	}
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"pack-calculator/internal/domain/model"
+)
+
+// solutionTableMagic and solutionTableVersion open every exported table
+const (
+	solutionTableMagic   = "PKST"
+	solutionTableVersion = 2
+)
+
+// SolutionTable holds the optimal distribution, under the lexicographic
+// objective, of every order quantity from 1 to a bound for one pack set.
+// A lookup reads one row of pack counts, so it costs the same for any
+// quantity. Tables are immutable and safe for concurrent use.
+type SolutionTable struct {
+	// sizes are normalised and scale is their GCD; counts holds a row of
+	// len(sizes) pack counts per multiple of scale, which answers every
+	// order quantity above the previous multiple
+	sizes  []int
+	scale  int
+	bound  int
+	counts []uint32
+}
+
+// BuildSolutionTable solves every order quantity up to bound against
+// packSizes from a single pack count table. Each reachable total inherits
+// the counts of the total its last pack was added to, so the rows are
+// filled in one pass over the totals. Totals count in multiples of the GCD
+// of the sizes, like Solve, so the table holds one row per multiple.
+func BuildSolutionTable(
+	ctx context.Context,
+	packSizes []int,
+	bound int,
+) (*SolutionTable, error) {
+	if _, err := validateInput(packSizes, []int{bound}); err != nil {
+		return nil, err
+	}
+
+	sizes := normalizePackSizes(packSizes)
+	scale := packSizesGCD(sizes)
+	reduced := scaleDown(sizes, scale)
+	rows := ceilDiv(bound, scale)
+	table, err := buildPackTable(ctx, reduced, unitCosts(reduced), rows+reduced[0])
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[int]int, len(reduced))
+	for i, size := range reduced {
+		index[size] = i
+	}
+
+	k := len(sizes)
+	totals := make([]uint32, len(table.cost)*k)
+	done := ctx.Done()
+	for t := 1; t < len(table.cost); t++ {
+		if t&(cancelCheckInterval-1) == 0 {
+			select {
+			case <-done:
+				return nil, contextError(ctx.Err())
+			default:
+			}
+		}
+		if table.cost[t] == unreachable {
+			continue
+		}
+		size := int(table.last[t])
+		copy(totals[t*k:(t+1)*k], totals[(t-size)*k:(t-size+1)*k])
+		totals[t*k+index[size]]++
+	}
+
+	// Each quantity ships the smallest reachable total that covers it
+	counts := make([]uint32, rows*k)
+	next := -1
+	for t := len(table.cost) - 1; t >= 1; t-- {
+		if table.cost[t] != unreachable {
+			next = t
+		}
+		if t <= rows {
+			copy(counts[(t-1)*k:t*k], totals[next*k:(next+1)*k])
+		}
+	}
+
+	return &SolutionTable{sizes: sizes, scale: scale, bound: bound, counts: counts}, nil
+}
+
+// PackSizes returns the pack sizes of the table, largest first
+func (t *SolutionTable) PackSizes() []int {
+	return append([]int(nil), t.sizes...)
+}
+
+// Bound returns the largest order quantity the table answers
+func (t *SolutionTable) Bound() int {
+	return t.bound
+}
+
+// Lookup returns the distribution for orderQuantity, or false when it is
+// outside the table
+func (t *SolutionTable) Lookup(orderQuantity int) (model.PackDistribution, bool) {
+	if orderQuantity < 1 || orderQuantity > t.bound {
+		return nil, false
+	}
+	k := len(t.sizes)
+	r := ceilDiv(orderQuantity, t.scale)
+	row := t.counts[(r-1)*k : r*k]
+	distribution := make(model.PackDistribution)
+	for i, count := range row {
+		if count > 0 {
+			distribution[t.sizes[i]] = int(count)
+		}
+	}
+	return distribution, true
+}
+
+// MarshalBinary encodes the table for export: the "PKST" magic and a
+// version byte, then the number of sizes, the bound, the sizes and every
+// row of pack counts as unsigned varints, one row per multiple of the
+// sizes' GCD up to the bound, and finally the IEEE CRC-32 of everything
+// before it, little endian.
+func (t *SolutionTable) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, len(solutionTableMagic)+1+len(t.counts)+4)
+	data = append(data, solutionTableMagic...)
+	data = append(data, solutionTableVersion)
+	data = binary.AppendUvarint(data, uint64(len(t.sizes)))
+	data = binary.AppendUvarint(data, uint64(t.bound))
+	for _, size := range t.sizes {
+		data = binary.AppendUvarint(data, uint64(size))
+	}
+	for _, count := range t.counts {
+		data = binary.AppendUvarint(data, uint64(count))
+	}
+	return binary.LittleEndian.AppendUint32(data, crc32.ChecksumIEEE(data)), nil
+}
+
+// UnmarshalBinary decodes a table written by MarshalBinary. It returns
+// ErrInvalidSolutionTable when the data is corrupt or of another version,
+// or when a row falls short of its multiple of the sizes' GCD or
+// overshoots it by the largest size or more. Rows are not checked to be optimal; only the
+// checksum guards against a table that was built wrong.
+func (t *SolutionTable) UnmarshalBinary(data []byte) error {
+	header := len(solutionTableMagic) + 1
+	if len(data) < header+4 || !bytes.HasPrefix(data, []byte(solutionTableMagic)) {
+		return fmt.Errorf("%w: not a solution table", model.ErrInvalidSolutionTable)
+	}
+	if version := data[len(solutionTableMagic)]; version != solutionTableVersion {
+		return fmt.Errorf("%w: version %d", model.ErrInvalidSolutionTable, version)
+	}
+	body, sum := data[:len(data)-4], binary.LittleEndian.Uint32(data[len(data)-4:])
+	if crc32.ChecksumIEEE(body) != sum {
+		return fmt.Errorf("%w: checksum mismatch", model.ErrInvalidSolutionTable)
+	}
+
+	reader := bytes.NewReader(body[header:])
+	read := func() (int, error) {
+		value, err := binary.ReadUvarint(reader)
+		if err != nil || value > uint64(unreachable) {
+			return 0, fmt.Errorf("%w: truncated", model.ErrInvalidSolutionTable)
+		}
+		return int(value), nil
+	}
+
+	k, err := read()
+	if err != nil {
+		return err
+	}
+	bound, err := read()
+	if err != nil {
+		return err
+	}
+	if k == 0 || bound == 0 || reader.Len() < k {
+		return fmt.Errorf("%w: truncated", model.ErrInvalidSolutionTable)
+	}
+
+	sizes := make([]int, k)
+	for i := range sizes {
+		if sizes[i], err = read(); err != nil {
+			return err
+		}
+		if sizes[i] <= 0 || (i > 0 && sizes[i] >= sizes[i-1]) {
+			return fmt.Errorf("%w: pack sizes", model.ErrInvalidSolutionTable)
+		}
+	}
+
+	// Rows are checked in multiples of the GCD, as they were built
+	scale := packSizesGCD(sizes)
+	reduced := scaleDown(sizes, scale)
+	rows := ceilDiv(bound, scale)
+	if reader.Len() < rows*k {
+		return fmt.Errorf("%w: truncated", model.ErrInvalidSolutionTable)
+	}
+	counts := make([]uint32, rows*k)
+	for q := 1; q <= rows; q++ {
+		total := 0
+		for i, size := range reduced {
+			count, err := read()
+			if err != nil {
+				return err
+			}
+			// Checked per size so the total cannot overflow
+			if total += count * size; count > q || total >= q+reduced[0] {
+				return fmt.Errorf("%w: row %d", model.ErrInvalidSolutionTable, q)
+			}
+			counts[(q-1)*k+i] = uint32(count)
+		}
+		if total < q {
+			return fmt.Errorf("%w: row %d", model.ErrInvalidSolutionTable, q)
+		}
+	}
+	if reader.Len() > 0 {
+		return fmt.Errorf("%w: trailing data", model.ErrInvalidSolutionTable)
+	}
+
+	*t = SolutionTable{sizes: sizes, scale: scale, bound: bound, counts: counts}
+	return nil
+}
+
+// ReadSolutionTable loads a table exported with MarshalBinary
+func ReadSolutionTable(r io.Reader) (*SolutionTable, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	table := &SolutionTable{}
+	if err := table.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return table, nil
+}
@@ -0,0 +1,374 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/domain/repository"
+	"pack-calculator/internal/infrastructure/persistence"
+)
+
+func TestSolutionTable_MatchesSolver(t *testing.T) {
+	calculator := NewPackCalculator()
+	ctx := context.Background()
+	const bound = 3000
+
+	packSets := [][]int{
+		{250, 500, 1000, 2000, 5000},
+		{23, 31, 53},
+		{6, 9, 20},
+		{4, 6},
+		{1},
+		{500, 250, 250},
+	}
+	for _, packSizes := range packSets {
+		table, err := BuildSolutionTable(ctx, packSizes, bound)
+		if err != nil {
+			t.Fatalf("BuildSolutionTable(%v): %v", packSizes, err)
+		}
+		if !reflect.DeepEqual(table.PackSizes(), normalizePackSizes(packSizes)) {
+			t.Errorf("Expected sizes %v, got %v", normalizePackSizes(packSizes), table.PackSizes())
+		}
+		// One row per multiple of the GCD of the sizes
+		sizes := normalizePackSizes(packSizes)
+		if rows := ceilDiv(bound, packSizesGCD(sizes)); len(table.counts) != rows*len(sizes) {
+			t.Errorf("%v: expected %d rows, got %d", packSizes, rows, len(table.counts)/len(sizes))
+		}
+
+		for q := 1; q <= bound; q++ {
+			got, ok := table.Lookup(q)
+			if !ok {
+				t.Fatalf("%v: no entry for %d", packSizes, q)
+			}
+			ranked, err := calculator.Rank(ctx, packSizes, q, SolveOptions{})
+			if err != nil {
+				t.Fatalf("Rank(%v, %d): %v", packSizes, q, err)
+			}
+			if !reflect.DeepEqual(got, ranked[0]) {
+				t.Fatalf("%v at %d: table %v, solver %v", packSizes, q, got, ranked[0])
+			}
+		}
+	}
+
+	table, _ := BuildSolutionTable(ctx, []int{250, 500}, 100)
+	for _, q := range []int{0, -1, 101} {
+		if _, ok := table.Lookup(q); ok {
+			t.Errorf("Expected no entry for %d", q)
+		}
+	}
+
+	if _, err := BuildSolutionTable(ctx, nil, 100); !errors.Is(err, model.ErrEmptyPackSizes) {
+		t.Errorf("Expected ErrEmptyPackSizes, got %v", err)
+	}
+	_, err := BuildSolutionTable(ctx, []int{250}, 0)
+	if !errors.Is(err, model.ErrInvalidOrderQuantity) {
+		t.Errorf("Expected ErrInvalidOrderQuantity, got %v", err)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = BuildSolutionTable(canceled, []int{23, 31, 53}, 100000)
+	if !errors.Is(err, model.ErrCalculationCanceled) {
+		t.Errorf("Expected ErrCalculationCanceled, got %v", err)
+	}
+}
+
+func TestSolutionTable_Binary(t *testing.T) {
+	table, err := BuildSolutionTable(context.Background(), []int{23, 31, 53}, 1000)
+	if err != nil {
+		t.Fatalf("BuildSolutionTable: %v", err)
+	}
+	data, err := table.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+
+	loaded, err := ReadSolutionTable(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadSolutionTable: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, table) {
+		t.Fatal("Expected the loaded table to equal the exported one")
+	}
+
+	// Rows of sizes with a common divisor cover several quantities each
+	scaled, err := BuildSolutionTable(context.Background(), []int{250, 500, 1000}, 1001)
+	if err != nil {
+		t.Fatalf("BuildSolutionTable: %v", err)
+	}
+	scaledData, err := scaled.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary: %v", err)
+	}
+	if loaded, err := ReadSolutionTable(bytes.NewReader(scaledData)); err != nil ||
+		!reflect.DeepEqual(loaded, scaled) {
+		t.Fatalf("Expected the scaled table to load unchanged, got %v", err)
+	}
+
+	corrupt := func(mutate func([]byte) []byte) []byte {
+		return mutate(append([]byte(nil), data...))
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"Empty", nil},
+		{"Wrong magic", corrupt(func(b []byte) []byte { b[0] = 'X'; return b })},
+		{"Other version", corrupt(func(b []byte) []byte { b[4] = 1; return b })},
+		{"Flipped bit", corrupt(func(b []byte) []byte { b[len(b)/2] ^= 1; return b })},
+		{"Truncated", corrupt(func(b []byte) []byte { return b[:len(b)-10] })},
+		{"Trailing data", corrupt(func(b []byte) []byte { return append(b, 0) })},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var table SolutionTable
+			if err := table.UnmarshalBinary(tt.data); !errors.Is(err, model.ErrInvalidSolutionTable) {
+				t.Errorf("Expected ErrInvalidSolutionTable, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSolutionTables(t *testing.T) {
+	ctx := context.Background()
+	packSizes := []int{250, 500, 1000}
+
+	t.Run("Hot pack set gets a table", func(t *testing.T) {
+		tables := NewSolutionTables(WithSolutionTableQuantity(5000), WithSolutionTableHotAfter(3))
+		for i := 0; i < 3; i++ {
+			if _, ok := tables.Lookup(packSizes, 263); ok {
+				t.Fatalf("Expected a miss on solve %d", i+1)
+			}
+		}
+
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			// Listed in another order, the pack set is the same
+			distribution, ok := tables.Lookup([]int{1000, 250, 500}, 263)
+			if ok {
+				if !reflect.DeepEqual(distribution, model.PackDistribution{500: 1}) {
+					t.Errorf("Expected {500: 1}, got %v", distribution)
+				}
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("Expected the table to be built in the background")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		if _, ok := tables.Lookup(packSizes, 5001); ok {
+			t.Error("Expected quantities over the bound to miss")
+		}
+
+		tables.Invalidate()
+		if _, ok := tables.Lookup(packSizes, 263); ok {
+			t.Error("Expected Invalidate to drop the table")
+		}
+	})
+
+	t.Run("Least used table is evicted", func(t *testing.T) {
+		tables := NewSolutionTables(
+			WithSolutionTableQuantity(1000),
+			WithMaxSolutionTables(2),
+			WithSolutionTableHotAfter(0),
+		)
+		if err := tables.Warm(ctx, [][]int{{250, 500}, {6, 9, 20}}); err != nil {
+			t.Fatalf("Warm: %v", err)
+		}
+		tables.Lookup([]int{250, 500}, 300)
+		if err := tables.Warm(ctx, [][]int{{4, 6}}); err != nil {
+			t.Fatalf("Warm: %v", err)
+		}
+
+		if _, ok := tables.Lookup([]int{250, 500}, 300); !ok {
+			t.Error("Expected the used table to stay")
+		}
+		if _, ok := tables.Lookup([]int{6, 9, 20}, 43); ok {
+			t.Error("Expected the unused table to be evicted")
+		}
+		if _, ok := tables.Lookup([]int{4, 6}, 10); !ok {
+			t.Error("Expected the new table to be cached")
+		}
+	})
+
+	t.Run("Exported tables are not cached", func(t *testing.T) {
+		tables := NewSolutionTables(
+			WithSolutionTableQuantity(1000),
+			WithMaxSolutionTables(1),
+			WithSolutionTableHotAfter(0),
+		)
+		if err := tables.Warm(ctx, [][]int{{250, 500}}); err != nil {
+			t.Fatalf("Warm: %v", err)
+		}
+		table, err := tables.Table(ctx, []int{4, 6})
+		if err != nil {
+			t.Fatalf("Table: %v", err)
+		}
+		if distribution, ok := table.Lookup(10); !ok || distribution.TotalItems() != 10 {
+			t.Errorf("Expected the exported table to answer 10, got %v", distribution)
+		}
+
+		if _, ok := tables.Lookup([]int{250, 500}, 300); !ok {
+			t.Error("Expected the export to leave the hot table cached")
+		}
+		if _, ok := tables.Lookup([]int{4, 6}, 10); ok {
+			t.Error("Expected the exported table not to be cached")
+		}
+	})
+
+	t.Run("Callers share a build", func(t *testing.T) {
+		tables := NewSolutionTables(WithSolutionTableQuantity(1000), WithSolutionTableHotAfter(0))
+		sizes := normalizePackSizes(packSizes)
+		key := packSetKey(sizes)
+
+		tables.mu.Lock()
+		export := tables.start(key, sizes, false)
+		warm := tables.start(key, sizes, true)
+		tables.mu.Unlock()
+		if export != warm {
+			t.Fatal("Expected one build for the pack set")
+		}
+
+		exported, err := export.wait(ctx)
+		if err != nil {
+			t.Fatalf("Build: %v", err)
+		}
+		cached, err := tables.Table(ctx, packSizes)
+		if err != nil {
+			t.Fatalf("Table: %v", err)
+		}
+		// The warm-up asked for the shared table to be cached
+		if cached != exported {
+			t.Error("Expected the shared build to be cached")
+		}
+
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err = tables.Table(canceled, []int{23, 31, 53})
+		if !errors.Is(err, model.ErrCalculationCanceled) {
+			t.Errorf("Expected ErrCalculationCanceled, got %v", err)
+		}
+	})
+
+	t.Run("Builds time out", func(t *testing.T) {
+		tables := NewSolutionTables(
+			WithSolutionTableQuantity(10000000),
+			WithSolutionTableBuildTimeout(time.Millisecond),
+		)
+		_, err := tables.Table(ctx, []int{23, 31, 53})
+		if !errors.Is(err, model.ErrCalculationTimeout) {
+			t.Errorf("Expected ErrCalculationTimeout, got %v", err)
+		}
+	})
+
+	t.Run("Failed builds back off", func(t *testing.T) {
+		tables := NewSolutionTables(
+			WithSolutionTableQuantity(10000000),
+			WithSolutionTableHotAfter(1),
+			WithSolutionTableBuildTimeout(time.Millisecond),
+		)
+		packSizes := []int{23, 31, 53}
+		key := packSetKey(normalizePackSizes(packSizes))
+
+		tables.Lookup(packSizes, 263)
+		tables.mu.Lock()
+		build := tables.building[key]
+		tables.mu.Unlock()
+		if build == nil {
+			t.Fatal("Expected the hot pack set to start a build")
+		}
+		if _, err := build.wait(ctx); !errors.Is(err, model.ErrCalculationTimeout) {
+			t.Fatalf("Expected ErrCalculationTimeout, got %v", err)
+		}
+
+		tables.Lookup(packSizes, 263)
+		tables.mu.Lock()
+		_, rebuilding := tables.building[key]
+		tables.mu.Unlock()
+		if rebuilding {
+			t.Error("Expected the next miss not to rebuild the failed table")
+		}
+	})
+
+	t.Run("Reload warms the stored catalogs", func(t *testing.T) {
+		repo := persistence.NewMemoryPackRepository()
+		packConfig := NewPackConfigService(repo)
+		for _, in := range []PackInput{
+			{Size: 250, Name: "Small"},
+			{Size: 500, Name: "Medium"},
+			{Size: 6, Name: "Bolt box", SKU: "bolts"},
+		} {
+			if _, err := packConfig.Create(ctx, in); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+
+		tables := NewSolutionTables(WithSolutionTableQuantity(1000), WithSolutionTableHotAfter(0))
+		if err := tables.Reload(ctx, repo); err != nil {
+			t.Fatalf("Reload: %v", err)
+		}
+		if _, ok := tables.Lookup([]int{250, 500}, 263); !ok {
+			t.Error("Expected the default catalog to be warmed up")
+		}
+		if _, ok := tables.Lookup([]int{6}, 7); !ok {
+			t.Error("Expected the bolts catalog to be warmed up")
+		}
+
+		// Reloading one SKU leaves the other catalogs cached
+		sku := "bolts"
+		bolts, err := packConfig.List(ctx, repository.PackFilter{SKU: &sku})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		update := PackInput{Size: 9, Name: "Bolt box", SKU: "bolts"}
+		if _, err := packConfig.Update(ctx, bolts[0].ID, update); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if err := tables.Reload(ctx, repo, "bolts"); err != nil {
+			t.Fatalf("Reload: %v", err)
+		}
+		if _, ok := tables.Lookup([]int{6}, 7); ok {
+			t.Error("Expected the old bolts pack set to be dropped")
+		}
+		if _, ok := tables.Lookup([]int{9}, 7); !ok {
+			t.Error("Expected the new bolts pack set to be warmed up")
+		}
+		if _, ok := tables.Lookup([]int{250, 500}, 263); !ok {
+			t.Error("Expected the default catalog to stay cached")
+		}
+	})
+
+	t.Run("Watch reloads changed catalogs", func(t *testing.T) {
+		repo := persistence.NewMemoryPackRepository()
+		tables := NewSolutionTables(WithSolutionTableQuantity(1000), WithSolutionTableHotAfter(0))
+		packConfig := NewPackConfigService(repo, WithPackChangeHook(tables.Refresh))
+		watching, stop := context.WithCancel(ctx)
+		defer stop()
+		go tables.Watch(watching, repo)
+
+		for _, in := range []PackInput{
+			{Size: 250, Name: "Small"},
+			{Size: 500, Name: "Medium"},
+		} {
+			if _, err := packConfig.Create(ctx, in); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+		}
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			if _, ok := tables.Lookup([]int{250, 500}, 263); ok {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("Expected the changed catalog to be warmed up")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+}
@@ -0,0 +1,473 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/domain/repository"
+	"pack-calculator/internal/infrastructure/logger"
+)
+
+const (
+	// DefaultSolutionTableQuantity is the largest order quantity a
+	// solution table answers when no bound is configured
+	DefaultSolutionTableQuantity = 100000
+	// DefaultMaxSolutionTables is how many solution tables are cached when
+	// no limit is configured
+	DefaultMaxSolutionTables = 32
+	// DefaultSolutionTableHotAfter is how many solves make a pack set hot
+	// when no threshold is configured
+	DefaultSolutionTableHotAfter = 100
+	// DefaultMaxSolutionTableBuilds is how many tables are built at once
+	// when no limit is configured
+	DefaultMaxSolutionTableBuilds = 2
+	// DefaultSolutionTableBuildTimeout bounds a table build, waiting for a
+	// free build slot included, when no timeout is configured
+	DefaultSolutionTableBuildTimeout = time.Minute
+
+	// maxTrackedPackSets bounds the pack sets whose solves are counted
+	maxTrackedPackSets = 4096
+	// failedBuildBackoff is how long a pack set whose build failed is not
+	// built again on a miss
+	failedBuildBackoff = 10 * time.Minute
+)
+
+// SolutionTables caches the solution tables of hot pack sets. A pack set
+// gets a table when it is warmed up, or in the background once it has
+// been solved often enough; the least used table makes way when the cache
+// is full. Every table is built in the background, one build per pack set
+// shared by all its callers, and only a few builds run at once. All
+// methods are safe for concurrent use.
+type SolutionTables struct {
+	maxQuantity  int
+	maxTables    int
+	hotAfter     int
+	buildTimeout time.Duration
+	// builds holds a token per running build
+	builds chan struct{}
+
+	mu       sync.Mutex
+	tables   map[string]*SolutionTable
+	hits     map[string]int
+	solves   map[string]int
+	building map[string]*tableBuild
+	// failedUntil holds when the pack sets whose builds failed may be
+	// built again on a miss
+	failedUntil map[string]time.Time
+	// catalogs maps each warmed SKU to its pack set key
+	catalogs map[string]string
+	// dirty holds the SKUs whose catalogs Watch reloads next, all of them
+	// when dirtyAll is set; wake signals Watch that there are some
+	dirty    map[string]bool
+	dirtyAll bool
+	wake     chan struct{}
+	// generation is bumped by Invalidate so that builds started before
+	// are discarded
+	generation int
+}
+
+// SolutionTablesOption configures optional SolutionTables behaviour
+type SolutionTablesOption func(*SolutionTables)
+
+// WithSolutionTableQuantity sets the largest order quantity a table
+// answers
+func WithSolutionTableQuantity(n int) SolutionTablesOption {
+	return func(s *SolutionTables) {
+		if n > 0 {
+			s.maxQuantity = n
+		}
+	}
+}
+
+// WithMaxSolutionTables sets how many tables are cached
+func WithMaxSolutionTables(n int) SolutionTablesOption {
+	return func(s *SolutionTables) {
+		if n > 0 {
+			s.maxTables = n
+		}
+	}
+}
+
+// WithSolutionTableHotAfter sets how many solves of a pack set build its
+// table; zero only builds tables on warm-up
+func WithSolutionTableHotAfter(n int) SolutionTablesOption {
+	return func(s *SolutionTables) {
+		if n >= 0 {
+			s.hotAfter = n
+		}
+	}
+}
+
+// WithMaxSolutionTableBuilds sets how many tables are built at once
+func WithMaxSolutionTableBuilds(n int) SolutionTablesOption {
+	return func(s *SolutionTables) {
+		if n > 0 {
+			s.builds = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithSolutionTableBuildTimeout sets how long a table build may take,
+// waiting for a free build slot included
+func WithSolutionTableBuildTimeout(d time.Duration) SolutionTablesOption {
+	return func(s *SolutionTables) {
+		if d > 0 {
+			s.buildTimeout = d
+		}
+	}
+}
+
+// NewSolutionTables creates an empty solution table cache
+func NewSolutionTables(opts ...SolutionTablesOption) *SolutionTables {
+	s := &SolutionTables{
+		maxQuantity:  DefaultSolutionTableQuantity,
+		maxTables:    DefaultMaxSolutionTables,
+		hotAfter:     DefaultSolutionTableHotAfter,
+		buildTimeout: DefaultSolutionTableBuildTimeout,
+		builds:       make(chan struct{}, DefaultMaxSolutionTableBuilds),
+		tables:       make(map[string]*SolutionTable),
+		hits:         make(map[string]int),
+		solves:       make(map[string]int),
+		building:     make(map[string]*tableBuild),
+		failedUntil:  make(map[string]time.Time),
+		catalogs:     make(map[string]string),
+		dirty:        make(map[string]bool),
+		wake:         make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// MaxQuantity returns the largest order quantity the tables answer
+func (s *SolutionTables) MaxQuantity() int {
+	return s.maxQuantity
+}
+
+// Lookup answers orderQuantity from the table of packSizes. A miss counts
+// as a solve of the pack set, and the solve that makes it hot starts
+// building its table in the background, unless a build of the pack set
+// failed within failedBuildBackoff.
+func (s *SolutionTables) Lookup(
+	packSizes []int,
+	orderQuantity int,
+) (model.PackDistribution, bool) {
+	if orderQuantity > s.maxQuantity {
+		return nil, false
+	}
+	sizes := normalizePackSizes(packSizes)
+	key := packSetKey(sizes)
+
+	s.mu.Lock()
+	if table, ok := s.tables[key]; ok {
+		s.hits[key]++
+		s.mu.Unlock()
+		return table.Lookup(orderQuantity)
+	}
+
+	if len(s.solves) >= maxTrackedPackSets {
+		clear(s.solves)
+	}
+	s.solves[key]++
+	if s.hotAfter > 0 && s.solves[key] >= s.hotAfter && !s.backingOff(key) {
+		s.start(key, sizes, true)
+	}
+	s.mu.Unlock()
+	return nil, false
+}
+
+// Table returns the cached table of packSizes, or builds one for the
+// caller without caching it, so that exporting a table never evicts a hot
+// one
+func (s *SolutionTables) Table(ctx context.Context, packSizes []int) (*SolutionTable, error) {
+	return s.table(ctx, packSizes, false)
+}
+
+// Warm builds and caches the tables of packSets. The builds all start at
+// once, run as build slots free up, and the first that fails is returned.
+func (s *SolutionTables) Warm(ctx context.Context, packSets [][]int) error {
+	builds := make([]*tableBuild, 0, len(packSets))
+	s.mu.Lock()
+	for _, packSizes := range packSets {
+		sizes := normalizePackSizes(packSizes)
+		key := packSetKey(sizes)
+		if _, ok := s.tables[key]; !ok {
+			builds = append(builds, s.start(key, sizes, true))
+		}
+	}
+	s.mu.Unlock()
+
+	for _, build := range builds {
+		if _, err := build.wait(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Invalidate drops every table and solve count; builds already running
+// are discarded when they finish
+func (s *SolutionTables) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.generation++
+	clear(s.tables)
+	clear(s.hits)
+	clear(s.solves)
+	clear(s.building)
+	clear(s.failedUntil)
+}
+
+// Reload warms up the catalogs of skus from the active stored packs, one
+// pack set per SKU, or every catalog when no SKU is given. A catalog whose
+// pack set changed drops the table of its old pack set unless another
+// catalog still uses it; every other table stays cached.
+func (s *SolutionTables) Reload(
+	ctx context.Context,
+	repo repository.PackRepository,
+	skus ...string,
+) error {
+	packs, err := repo.List(ctx, repository.PackFilter{ActiveOnly: true})
+	if err != nil {
+		return err
+	}
+	catalogs := make(map[string][]int)
+	for _, pack := range packs {
+		catalogs[pack.SKU] = append(catalogs[pack.SKU], pack.Size)
+	}
+
+	affected := make(map[string]bool, len(skus))
+	for _, sku := range skus {
+		affected[sku] = true
+	}
+	s.mu.Lock()
+	if len(skus) == 0 {
+		for sku := range catalogs {
+			affected[sku] = true
+		}
+		for sku := range s.catalogs {
+			affected[sku] = true
+		}
+	}
+	var packSets [][]int
+	for sku := range affected {
+		old, warmed := s.catalogs[sku]
+		delete(s.catalogs, sku)
+		if sizes, ok := catalogs[sku]; ok {
+			s.catalogs[sku] = packSetKey(normalizePackSizes(sizes))
+			packSets = append(packSets, sizes)
+		}
+		if warmed && old != s.catalogs[sku] && !s.catalogUses(old) {
+			delete(s.tables, old)
+			delete(s.hits, old)
+		}
+	}
+	s.mu.Unlock()
+
+	startTime := time.Now()
+	if err := s.Warm(ctx, packSets); err != nil {
+		return err
+	}
+	logger.Info("Solution tables warmed up", map[string]interface{}{
+		"pack_sets":    len(packSets),
+		"max_quantity": s.maxQuantity,
+		"duration_ms":  time.Since(startTime).Milliseconds(),
+	})
+	return nil
+}
+
+// catalogUses reports whether a warmed catalog has the pack set key. The
+// caller holds the lock.
+func (s *SolutionTables) catalogUses(key string) bool {
+	for _, catalogKey := range s.catalogs {
+		if catalogKey == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Refresh marks the catalogs of skus for Watch to reload, every catalog
+// when no SKU is given. It never blocks, so it can run on the request path
+// after each pack change.
+func (s *SolutionTables) Refresh(skus ...string) {
+	s.mu.Lock()
+	if len(skus) == 0 {
+		s.dirtyAll = true
+	}
+	for _, sku := range skus {
+		s.dirty[sku] = true
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Watch reloads the catalogs marked by Refresh from repo until ctx ends.
+// Reloads run one at a time, so every change made while one runs is
+// coalesced into the next, and each is bounded by the build timeout.
+func (s *SolutionTables) Watch(ctx context.Context, repo repository.PackRepository) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		}
+
+		s.mu.Lock()
+		var skus []string
+		if !s.dirtyAll {
+			for sku := range s.dirty {
+				skus = append(skus, sku)
+			}
+		}
+		s.dirtyAll = false
+		clear(s.dirty)
+		s.mu.Unlock()
+
+		reloadCtx, cancel := context.WithTimeout(ctx, s.buildTimeout)
+		if err := s.Reload(reloadCtx, repo, skus...); err != nil {
+			logger.Error("Failed to warm up solution tables", map[string]interface{}{
+				"skus":  skus,
+				"error": err.Error(),
+			})
+		}
+		cancel()
+	}
+}
+
+// backingOff reports whether a build of key failed too recently to retry
+// on a miss. The caller holds the lock.
+func (s *SolutionTables) backingOff(key string) bool {
+	until, ok := s.failedUntil[key]
+	if ok && time.Now().After(until) {
+		delete(s.failedUntil, key)
+		return false
+	}
+	return ok
+}
+
+// table returns the cached table of packSizes, or waits for its build;
+// store caches the built table
+func (s *SolutionTables) table(
+	ctx context.Context,
+	packSizes []int,
+	store bool,
+) (*SolutionTable, error) {
+	sizes := normalizePackSizes(packSizes)
+	key := packSetKey(sizes)
+
+	s.mu.Lock()
+	if table, ok := s.tables[key]; ok {
+		s.mu.Unlock()
+		return table, nil
+	}
+	build := s.start(key, sizes, store)
+	s.mu.Unlock()
+	return build.wait(ctx)
+}
+
+// tableBuild is the build of a pack set's table, shared by every caller
+// asking for it while it runs
+type tableBuild struct {
+	done       chan struct{}
+	generation int
+	// store is set once a caller wants the table cached
+	store bool
+	table *SolutionTable
+	err   error
+}
+
+// wait returns the built table, or the error of ctx when it ends first
+func (b *tableBuild) wait(ctx context.Context) (*SolutionTable, error) {
+	select {
+	case <-b.done:
+		return b.table, b.err
+	case <-ctx.Done():
+		return nil, contextError(ctx.Err())
+	}
+}
+
+// start returns the running build of the normalised sizes, starting one
+// when there is none. The caller holds the lock.
+func (s *SolutionTables) start(key string, sizes []int, store bool) *tableBuild {
+	if build, ok := s.building[key]; ok {
+		build.store = build.store || store
+		return build
+	}
+	build := &tableBuild{
+		done:       make(chan struct{}),
+		generation: s.generation,
+		store:      store,
+	}
+	s.building[key] = build
+	go s.run(key, sizes, build)
+	return build
+}
+
+// run builds the table once a build slot is free and caches it when a
+// caller asked for it and the cache was not invalidated since the build
+// started. Callers wait with their own context, so the build is bounded
+// by the build timeout alone.
+func (s *SolutionTables) run(key string, sizes []int, build *tableBuild) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.buildTimeout)
+	defer cancel()
+	select {
+	case s.builds <- struct{}{}:
+		build.table, build.err = BuildSolutionTable(ctx, sizes, s.maxQuantity)
+		<-s.builds
+	case <-ctx.Done():
+		build.err = contextError(ctx.Err())
+	}
+	if build.err != nil {
+		logger.Warn("Solution table build failed", map[string]interface{}{
+			"pack_sizes": sizes,
+			"error":      build.err.Error(),
+		})
+	}
+
+	s.mu.Lock()
+	if s.building[key] == build {
+		delete(s.building, key)
+	}
+	if s.generation == build.generation {
+		switch {
+		case build.err != nil:
+			// Misses start counting again once the backoff is over
+			if len(s.failedUntil) >= maxTrackedPackSets {
+				clear(s.failedUntil)
+			}
+			s.failedUntil[key] = time.Now().Add(failedBuildBackoff)
+			delete(s.solves, key)
+		case build.store:
+			s.store(key, build.table)
+		}
+	}
+	s.mu.Unlock()
+	close(build.done)
+}
+
+// store caches table under key, evicting the least used table when the
+// cache is full. The caller holds the lock.
+func (s *SolutionTables) store(key string, table *SolutionTable) {
+	if _, ok := s.tables[key]; !ok && len(s.tables) >= s.maxTables {
+		evict, fewest := "", -1
+		for cached := range s.tables {
+			if fewest < 0 || s.hits[cached] < fewest {
+				evict, fewest = cached, s.hits[cached]
+			}
+		}
+		delete(s.tables, evict)
+		delete(s.hits, evict)
+	}
+	s.tables[key] = table
+	delete(s.solves, key)
+	delete(s.failedUntil, key)
+}
@@ -0,0 +1,182 @@
+package idgen
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Supported identifier formats
+const (
+	FormatULID   = "ulid"
+	FormatUUIDv7 = "uuidv7"
+)
+
+// ErrUnknownFormat is returned by New for an unsupported format
+var ErrUnknownFormat = errors.New("unknown ID format")
+
+// crockford is the ULID base32 alphabet
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// Generator issues time-ordered identifiers made of a millisecond
+// timestamp followed by random bits. IDs from one generator are strictly
+// increasing: within the same millisecond, or when the clock moves
+// backwards, the random part of the previous ID is incremented instead of
+// drawn anew. A Generator is safe for concurrent use.
+type Generator struct {
+	format  string
+	now     func() time.Time
+	entropy io.Reader
+
+	mu sync.Mutex
+	// ms is the timestamp of the last ID; hi and lo hold its random bits,
+	// with hi limited to hiMask
+	ms     uint64
+	hi     uint64
+	lo     uint64
+	hiMask uint64
+}
+
+// Option configures a Generator
+type Option func(*Generator)
+
+// WithClock replaces the wall clock used for timestamps
+func WithClock(now func() time.Time) Option {
+	return func(g *Generator) {
+		g.now = now
+	}
+}
+
+// WithEntropy replaces crypto/rand as the source of random bits
+func WithEntropy(r io.Reader) Option {
+	return func(g *Generator) {
+		g.entropy = r
+	}
+}
+
+// New returns a generator for the named format
+func New(format string, opts ...Option) (*Generator, error) {
+	switch format {
+	case FormatULID:
+		return NewULID(opts...), nil
+	case FormatUUIDv7:
+		return NewUUIDv7(opts...), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+}
+
+// NewULID returns a generator of 26-character ULIDs
+// (48-bit timestamp, 80 random bits)
+func NewULID(opts ...Option) *Generator {
+	return newGenerator(FormatULID, 1<<16-1, opts)
+}
+
+// NewUUIDv7 returns a generator of RFC 9562 version 7 UUIDs
+// (48-bit timestamp, 74 random bits)
+func NewUUIDv7(opts ...Option) *Generator {
+	return newGenerator(FormatUUIDv7, 1<<10-1, opts)
+}
+
+func newGenerator(format string, hiMask uint64, opts []Option) *Generator {
+	g := &Generator{
+		format:  format,
+		now:     time.Now,
+		entropy: rand.Reader,
+		hiMask:  hiMask,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Format returns the generator's identifier format
+func (g *Generator) Format() string {
+	return g.format
+}
+
+// NewID returns the next identifier
+func (g *Generator) NewID() string {
+	ms, hi, lo := g.next()
+
+	if g.format == FormatUUIDv7 {
+		return encodeUUIDv7(ms, hi, lo)
+	}
+	return encodeULID(ms, hi, lo)
+}
+
+// next advances the generator state and returns it
+func (g *Generator) next() (ms, hi, lo uint64) {
+	now := uint64(g.now().UnixMilli())
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var random [10]byte
+	if now > g.ms {
+		if _, err := io.ReadFull(g.entropy, random[:]); err == nil {
+			g.ms = now
+			g.hi = uint64(binary.BigEndian.Uint16(random[:2])) & g.hiMask
+			g.lo = binary.BigEndian.Uint64(random[2:])
+			return g.ms, g.hi, g.lo
+		}
+		// Without fresh entropy the increment below still yields a unique,
+		// ordered ID
+	}
+
+	g.lo++
+	if g.lo == 0 {
+		g.hi++
+		if g.hi > g.hiMask {
+			// The random space of this millisecond is exhausted; borrow
+			// the next one
+			g.ms++
+			g.hi = 0
+		}
+	}
+	return g.ms, g.hi, g.lo
+}
+
+// encodeULID writes the 128-bit value ms|hi|lo in Crockford base32
+func encodeULID(ms, hi, lo uint64) string {
+	// Pack the 48-bit timestamp and 80 random bits into two words
+	upper := ms<<16 | hi
+	lower := lo
+
+	var out [26]byte
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = crockford[lower&31]
+		lower = lower>>5 | upper<<59
+		upper >>= 5
+	}
+	return string(out[:])
+}
+
+// encodeUUIDv7 lays out ms|hi|lo with the version and variant bits
+func encodeUUIDv7(ms, hi, lo uint64) string {
+	// 74 random bits split into the 12-bit rand_a and 62-bit rand_b fields
+	randA := hi<<2 | lo>>62
+	randB := lo & (1<<62 - 1)
+
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[0:8], ms<<16|0x7000|randA)
+	binary.BigEndian.PutUint64(b[8:16], 0x8000000000000000|randB)
+
+	var out [36]byte
+	hex.Encode(out[0:8], b[0:4])
+	out[8] = '-'
+	hex.Encode(out[9:13], b[4:6])
+	out[13] = '-'
+	hex.Encode(out[14:18], b[6:8])
+	out[18] = '-'
+	hex.Encode(out[19:23], b[8:10])
+	out[23] = '-'
+	hex.Encode(out[24:36], b[10:16])
+	return string(out[:])
+}
@@ -0,0 +1,145 @@
+package idgen
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	ulidPattern   = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+	uuidv7Pattern = regexp.MustCompile(
+		`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+	)
+)
+
+// fixedClock returns a clock that only moves when advanced
+func fixedClock(start time.Time) (func() time.Time, func(time.Duration)) {
+	var mu sync.Mutex
+	now := start
+	clock := func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(d)
+	}
+	return clock, advance
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		format  string
+		pattern *regexp.Regexp
+	}{
+		{FormatULID, ulidPattern},
+		{FormatUUIDv7, uuidv7Pattern},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			g, err := New(tt.format)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if g.Format() != tt.format {
+				t.Errorf("Expected format %s, got %s", tt.format, g.Format())
+			}
+			if id := g.NewID(); !tt.pattern.MatchString(id) {
+				t.Errorf("Malformed %s: %s", tt.format, id)
+			}
+		})
+	}
+
+	if _, err := New("snowflake"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Expected ErrUnknownFormat, got %v", err)
+	}
+}
+
+func TestGenerator_Encoding(t *testing.T) {
+	clock := func() time.Time { return time.UnixMilli(1469918176385) }
+	entropy := bytes.Repeat([]byte{0}, 10)
+
+	ulid := NewULID(WithClock(clock), WithEntropy(bytes.NewReader(entropy)))
+	if id := ulid.NewID(); id != "01ARYZ6S410000000000000000" {
+		t.Errorf("Unexpected ULID: %s", id)
+	}
+
+	uuid := NewUUIDv7(WithClock(clock), WithEntropy(bytes.NewReader(entropy)))
+	if id := uuid.NewID(); id != "01563df3-6481-7000-8000-000000000000" {
+		t.Errorf("Unexpected UUIDv7: %s", id)
+	}
+}
+
+func TestGenerator_Monotonic(t *testing.T) {
+	for _, newGenerator := range []func(...Option) *Generator{NewULID, NewUUIDv7} {
+		clock, advance := fixedClock(time.UnixMilli(1700000000000))
+		g := newGenerator(WithClock(clock))
+
+		prev := g.NewID()
+		for i := 0; i < 1000; i++ {
+			switch i {
+			case 300:
+				advance(time.Millisecond)
+			case 600:
+				// A clock moving backwards must not reorder IDs
+				advance(-time.Second)
+			}
+
+			id := g.NewID()
+			if id <= prev {
+				t.Fatalf("%s: IDs not increasing: %s then %s", g.Format(), prev, id)
+			}
+			prev = id
+		}
+	}
+}
+
+func TestGenerator_EntropyOverflow(t *testing.T) {
+	clock := func() time.Time { return time.UnixMilli(1700000000000) }
+	entropy := bytes.Repeat([]byte{0xff}, 10)
+	g := NewULID(WithClock(clock), WithEntropy(bytes.NewReader(entropy)))
+
+	first := g.NewID()
+	second := g.NewID()
+	if second <= first {
+		t.Errorf("Expected overflow to carry into the timestamp: %s then %s", first, second)
+	}
+	if first[:10] == second[:10] {
+		t.Errorf("Expected the timestamp to advance: %s then %s", first, second)
+	}
+}
+
+func TestGenerator_Concurrent(t *testing.T) {
+	g := NewULID()
+
+	const workers, perWorker = 8, 1000
+	ids := make(chan string, workers*perWorker)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				ids <- g.NewID()
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[string]bool, workers*perWorker)
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("Duplicate ID: %s", id)
+		}
+		seen[id] = true
+	}
+}
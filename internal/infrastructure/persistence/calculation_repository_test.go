@@ -0,0 +1,184 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/domain/repository"
+)
+
+func TestMemoryCalculationRepository(t *testing.T) {
+	testCalculationRepository(t, NewMemoryCalculationRepository(0))
+	testCalculationRepositoryList(t, NewMemoryCalculationRepository(0))
+}
+
+func TestGormCalculationRepository(t *testing.T) {
+	testCalculationRepository(t, NewGormCalculationRepository(newTestDB(t)))
+	testCalculationRepositoryList(t, NewGormCalculationRepository(newTestDB(t)))
+}
+
+func TestMemoryCalculationRepository_Eviction(t *testing.T) {
+	repo := NewMemoryCalculationRepository(2)
+	ctx := context.Background()
+
+	for _, id := range []string{"a", "b", "c"} {
+		calc := model.NewCalculation([]int{250}, 1, model.PackDistribution{250: 1}, 0)
+		calc.ID = id
+		if err := repo.Save(ctx, calc); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	if _, err := repo.GetByID(ctx, "a"); !errors.Is(err, model.ErrCalculationNotFound) {
+		t.Errorf("Expected oldest calculation to be evicted, got %v", err)
+	}
+	if _, err := repo.GetByID(ctx, "c"); err != nil {
+		t.Errorf("Expected newest calculation to be kept, got %v", err)
+	}
+}
+
+// testCalculationRepository checks the CalculationRepository contract
+// shared by all implementations
+func testCalculationRepository(t *testing.T, repo repository.CalculationRepository) {
+	ctx := context.Background()
+
+	calc := model.NewCalculation(
+		[]int{250, 500, 1000},
+		263,
+		model.PackDistribution{500: 1},
+		3*time.Millisecond,
+	)
+	calc.ID = "calc-1"
+	calc.UserID = "user-1"
+	created := time.Date(2025, 1, 14, 11, 0, 0, 0, time.FixedZone("CET", 3600))
+	calc.CreatedAt = created
+
+	if err := repo.Save(ctx, calc); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if calc.CreatedAt != created {
+		t.Errorf("Expected Save to leave the creation time as %v, got %v", created, calc.CreatedAt)
+	}
+
+	found, err := repo.GetByID(ctx, "calc-1")
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+
+	if found.OrderQuantity != 263 || found.TotalItems != 500 || found.UserID != "user-1" {
+		t.Errorf("Calculation not stored correctly: %+v", found)
+	}
+
+	if found.GetDistribution()[500] != 1 {
+		t.Errorf("Distribution not stored correctly: %v", found.GetDistribution())
+	}
+
+	if found.CalculationTime != 3*time.Millisecond {
+		t.Errorf("Expected calculation time 3ms, got %v", found.CalculationTime)
+	}
+
+	if !found.CreatedAt.Equal(created) {
+		t.Errorf("Expected creation time %v, got %v", created, found.CreatedAt)
+	}
+
+	if _, err := repo.GetByID(ctx, "missing"); !errors.Is(err, model.ErrCalculationNotFound) {
+		t.Errorf("Expected ErrCalculationNotFound, got %v", err)
+	}
+}
+
+// testCalculationRepositoryList checks filtering and cursor pagination
+func testCalculationRepositoryList(t *testing.T, repo repository.CalculationRepository) {
+	ctx := context.Background()
+	base := time.Date(2025, 1, 14, 10, 0, 0, 0, time.UTC)
+
+	// Quantities 100..1000; overage is 500 for odd indexes
+	for i := 0; i < 10; i++ {
+		quantity := (i + 1) * 100
+		distribution := model.PackDistribution{100: i + 1}
+		if i%2 == 1 {
+			distribution[500] = 1
+		}
+		calc := model.NewCalculation([]int{100, 500}, quantity, distribution, time.Millisecond)
+		calc.ID = fmt.Sprintf("calc-%02d", i)
+		calc.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		if i < 5 {
+			calc.UserID = "alice"
+		}
+		if err := repo.Save(ctx, calc); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	ids := func(page *repository.CalculationPage) []string {
+		result := make([]string, len(page.Calculations))
+		for i, calc := range page.Calculations {
+			result[i] = calc.ID
+		}
+		return result
+	}
+
+	// Paging walks every calculation newest first without overlap
+	var walked []string
+	filter := repository.CalculationFilter{Limit: 4}
+	for pages := 0; pages < 5; pages++ {
+		page, err := repo.List(ctx, filter)
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		walked = append(walked, ids(page)...)
+		if page.NextCursor == "" {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+	expected := "[calc-09 calc-08 calc-07 calc-06 calc-05 calc-04 calc-03 calc-02 calc-01 calc-00]"
+	if fmt.Sprint(walked) != expected {
+		t.Errorf("Unexpected paging order: %v", walked)
+	}
+
+	tests := []struct {
+		name     string
+		filter   repository.CalculationFilter
+		expected string
+	}{
+		{
+			name:     "Quantity range",
+			filter:   repository.CalculationFilter{MinOrderQuantity: 300, MaxOrderQuantity: 500},
+			expected: "[calc-04 calc-03 calc-02]",
+		},
+		{
+			name: "Created range",
+			filter: repository.CalculationFilter{
+				CreatedAfter:  base.Add(7 * time.Minute),
+				CreatedBefore: base.Add(9 * time.Minute),
+			},
+			expected: "[calc-08 calc-07]",
+		},
+		{
+			name:     "User and overage",
+			filter:   repository.CalculationFilter{UserID: "alice", MinOverage: 1},
+			expected: "[calc-03 calc-01]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := repo.List(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+			if got := fmt.Sprint(ids(page)); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	_, err := repo.List(ctx, repository.CalculationFilter{Cursor: "not a cursor"})
+	if !errors.Is(err, model.ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor, got %v", err)
+	}
+}
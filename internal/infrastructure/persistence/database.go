@@ -0,0 +1,86 @@
+package persistence
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"pack-calculator/internal/config"
+	"pack-calculator/internal/domain/model"
+)
+
+// activeSizeIndex enforces unique sizes among the active packs of a SKU at
+// the database level; both PostgreSQL and SQLite support partial indexes
+const activeSizeIndex = `CREATE UNIQUE INDEX IF NOT EXISTS idx_packs_active_sku_size
+	ON packs (sku, size) WHERE active`
+
+// OpenPostgres connects to PostgreSQL and applies the pool settings
+func OpenPostgres(cfg config.DatabaseConfig) (*gorm.DB, error) {
+	return Open(postgres.Open(cfg.DSN()), cfg)
+}
+
+// Open connects through the given dialector and applies the pool settings.
+// Tests use it with an in-memory SQLite dialector.
+func Open(dialector gorm.Dialector, cfg config.DatabaseConfig) (*gorm.DB, error) {
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:         gormlogger.Default.LogMode(gormlogger.Silent),
+		TranslateError: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("access connection pool: %w", err)
+	}
+
+	if cfg.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+	if cfg.ConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	}
+
+	return db, nil
+}
+
+// Migrate creates or updates the schema for packs and calculations
+func Migrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(&model.Pack{}, &model.Calculation{}); err != nil {
+		return fmt.Errorf("migrate schema: %w", err)
+	}
+	if err := db.Exec(activeSizeIndex).Error; err != nil {
+		return fmt.Errorf("create active size index: %w", err)
+	}
+	return nil
+}
+
+// Ping checks database connectivity; it is used as a readiness check
+func Ping(db *gorm.DB) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// Close releases the connection pool
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
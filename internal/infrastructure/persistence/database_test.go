@@ -0,0 +1,44 @@
+package persistence
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+
+	"pack-calculator/internal/config"
+)
+
+// testDBCounter keeps databases opened by the same test apart
+var testDBCounter atomic.Int64
+
+// newTestDB opens a migrated in-memory SQLite database private to the test
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	dsn := fmt.Sprintf("file:%s_%d?mode=memory&cache=shared", name, testDBCounter.Add(1))
+
+	db, err := Open(sqlite.Open(dsn), config.DatabaseConfig{MaxOpenConns: 1})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { Close(db) })
+
+	if err := Migrate(db); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	return db
+}
+
+func TestMigrate_Idempotent(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := Migrate(db); err != nil {
+		t.Errorf("Second migration failed: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/domain/repository"
+)
+
+// GormCalculationRepository is a CalculationRepository backed by a SQL database
+type GormCalculationRepository struct {
+	db *gorm.DB
+}
+
+// NewGormCalculationRepository creates a database-backed calculation repository
+func NewGormCalculationRepository(db *gorm.DB) *GormCalculationRepository {
+	return &GormCalculationRepository{db: db}
+}
+
+// Save stores a completed calculation
+func (r *GormCalculationRepository) Save(
+	ctx context.Context,
+	calculation *model.Calculation,
+) error {
+	// Store UTC so keyset comparisons behave the same on every backend,
+	// leaving the caller's calculation as it was
+	stored := *calculation
+	stored.CreatedAt = stored.CreatedAt.UTC()
+	return r.db.WithContext(ctx).Create(&stored).Error
+}
+
+// GetByID returns the calculation with the given ID
+func (r *GormCalculationRepository) GetByID(
+	ctx context.Context,
+	id string,
+) (*model.Calculation, error) {
+	var calculation model.Calculation
+	err := r.db.WithContext(ctx).First(&calculation, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, model.ErrCalculationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	restoreCalculationTime(&calculation)
+	return &calculation, nil
+}
+
+// List returns a page of calculations matching filter, newest first
+func (r *GormCalculationRepository) List(
+	ctx context.Context,
+	filter repository.CalculationFilter,
+) (*repository.CalculationPage, error) {
+	query := r.db.WithContext(ctx).Model(&model.Calculation{})
+
+	if filter.MinOrderQuantity > 0 {
+		query = query.Where("order_quantity >= ?", filter.MinOrderQuantity)
+	}
+	if filter.MaxOrderQuantity > 0 {
+		query = query.Where("order_quantity <= ?", filter.MaxOrderQuantity)
+	}
+	if !filter.CreatedAfter.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedAfter.UTC())
+	}
+	if !filter.CreatedBefore.IsZero() {
+		query = query.Where("created_at < ?", filter.CreatedBefore.UTC())
+	}
+	if filter.UserID != "" {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.MinOverage > 0 {
+		query = query.Where("items_overage >= ?", filter.MinOverage)
+	}
+
+	if filter.Cursor != "" {
+		cursor, err := repository.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(
+			"created_at < ? OR (created_at = ? AND id < ?)",
+			cursor.CreatedAt, cursor.CreatedAt, cursor.ID,
+		)
+	}
+
+	query = query.Order("created_at DESC").Order("id DESC")
+	if filter.Limit > 0 {
+		// One extra row tells whether another page follows
+		query = query.Limit(filter.Limit + 1)
+	}
+
+	var calculations []*model.Calculation
+	if err := query.Find(&calculations).Error; err != nil {
+		return nil, err
+	}
+	for _, calculation := range calculations {
+		restoreCalculationTime(calculation)
+	}
+
+	return buildCalculationPage(calculations, filter.Limit), nil
+}
+
+// restoreCalculationTime rebuilds the unpersisted duration from its millis
+func restoreCalculationTime(calculation *model.Calculation) {
+	calculation.CalculationTime = time.Duration(calculation.CalculationTimeMs) * time.Millisecond
+}
@@ -0,0 +1,116 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/domain/repository"
+)
+
+// GormPackRepository is a PackRepository backed by a SQL database
+type GormPackRepository struct {
+	db *gorm.DB
+}
+
+// NewGormPackRepository creates a database-backed pack repository
+func NewGormPackRepository(db *gorm.DB) *GormPackRepository {
+	return &GormPackRepository{db: db}
+}
+
+// Create stores a new pack
+func (r *GormPackRepository) Create(ctx context.Context, pack *model.Pack) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if pack.Active {
+			if err := activeSizeTaken(tx, pack.SKU, pack.Size, pack.ID); err != nil {
+				return err
+			}
+		}
+		// Select("*") keeps an explicit inactive flag instead of the column default
+		return translatePackError(tx.Select("*").Create(pack).Error)
+	})
+}
+
+// GetByID returns the pack with the given ID
+func (r *GormPackRepository) GetByID(ctx context.Context, id string) (*model.Pack, error) {
+	var pack model.Pack
+	err := r.db.WithContext(ctx).First(&pack, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, model.ErrPackNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pack, nil
+}
+
+// List returns packs ordered by size
+func (r *GormPackRepository) List(
+	ctx context.Context,
+	filter repository.PackFilter,
+) ([]*model.Pack, error) {
+	query := r.db.WithContext(ctx).Order("size ASC").Order("id ASC")
+	if filter.ActiveOnly {
+		query = query.Where("active = ?", true)
+	}
+	if filter.SKU != nil {
+		query = query.Where("sku = ?", *filter.SKU)
+	}
+
+	var packs []*model.Pack
+	if err := query.Find(&packs).Error; err != nil {
+		return nil, err
+	}
+	return packs, nil
+}
+
+// Update replaces a stored pack
+func (r *GormPackRepository) Update(ctx context.Context, pack *model.Pack) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if pack.Active {
+			if err := activeSizeTaken(tx, pack.SKU, pack.Size, pack.ID); err != nil {
+				return err
+			}
+		}
+
+		// Select("*") writes zero values too, so deactivation is persisted
+		result := tx.Model(&model.Pack{}).
+			Where("id = ?", pack.ID).
+			Select("*").
+			Updates(pack)
+		if err := translatePackError(result.Error); err != nil {
+			return err
+		}
+		if result.RowsAffected == 0 {
+			return model.ErrPackNotFound
+		}
+		return nil
+	})
+}
+
+// activeSizeTaken returns ErrPackAlreadyExists when an active pack of sku
+// other than excludeID already uses size
+func activeSizeTaken(tx *gorm.DB, sku string, size int, excludeID string) error {
+	var count int64
+	err := tx.Model(&model.Pack{}).
+		Where("sku = ? AND size = ? AND active = ? AND id <> ?", sku, size, true, excludeID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return model.ErrPackAlreadyExists
+	}
+	return nil
+}
+
+// translatePackError maps unique constraint violations, which cover races
+// the pre-check cannot see, to the domain error
+func translatePackError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return model.ErrPackAlreadyExists
+	}
+	return err
+}
@@ -0,0 +1,147 @@
+package persistence
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/domain/repository"
+)
+
+// MemoryCalculationRepository is an in-process CalculationRepository that
+// keeps at most maxEntries calculations, evicting the oldest first
+type MemoryCalculationRepository struct {
+	mu           sync.RWMutex
+	calculations map[string]*model.Calculation
+	order        []string
+	maxEntries   int
+}
+
+// NewMemoryCalculationRepository creates an in-memory calculation history.
+// A maxEntries of zero keeps every calculation.
+func NewMemoryCalculationRepository(maxEntries int) *MemoryCalculationRepository {
+	return &MemoryCalculationRepository{
+		calculations: make(map[string]*model.Calculation),
+		maxEntries:   maxEntries,
+	}
+}
+
+// Save stores a completed calculation
+func (r *MemoryCalculationRepository) Save(
+	ctx context.Context,
+	calculation *model.Calculation,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.calculations[calculation.ID]; !exists {
+		r.order = append(r.order, calculation.ID)
+	}
+	stored := *calculation
+	r.calculations[calculation.ID] = &stored
+
+	if r.maxEntries > 0 && len(r.order) > r.maxEntries {
+		evicted := len(r.order) - r.maxEntries
+		for _, id := range r.order[:evicted] {
+			delete(r.calculations, id)
+		}
+		r.order = append([]string(nil), r.order[evicted:]...)
+	}
+
+	return nil
+}
+
+// GetByID returns the calculation with the given ID
+func (r *MemoryCalculationRepository) GetByID(
+	ctx context.Context,
+	id string,
+) (*model.Calculation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	calculation, ok := r.calculations[id]
+	if !ok {
+		return nil, model.ErrCalculationNotFound
+	}
+
+	found := *calculation
+	return &found, nil
+}
+
+// List returns a page of calculations matching filter, newest first
+func (r *MemoryCalculationRepository) List(
+	ctx context.Context,
+	filter repository.CalculationFilter,
+) (*repository.CalculationPage, error) {
+	var cursor *repository.CalculationCursor
+	if filter.Cursor != "" {
+		decoded, err := repository.DecodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		cursor = &decoded
+	}
+
+	r.mu.RLock()
+	matches := make([]*model.Calculation, 0)
+	for _, calculation := range r.calculations {
+		if matchesCalculationFilter(calculation, filter) &&
+			(cursor == nil || isAfterCursor(calculation, *cursor)) {
+			found := *calculation
+			matches = append(matches, &found)
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		if !matches[i].CreatedAt.Equal(matches[j].CreatedAt) {
+			return matches[i].CreatedAt.After(matches[j].CreatedAt)
+		}
+		return matches[i].ID > matches[j].ID
+	})
+
+	return buildCalculationPage(matches, filter.Limit), nil
+}
+
+// matchesCalculationFilter applies the non-cursor filters
+func matchesCalculationFilter(c *model.Calculation, filter repository.CalculationFilter) bool {
+	switch {
+	case filter.MinOrderQuantity > 0 && c.OrderQuantity < filter.MinOrderQuantity:
+		return false
+	case filter.MaxOrderQuantity > 0 && c.OrderQuantity > filter.MaxOrderQuantity:
+		return false
+	case !filter.CreatedAfter.IsZero() && c.CreatedAt.Before(filter.CreatedAfter):
+		return false
+	case !filter.CreatedBefore.IsZero() && !c.CreatedAt.Before(filter.CreatedBefore):
+		return false
+	case filter.UserID != "" && c.UserID != filter.UserID:
+		return false
+	case filter.MinOverage > 0 && c.ItemsOverage < filter.MinOverage:
+		return false
+	}
+	return true
+}
+
+// isAfterCursor reports whether c sorts after the cursor position
+func isAfterCursor(c *model.Calculation, cursor repository.CalculationCursor) bool {
+	if c.CreatedAt.Equal(cursor.CreatedAt) {
+		return c.ID < cursor.ID
+	}
+	return c.CreatedAt.Before(cursor.CreatedAt)
+}
+
+// buildCalculationPage trims sorted matches to limit and derives the next
+// cursor; matches may hold one extra row to detect further pages
+func buildCalculationPage(matches []*model.Calculation, limit int) *repository.CalculationPage {
+	page := &repository.CalculationPage{Calculations: matches}
+	if limit > 0 && len(matches) > limit {
+		page.Calculations = matches[:limit]
+		last := page.Calculations[limit-1]
+		page.NextCursor = repository.EncodeCursor(repository.CalculationCursor{
+			CreatedAt: last.CreatedAt,
+			ID:        last.ID,
+		})
+	}
+	return page
+}
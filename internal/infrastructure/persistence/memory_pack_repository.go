@@ -0,0 +1,112 @@
+package persistence
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/domain/repository"
+)
+
+// MemoryPackRepository is an in-process PackRepository
+type MemoryPackRepository struct {
+	mu    sync.RWMutex
+	packs map[string]*model.Pack
+}
+
+// NewMemoryPackRepository creates an empty in-memory pack repository
+func NewMemoryPackRepository() *MemoryPackRepository {
+	return &MemoryPackRepository{
+		packs: make(map[string]*model.Pack),
+	}
+}
+
+// Create stores a new pack
+func (r *MemoryPackRepository) Create(ctx context.Context, pack *model.Pack) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.packs[pack.ID]; exists {
+		return model.ErrPackAlreadyExists
+	}
+	if pack.Active && r.activeSizeTaken(pack.SKU, pack.Size, pack.ID) {
+		return model.ErrPackAlreadyExists
+	}
+
+	stored := *pack
+	r.packs[pack.ID] = &stored
+	return nil
+}
+
+// GetByID returns the pack with the given ID
+func (r *MemoryPackRepository) GetByID(ctx context.Context, id string) (*model.Pack, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pack, ok := r.packs[id]
+	if !ok {
+		return nil, model.ErrPackNotFound
+	}
+
+	found := *pack
+	return &found, nil
+}
+
+// List returns packs ordered by size
+func (r *MemoryPackRepository) List(
+	ctx context.Context,
+	filter repository.PackFilter,
+) ([]*model.Pack, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	packs := make([]*model.Pack, 0, len(r.packs))
+	for _, pack := range r.packs {
+		if filter.ActiveOnly && !pack.Active {
+			continue
+		}
+		if filter.SKU != nil && pack.SKU != *filter.SKU {
+			continue
+		}
+		found := *pack
+		packs = append(packs, &found)
+	}
+
+	sort.Slice(packs, func(i, j int) bool {
+		if packs[i].Size != packs[j].Size {
+			return packs[i].Size < packs[j].Size
+		}
+		return packs[i].ID < packs[j].ID
+	})
+
+	return packs, nil
+}
+
+// Update replaces a stored pack
+func (r *MemoryPackRepository) Update(ctx context.Context, pack *model.Pack) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.packs[pack.ID]; !ok {
+		return model.ErrPackNotFound
+	}
+	if pack.Active && r.activeSizeTaken(pack.SKU, pack.Size, pack.ID) {
+		return model.ErrPackAlreadyExists
+	}
+
+	stored := *pack
+	r.packs[pack.ID] = &stored
+	return nil
+}
+
+// activeSizeTaken reports whether an active pack of sku other than
+// excludeID already uses size; callers must hold the lock
+func (r *MemoryPackRepository) activeSizeTaken(sku string, size int, excludeID string) bool {
+	for id, pack := range r.packs {
+		if id != excludeID && pack.Active && pack.SKU == sku && pack.Size == size {
+			return true
+		}
+	}
+	return false
+}
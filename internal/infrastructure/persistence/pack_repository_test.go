@@ -0,0 +1,158 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"pack-calculator/internal/domain/model"
+	"pack-calculator/internal/domain/repository"
+)
+
+func newTestPack(id string, size int) *model.Pack {
+	pack := model.NewPack(size, "Pack")
+	pack.ID = id
+	return pack
+}
+
+func TestMemoryPackRepository(t *testing.T) {
+	testPackRepository(t, NewMemoryPackRepository())
+}
+
+func TestGormPackRepository(t *testing.T) {
+	testPackRepository(t, NewGormPackRepository(newTestDB(t)))
+}
+
+// testPackRepository checks the PackRepository contract shared by all
+// implementations
+func testPackRepository(t *testing.T, repo repository.PackRepository) {
+	ctx := context.Background()
+
+	if err := repo.Create(ctx, newTestPack("1", 500)); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := repo.Create(ctx, newTestPack("2", 250)); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// Duplicate active size
+	if err := repo.Create(ctx, newTestPack("3", 500)); !errors.Is(err, model.ErrPackAlreadyExists) {
+		t.Errorf("Expected ErrPackAlreadyExists, got %v", err)
+	}
+
+	pack, err := repo.GetByID(ctx, "1")
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+
+	// Returned packs are copies
+	pack.Size = 999
+	if stored, _ := repo.GetByID(ctx, "1"); stored.Size != 500 {
+		t.Errorf("Repository state changed without Update")
+	}
+
+	if pack.Stock != nil {
+		t.Errorf("Expected unlimited stock, got %d", *pack.Stock)
+	}
+
+	// Stock round-trips, including an empty stock
+	stock := 0
+	pack.Stock = &stock
+	if err := repo.Update(ctx, pack); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if stored, _ := repo.GetByID(ctx, "1"); stored.Stock == nil || *stored.Stock != 0 {
+		t.Errorf("Expected stock 0, got %v", stored.Stock)
+	}
+
+	// Packaging round-trips
+	pack.Packaging = model.Packaging{{Name: "carton", Quantity: 8}, {Name: "pallet", Quantity: 40}}
+	if err := repo.Update(ctx, pack); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if stored, _ := repo.GetByID(ctx, "1"); !reflect.DeepEqual(stored.Packaging, pack.Packaging) {
+		t.Errorf("Expected packaging %v, got %v", pack.Packaging, stored.Packaging)
+	}
+
+	// Rules round-trip
+	maxCount := 3
+	pack.Rule = model.PackRule{Required: true, MaxCount: &maxCount, MinOrderQuantity: 1000}
+	if err := repo.Update(ctx, pack); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if stored, _ := repo.GetByID(ctx, "1"); !reflect.DeepEqual(stored.Rule, pack.Rule) {
+		t.Errorf("Expected rule %+v, got %+v", pack.Rule, stored.Rule)
+	}
+
+	if _, err := repo.GetByID(ctx, "missing"); !errors.Is(err, model.ErrPackNotFound) {
+		t.Errorf("Expected ErrPackNotFound, got %v", err)
+	}
+
+	// Updating to a taken size is rejected
+	pack.Size = 250
+	if err := repo.Update(ctx, pack); !errors.Is(err, model.ErrPackAlreadyExists) {
+		t.Errorf("Expected ErrPackAlreadyExists, got %v", err)
+	}
+
+	// Deactivated packs free their size
+	pack.Size = 500
+	pack.Deactivate()
+	if err := repo.Update(ctx, pack); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if err := repo.Create(ctx, newTestPack("3", 500)); err != nil {
+		t.Errorf("Expected size of deactivated pack to be reusable, got %v", err)
+	}
+
+	all, _ := repo.List(ctx, repository.PackFilter{})
+	if len(all) != 3 || all[0].Size != 250 {
+		t.Errorf("Expected 3 packs sorted by size, got %+v", all)
+	}
+
+	active, _ := repo.List(ctx, repository.PackFilter{ActiveOnly: true})
+	if len(active) != 2 {
+		t.Errorf("Expected 2 active packs, got %d", len(active))
+	}
+
+	if err := repo.Update(ctx, newTestPack("missing", 1)); !errors.Is(err, model.ErrPackNotFound) {
+		t.Errorf("Expected ErrPackNotFound, got %v", err)
+	}
+
+	// Sizes are unique per SKU catalog
+	widget := newTestPack("4", 250)
+	widget.SKU = "WIDGET"
+	if err := repo.Create(ctx, widget); err != nil {
+		t.Errorf("Expected a size taken by another SKU to be free, got %v", err)
+	}
+	duplicate := newTestPack("5", 250)
+	duplicate.SKU = "WIDGET"
+	if err := repo.Create(ctx, duplicate); !errors.Is(err, model.ErrPackAlreadyExists) {
+		t.Errorf("Expected ErrPackAlreadyExists within a SKU, got %v", err)
+	}
+
+	sku := "WIDGET"
+	catalog, _ := repo.List(ctx, repository.PackFilter{SKU: &sku})
+	if len(catalog) != 1 || catalog[0].ID != "4" {
+		t.Errorf("Expected the WIDGET pack only, got %+v", catalog)
+	}
+	sku = ""
+	defaults, _ := repo.List(ctx, repository.PackFilter{ActiveOnly: true, SKU: &sku})
+	if len(defaults) != 2 {
+		t.Errorf("Expected 2 active default packs, got %d", len(defaults))
+	}
+}
+
+func TestGormPackRepository_ActiveSizeIndex(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := db.Create(newTestPack("1", 250)).Error; err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	// Bypass the repository pre-check to hit the database constraint
+	err := translatePackError(db.Create(newTestPack("2", 250)).Error)
+	if !errors.Is(err, model.ErrPackAlreadyExists) {
+		t.Errorf("Expected ErrPackAlreadyExists from unique index, got %v", err)
+	}
+}
@@ -44,6 +44,90 @@ function setPreset(sizes) {
   updateRemoveButtons();
 }
 
+// Ranked alternatives of the last calculation, best first
+let currentAlternatives = [];
+
+function renderPacks(packsUsed) {
+  let packsHtml = "";
+  for (const [packSize, quantity] of Object.entries(packsUsed)) {
+    if (quantity > 0) {
+      packsHtml += `
+        <div class="result-item">
+          <div class="result-number">${quantity}</div>
+          <div class="result-label">Pack${quantity > 1 ? "s" : ""} of ${packSize} items</div>
+        </div>
+      `;
+    }
+  }
+  return packsHtml;
+}
+
+function renderSummary(option, calculationTime) {
+  const overage =
+    option.items_overage > 0 ? ` (+${option.items_overage} extra)` : "";
+
+  return `
+    <div class="summary-item">
+      <div class="summary-label">📦 Total Packs</div>
+      <div class="summary-value">${option.total_packs}</div>
+    </div>
+    <div class="summary-item">
+      <div class="summary-label">📊 Total Items</div>
+      <div class="summary-value">${option.total_items}${overage}</div>
+    </div>
+    <div class="summary-item">
+      <div class="summary-label">⚡ Time</div>
+      <div class="summary-value">${calculationTime}</div>
+    </div>
+  `;
+}
+
+function renderAlternatives(alternatives) {
+  const options = alternatives
+    .map(
+      (alt, index) => `
+        <button
+          type="button"
+          class="alternative-option${index === 0 ? " selected" : ""}"
+          onclick="selectAlternative(${index})"
+        >
+          <div class="alternative-rank">#${alt.rank}</div>
+          <div class="alternative-detail">
+            ${alt.total_packs} pack${alt.total_packs > 1 ? "s" : ""} ·
+            ${alt.total_items} items
+            ${alt.items_overage > 0 ? `(+${alt.items_overage})` : ""}
+          </div>
+        </button>
+      `,
+    )
+    .join("");
+
+  return `
+    <div class="section-title">Ranked Alternatives:</div>
+    <div class="alternatives-list">${options}</div>
+  `;
+}
+
+function selectAlternative(index) {
+  const alt = currentAlternatives[index];
+  if (!alt) {
+    return;
+  }
+
+  document.querySelectorAll(".alternative-option").forEach((option, i) => {
+    option.classList.toggle("selected", i === index);
+  });
+
+  const results = document.getElementById("results");
+  results.querySelector(".result-grid").innerHTML = renderPacks(alt.packs_used);
+  results.querySelector(".summary-grid").innerHTML = renderSummary(
+    alt,
+    results.dataset.calculationTime,
+  );
+  results.querySelector(".result-title").textContent =
+    index === 0 ? "✅ Optimal Pack Distribution" : `🔀 Alternative #${alt.rank}`;
+}
+
 // Handle successful response
 document.addEventListener("htmx:beforeSwap", function (evt) {
   evt.preventDefault();
@@ -52,44 +136,27 @@ document.addEventListener("htmx:beforeSwap", function (evt) {
 
     if (response.success && response.data) {
       const data = response.data;
-      let packsHtml = "";
-
-      for (const [packSize, quantity] of Object.entries(data.packs_used)) {
-        if (quantity > 0) {
-          packsHtml += `
-            <div class="result-item">
-              <div class="result-number">${quantity}</div>
-              <div class="result-label">Pack${quantity > 1 ? "s" : ""} of ${packSize} items</div>
-            </div>
-          `;
-        }
-      }
-
-      const overage =
-        data.items_overage > 0 ? ` (+${data.items_overage} extra)` : "";
+      currentAlternatives = data.alternatives || [];
+      evt.detail.target.dataset.calculationTime = data.calculation_time;
+
+      const alternativesHtml =
+        currentAlternatives.length > 1
+          ? renderAlternatives(currentAlternatives)
+          : "";
 
       evt.detail.target.innerHTML = `
         <div class="result-container">
           <div class="result-title">✅ Optimal Pack Distribution</div>
 
           <div class="result-grid">
-            ${packsHtml}
+            ${renderPacks(data.packs_used)}
           </div>
 
           <div class="summary-grid">
-            <div class="summary-item">
-              <div class="summary-label">📦 Total Packs</div>
-              <div class="summary-value">${data.total_packs}</div>
-            </div>
-            <div class="summary-item">
-              <div class="summary-label">📊 Total Items</div>
-              <div class="summary-value">${data.total_items}${overage}</div>
-            </div>
-            <div class="summary-item">
-              <div class="summary-label">⚡ Time</div>
-              <div class="summary-value">${data.calculation_time}</div>
-            </div>
+            ${renderSummary(data, data.calculation_time)}
           </div>
+
+          ${alternativesHtml}
         </div>
       `;
     } else {